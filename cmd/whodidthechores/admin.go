@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// withHousehold returns a context selecting the given household, or the only
+// one when no ID is given.
+func withHousehold(ctx context.Context, repo *repository.Repository, householdID int) (context.Context, error) {
+	if householdID == 0 {
+		households, err := repo.ListHouseholds(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list households: %w", err)
+		}
+		if len(households) != 1 {
+			return nil, fmt.Errorf("%d households exist, please select one with -household", len(households))
+		}
+		householdID = int(households[0].ID)
+	}
+	if _, err := repo.GetHousehold(ctx, int32(householdID)); err != nil {
+		return nil, fmt.Errorf("unable to get household %d: %w", householdID, err)
+	}
+	return repository.WithHousehold(ctx, int32(householdID)), nil
+}
+
+// subcommand parses the arguments of one of the list or create commands of a
+// resource.
+func subcommand(resource string, args []string, usage string, define func(flags *flag.FlagSet)) (string, *flag.FlagSet, int, error) {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "create") {
+		fmt.Fprint(os.Stderr, usage)
+		return "", nil, 0, errors.New("list or create is expected")
+	}
+	command := args[0]
+	flags := flag.NewFlagSet(resource+" "+command, flag.ContinueOnError)
+	householdID := flags.Int("household", 0, "ID of the household, required when there are several households")
+	if command == "create" {
+		define(flags)
+	}
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		fmt.Fprintf(flags.Output(), "\nFlags of %s %s:\n", resource, command)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return "", nil, 0, err
+	}
+	return command, flags, *householdID, nil
+}
+
+// users lists the users of a household or creates one.
+func users(ctx context.Context, repo *repository.Repository, args []string) error {
+	usage := "Usage: whodidthechores users list [flags]\n       whodidthechores users create [flags] NAME\n"
+	var share *string
+	command, flags, householdID, err := subcommand("users", args, usage, func(flags *flag.FlagSet) {
+		share = flags.String("share", "1", "share of the chores expected from the user, relative to the other users")
+	})
+	if err != nil {
+		return err
+	}
+	if command == "create" && flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a single name is expected")
+	}
+	ctx, err = withHousehold(ctx, repo, householdID)
+	if err != nil {
+		return err
+	}
+
+	if command == "list" {
+		users, err := repo.ListUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to list users: %w", err)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tNAME\tSHARE")
+		for _, user := range users {
+			fmt.Fprintf(writer, "%d\t%s\t%d\n", user.ID, user.Name, user.Share)
+		}
+		return writer.Flush()
+	}
+
+	userParams := repository.UserParams{Name: flags.Arg(0), Share: *share}
+	validated, err := repo.ValidateUser(ctx, &userParams)
+	if errors.Is(err, repository.ErrValidation) {
+		messages := []string{}
+		for _, message := range []string{userParams.Errors.Name, userParams.Errors.Share} {
+			if message != "" {
+				messages = append(messages, message)
+			}
+		}
+		return errors.New(strings.Join(messages, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	user, err := repo.CreateUser(ctx, validated)
+	if err != nil {
+		return fmt.Errorf("unable to create user: %w", err)
+	}
+	fmt.Printf("User %q created with ID %d\n", user.Name, user.ID)
+	return nil
+}
+
+// chores lists the chores of a household or creates one.
+func chores(ctx context.Context, repo *repository.Repository, args []string) error {
+	usage := "Usage: whodidthechores chores list [flags]\n       whodidthechores chores create [flags] NAME\n"
+	var description, duration, difficulty, schedule, interval, weekdays, monthDay *string
+	command, flags, householdID, err := subcommand("chores", args, usage, func(flags *flag.FlagSet) {
+		description = flags.String("description", "", "description of the chore")
+		duration = flags.String("duration", "15", "default duration of the tasks in minutes")
+		difficulty = flags.String("difficulty", "1", "difficulty of the chore from 1 to 10, the points earned per minute")
+		schedule = flags.String("schedule", repository.ScheduleNone, "schedule of the chore: none, interval, weekly or monthly")
+		interval = flags.String("interval", "", "number of days between two tasks, for the interval schedule")
+		weekdays = flags.String("weekdays", "", "comma separated days of the week, 0 for Sunday to 6 for Saturday, for the weekly schedule")
+		monthDay = flags.String("month-day", "", "day of the month, for the monthly schedule")
+	})
+	if err != nil {
+		return err
+	}
+	if command == "create" && flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a single name is expected")
+	}
+	ctx, err = withHousehold(ctx, repo, householdID)
+	if err != nil {
+		return err
+	}
+
+	if command == "list" {
+		chores, err := repo.ListChores(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to list chores: %w", err)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tNAME\tDURATION\tDIFFICULTY\tSCHEDULE\tDESCRIPTION")
+		for _, chore := range chores {
+			fmt.Fprintf(writer, "%d\t%s\t%d mn\t%d\t%s\t%s\n", chore.ID, chore.Name, chore.DefaultDurationMn, chore.Difficulty, chore.ScheduleKind, chore.Description)
+		}
+		return writer.Flush()
+	}
+
+	choreParams := repository.ChoreParams{
+		Name:                 flags.Arg(0),
+		Description:          *description,
+		DefaultDurationMn:    *duration,
+		ScheduleKind:         *schedule,
+		ScheduleIntervalDays: *interval,
+		ScheduleMonthDay:     *monthDay,
+		Difficulty:           *difficulty,
+	}
+	if *weekdays != "" {
+		for _, day := range strings.Split(*weekdays, ",") {
+			choreParams.ScheduleWeekdays = append(choreParams.ScheduleWeekdays, strings.TrimSpace(day))
+		}
+	}
+	validated, err := repo.ValidateChore(ctx, &choreParams)
+	if errors.Is(err, repository.ErrValidation) {
+		messages := []string{}
+		for _, message := range []string{choreParams.Errors.Name, choreParams.Errors.Description, choreParams.Errors.DefaultDurationMn, choreParams.Errors.Schedule, choreParams.Errors.Difficulty} {
+			if message != "" {
+				messages = append(messages, message)
+			}
+		}
+		return errors.New(strings.Join(messages, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	chore, err := repo.CreateChore(ctx, validated)
+	if err != nil {
+		return fmt.Errorf("unable to create chore: %w", err)
+	}
+	fmt.Printf("Chore %q created with ID %d\n", chore.Name, chore.ID)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// backup writes an archive of every household to a file or to the standard
+// output.
+func backup(ctx context.Context, repo *repository.Repository, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := flags.String("o", "-", "file to write the archive to, - for the standard output")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: whodidthechores backup [flags]\n\n")
+		fmt.Fprintf(flags.Output(), "Writes the households with their chores, users and tasks to a JSON archive.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	archive, err := repo.CreateBackup(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create backup: %w", err)
+	}
+	var writer io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		writer = file
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(archive); err != nil {
+		return fmt.Errorf("unable to write backup: %w", err)
+	}
+	if *output != "-" {
+		fmt.Fprintf(os.Stderr, "backed up %d households\n", len(archive.Households))
+	}
+	return nil
+}
+
+// restore restores an archive written by backup.
+func restore(ctx context.Context, repo *repository.Repository, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	remap := flags.Bool("remap", false, "add the archived households as new ones with new IDs, instead of restoring into an empty database")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: whodidthechores restore [flags] FILE\n\n")
+		fmt.Fprintf(flags.Output(), "Restores a JSON archive written by the backup command, - reading it from the standard input.\n")
+		fmt.Fprintf(flags.Output(), "Without -remap, the database must not contain any household.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a single archive is expected")
+	}
+	var reader io.Reader = os.Stdin
+	if flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	var archive repository.Backup
+	if err := json.NewDecoder(reader).Decode(&archive); err != nil {
+		return fmt.Errorf("unable to read backup: %w", err)
+	}
+	result, err := repo.RestoreBackup(ctx, archive, *remap)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotEmpty) {
+			return fmt.Errorf("%w, use -remap to add the archive to the existing data", err)
+		}
+		return fmt.Errorf("unable to restore backup: %w", err)
+	}
+	fmt.Printf("restored %d households, %d chores, %d users and %d tasks\n", result.Households, result.Chores, result.Users, result.Tasks)
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/config"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// importTasks imports a CSV file of tasks in a household, printing the invalid
+// lines when there are some.
+func importTasks(ctx context.Context, repo *repository.Repository, conf config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	householdID := flags.Int("household", 0, "ID of the household to import the tasks in, required when there are several households")
+	createMissing := flags.Bool("create-missing", false, "create the chores and users that don't exist yet")
+	dryRun := flags.Bool("dry-run", false, "only check the file, without importing it")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: whodidthechores import [flags] FILE.csv\n\n")
+		fmt.Fprintf(flags.Output(), "Imports tasks from a CSV file with the columns %s.\n\n", strings.Join(repository.ImportColumns, ", "))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("a single CSV file is expected")
+	}
+
+	ctx, err := withHousehold(ctx, repo, *householdID)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	rows, err := repository.ParseTasksCSV(file)
+	if err != nil {
+		return err
+	}
+	location, _ := time.LoadLocation(conf.TimeZone) //timezone already validated in config
+	options := repository.ImportOptions{CreateMissing: *createMissing, Location: location}
+
+	err = repo.ValidateImport(ctx, rows, options)
+	if errors.Is(err, repository.ErrValidation) {
+		for _, row := range rows {
+			if len(row.Errors) > 0 {
+				fmt.Printf("line %d: %s\n", row.Line, strings.Join(row.Errors, ", "))
+			}
+		}
+		return fmt.Errorf("invalid file, nothing was imported")
+	}
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		newChores, newUsers := map[string]bool{}, map[string]bool{}
+		for _, row := range rows {
+			if row.NewChore {
+				newChores[strings.ToLower(row.Chore)] = true
+			}
+			if row.NewUser {
+				newUsers[strings.ToLower(row.User)] = true
+			}
+		}
+		fmt.Printf("%d tasks, %d chores and %d users would be imported\n", len(rows), len(newChores), len(newUsers))
+		return nil
+	}
+	result, err := repo.ImportTasks(ctx, rows, options)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d tasks, %d chores and %d users\n", result.Tasks, result.Chores, result.Users)
+	return nil
+}
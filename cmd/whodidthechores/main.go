@@ -2,50 +2,215 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mqufflc/whodidthechores/internal/api"
 	"github.com/mqufflc/whodidthechores/internal/config"
 	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/logging"
+	"github.com/mqufflc/whodidthechores/internal/metrics"
 	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/sqlite"
+	"github.com/mqufflc/whodidthechores/internal/webhooks"
 )
 
 const (
 	exitFail = 1
 )
 
+const usage = `Usage: whodidthechores [command] [arguments]
+
+Commands:
+  serve     start the web server, the default command
+  migrate   apply, revert or inspect the database migrations
+  users     list or create users
+  chores    list or create chores
+  import    import tasks from a CSV file
+  backup    write every household to a JSON archive
+  restore   restore a JSON archive
+
+Run 'whodidthechores <command> -h' for the arguments of a command.
+`
+
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(exitFail)
 	}
 }
 
-func run() error {
+func run(args []string) error {
 	ctx := context.Background()
 
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return nil
+	case "serve", "migrate", "users", "chores", "import", "backup", "restore":
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+
 	config, err := config.New()
 	if err != nil {
 		return fmt.Errorf("config error: %w", err)
 	}
+	slog.SetDefault(logging.New(os.Stderr, config.Log))
+
+	// Migrations use their own connection, the schema may not exist yet.
+	if command == "migrate" {
+		return migrate(config, args)
+	}
+
+	var store repository.Store
+	var poolStat func() *pgxpool.Stat
+	if config.Database.Driver == "sqlite" {
+		db, err := database.OpenSQLite(config.Database.Path)
+		if err != nil {
+			return fmt.Errorf("database open error: %w", err)
+		}
+		defer db.Close()
+		store = sqlite.New(db)
+	} else {
+		pool, err := database.Connect(ctx, config.Database)
+		if err != nil {
+			return fmt.Errorf("database connect error: %w", err)
+		}
+		defer pool.Close()
+		store = repository.NewPostgresStore(pool)
+		poolStat = pool.Stat
+	}
+
+	repo := repository.New(repository.NewRepositoryParams{Store: store})
+
+	switch command {
+	case "users":
+		return users(ctx, repo, args)
+	case "chores":
+		return chores(ctx, repo, args)
+	case "import":
+		return importTasks(ctx, repo, config, args)
+	case "backup":
+		return backup(ctx, repo, args)
+	case "restore":
+		return restore(ctx, repo, args)
+	}
+	return serve(ctx, repo, poolStat, config, args)
+}
 
-	pool, err := database.Connect(ctx, config.Database)
+// serve applies the migrations, unless disabled, and runs the web server, the
+// metrics server, the webhooks dispatcher and the listener of the changes
+// streamed to the pages until an interrupt or termination signal. poolStat
+// reports the PostgreSQL connection pool, nil with SQLite.
+func serve(ctx context.Context, repo *repository.Repository, poolStat func() *pgxpool.Stat, config config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: whodidthechores serve\n\n")
+		fmt.Fprintf(flags.Output(), "Starts the web server on the configured port. The migrations are applied first\nunless WDTC_DATABASE_AUTOMIGRATE is false.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if config.Database.AutoMigrate {
+		if err := applyMigrations(config.Database); err != nil {
+			return fmt.Errorf("applying migrations failed: %w", err)
+		}
+	}
+
+	schemaVersion, err := database.LatestVersion(config.Database.Driver)
 	if err != nil {
-		return fmt.Errorf("database connect error: %w", err)
+		return fmt.Errorf("unable to get schema version: %w", err)
 	}
-	defer pool.Close()
 
-	repo := repository.New(repository.NewRepositoryParams{DB: pool})
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dispatcherDone := make(chan struct{})
+	go func() {
+		webhooks.New(repo, config.Webhooks).Run(ctx)
+		close(dispatcherDone)
+	}()
+	sessionsDone := make(chan struct{})
+	go func() {
+		repo.CleanSessions(ctx)
+		close(sessionsDone)
+	}()
+	// The streams of changes end with the listener, before the servers are
+	// shut down, so that they don't hold the shutdown.
+	changesDone := make(chan struct{})
+	go func() {
+		repo.ListenChanges(ctx)
+		close(changesDone)
+	}()
 
-	handler := api.New(repo, config)
-	http := &http.Server{
+	location, _ := time.LoadLocation(config.TimeZone) //timezone already validated in config
+	var httpMetrics *metrics.HTTPMetrics
+	servers := []*http.Server{}
+	if config.MetricsPort != 0 {
+		registry := metrics.NewRegistry()
+		httpMetrics = metrics.NewHTTPMetrics(registry)
+		registry.MustRegister(metrics.NewHouseholdCollector(repo, location))
+		if poolStat != nil {
+			registry.MustRegister(metrics.NewPoolCollector(poolStat))
+		}
+		servers = append(servers, &http.Server{
+			Addr:    fmt.Sprintf(":%d", config.MetricsPort),
+			Handler: metrics.Handler(registry),
+		})
+	}
+	servers = append(servers, &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.Port),
-		Handler: handler,
+		Handler: api.New(repo, config, schemaVersion, httpMetrics),
+	})
+
+	serverErr := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			slog.Info(fmt.Sprintf("Listening on %s", server.Addr))
+			serverErr <- server.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		stop()
+		<-dispatcherDone
+		<-sessionsDone
+		<-changesDone
+		return fmt.Errorf("server error: %w", err)
+	case <-ctx.Done():
 	}
+	// A second signal stops the application without waiting.
+	stop()
 
-	fmt.Printf("Listening on :%d\n", config.Port)
-	http.ListenAndServe()
+	slog.Info(fmt.Sprintf("shutting down, waiting up to %v for requests in progress", config.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	for _, server := range servers {
+		if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+			err = shutdownErr
+		}
+	}
+	<-dispatcherDone
+	<-sessionsDone
+	<-changesDone
+	if err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	slog.Info("server stopped")
 	return nil
 }
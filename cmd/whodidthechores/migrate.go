@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/mqufflc/whodidthechores/internal/config"
+	"github.com/mqufflc/whodidthechores/internal/database"
+)
+
+// migrate manages the database schema, to apply the migrations once before
+// starting several servers.
+func migrate(conf config.Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: whodidthechores migrate <command>\n\n")
+		fmt.Fprintf(flags.Output(), "Commands:\n")
+		fmt.Fprintf(flags.Output(), "  up             apply every migration not applied yet\n")
+		fmt.Fprintf(flags.Output(), "  down [N]       revert the last N migrations, 1 by default\n")
+		fmt.Fprintf(flags.Output(), "  version        print the version of the schema\n")
+		fmt.Fprintf(flags.Output(), "  force VERSION  set the version of the schema without running any migration,\n")
+		fmt.Fprintf(flags.Output(), "                 once a failed migration was fixed by hand\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("a migrate command is expected")
+	}
+
+	command := flags.Arg(0)
+	expectedArgs := map[string][2]int{"up": {0, 0}, "down": {0, 1}, "version": {0, 0}, "force": {1, 1}}
+	expected, ok := expectedArgs[command]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+	if flags.NArg()-1 < expected[0] || flags.NArg()-1 > expected[1] {
+		flags.Usage()
+		return fmt.Errorf("wrong number of arguments for migrate %s", command)
+	}
+	number := 1
+	if flags.NArg() == 2 {
+		var err error
+		if number, err = strconv.Atoi(flags.Arg(1)); err != nil {
+			return fmt.Errorf("invalid number %q", flags.Arg(1))
+		}
+	}
+
+	migrator, err := database.OpenMigrator(conf.Database)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch command {
+	case "up":
+		return migrator.Up()
+	case "down":
+		return migrator.Down(number)
+	case "force":
+		return migrator.Force(number)
+	}
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return fmt.Errorf("unable to get schema version: %w", err)
+	}
+	if dirty {
+		fmt.Printf("%d (dirty)\n", version)
+	} else {
+		fmt.Println(version)
+	}
+	return nil
+}
+
+// applyMigrations applies every migration not applied yet when the server
+// starts.
+func applyMigrations(conf config.DbConfig) error {
+	slog.Info("applying migrations")
+	migrator, err := database.OpenMigrator(conf)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	return migrator.Up()
+}
@@ -80,3 +80,35 @@ Labels to use in selectors for pg
 app.kubernetes.io/name: {{ include "whodidthechores.name" . }}-pg
 app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end -}}
+
+{{/*
+Database connection environment variables
+*/}}
+{{- define "whodidthechores.databaseEnv" -}}
+{{- $secretName := .Values.secretName | default (printf "%s-pg-conn" (include "whodidthechores.fullname" .)) -}}
+- name: WDTC_DATABASE_USERNAME
+  valueFrom:
+    secretKeyRef:
+      name: {{ $secretName | quote }}
+      key: "username"
+- name: WDTC_DATABASE_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      name: {{ $secretName | quote }}
+      key: "password"
+- name: WDTC_DATABASE_HOSTNAME
+  valueFrom:
+    secretKeyRef:
+      name: {{ $secretName | quote }}
+      key: "host"
+- name: WDTC_DATABASE_PORT
+  valueFrom:
+    secretKeyRef:
+      name: {{ $secretName | quote }}
+      key: "port"
+- name: WDTC_DATABASE_DATABASE
+  valueFrom:
+    secretKeyRef:
+      name: {{ $secretName | quote }}
+      key: "database"
+{{- end -}}
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/mqufflc/whodidthechores/internal/config"
 	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/metrics"
 	"github.com/mqufflc/whodidthechores/internal/repository"
 	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
 )
@@ -19,30 +22,78 @@ import (
 type HTTPServer struct {
 	repository *repository.Repository
 	timezone   *time.Location
+	session    config.SessionConfig
+	// schemaVersion is the version of the database schema the application
+	// expects, checked by the readiness probe.
+	schemaVersion uint
+
+	allowRegistration bool
 }
 
-func New(repo *repository.Repository, conf config.Config) http.Handler {
+// New returns the handler of the application. The requests of each route are
+// recorded in httpMetrics when it isn't nil.
+func New(repo *repository.Repository, conf config.Config, schemaVersion uint, httpMetrics *metrics.HTTPMetrics) http.Handler {
 	location, _ := time.LoadLocation(conf.TimeZone) //timezone already validated in config
 	s := &HTTPServer{
-		repository: repo,
-		timezone:   location,
+		repository:    repo,
+		timezone:      location,
+		session:       conf.Session,
+		schemaVersion: schemaVersion,
+
+		allowRegistration: conf.AllowRegistration,
 	}
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", s.notFound)
-	mux.HandleFunc("/static/{fileName}", serveStatic)
-	mux.HandleFunc("/{$}", s.index)
-	mux.HandleFunc("/chores", s.chores)
-	mux.HandleFunc("/chores/{id}", s.viewChore)
-	mux.HandleFunc("/chores/{id}/edit", s.editChore)
-	mux.HandleFunc("/chores/new", s.createChore)
-	mux.HandleFunc("/users", s.users)
-	mux.HandleFunc("/users/{id}", s.viewUser)
-	mux.HandleFunc("/users/{id}/edit", s.editUser)
-	mux.HandleFunc("/users/new", s.createUser)
-	mux.HandleFunc("/tasks", s.tasks)
-	mux.HandleFunc("/tasks/{id}", s.editTask)
-	mux.HandleFunc("/tasks/new", s.createTask)
-	return mux
+	handle := func(pattern string, handler http.HandlerFunc) {
+		mux.Handle(pattern, httpMetrics.Instrument(pattern, handler))
+	}
+	handle("/", s.notFound)
+	handle("/static/{fileName}", serveStatic)
+	handle("/{$}", s.index)
+	handle("/dashboard", s.viewDashboard)
+	handle("/events", s.events)
+	handle("/chores", s.chores)
+	handle("/chores/{id}", s.viewChore)
+	handle("/chores/{id}/edit", s.editChore)
+	handle("/chores/{id}/done", s.doneChore)
+	handle("/chores/{id}/timer", s.startTimer)
+	handle("/chores/{id}/archive", s.archiveChore)
+	handle("/chores/{id}/restore", s.restoreChore)
+	handle("/chores/{id}/delete", s.deleteChore)
+	handle("/chores/new", s.createChore)
+	handle("/users", s.users)
+	handle("/users/{id}", s.viewUser)
+	handle("/users/{id}/edit", s.editUser)
+	handle("/users/{id}/archive", s.archiveUser)
+	handle("/users/{id}/restore", s.restoreUser)
+	handle("/users/{id}/delete", s.deleteUser)
+	handle("/users/new", s.createUser)
+	handle("/tasks", s.tasks)
+	handle("/tasks/{id}", s.editTask)
+	handle("/tasks/new", s.createTask)
+	handle("/tasks/import", s.importTasks)
+	handle("/timers", s.timers)
+	handle("/timers/{id}/{action}", s.timerAction)
+	handle("/export/tasks", s.exportTasks)
+	handle("/export/report", s.exportReport)
+	handle("/accounts", s.accounts)
+	handle("/accounts/{id}/edit", s.editAccount)
+	handle("/accounts/new", s.createAccount)
+	handle("/webhooks", s.webhooks)
+	handle("/webhooks/{id}", s.viewWebhook)
+	handle("/webhooks/{id}/edit", s.editWebhook)
+	handle("/webhooks/new", s.createWebhook)
+	handle("/webhooks/{id}/deliveries/{delivery}/retry", s.retryWebhookDelivery)
+	handle("/calendars", s.calendars)
+	handle("/calendars/{id}", s.deleteCalendar)
+	handle("/ical/{file}", s.calendarFeed)
+	handle("/healthz", s.healthz)
+	handle("/readyz", s.readyz)
+	handle("/login", s.login)
+	handle("/logout", s.logout)
+	handle("/setup", s.setup)
+	// The routes of the JSON API are recorded by its own mux.
+	mux.Handle("/api/v1/", s.apiV1(httpMetrics))
+	return requestID(accessLog(recoverPanic(s.authenticate(mux))))
 }
 
 func serveStatic(w http.ResponseWriter, r *http.Request) {
@@ -53,7 +104,7 @@ func serveStatic(w http.ResponseWriter, r *http.Request) {
 	}
 	p, err := html.EmbedStatic.ReadFile(fmt.Sprintf("static/%s", fileName))
 	if err != nil {
-		slog.Error(fmt.Sprintf("unable to read static file: %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to read static file: %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -67,10 +118,14 @@ func serveStatic(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *HTTPServer) notFound(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotFound)
 	html.NotFound().Render(r.Context(), w)
 }
 
-func (h *HTTPServer) index(w http.ResponseWriter, r *http.Request) {
+// reportRange returns the range given by the 'from' and 'to' query
+// parameters, defaulting to the last 90 days. A parameter that can't be parsed
+// is replaced by its default and reported in the error.
+func (h *HTTPServer) reportRange(r *http.Request) (time.Time, time.Time, error) {
 	queries := r.URL.Query()
 	fromQuery := queries.Get("from")
 	toQuery := queries.Get("to")
@@ -80,11 +135,11 @@ func (h *HTTPServer) index(w http.ResponseWriter, r *http.Request) {
 	defaultLast := time.Date(currentYear, currentMonth, currentDay, 23, 59, 59, 0, h.timezone)
 	defaultFrom := defaultLast.AddDate(0, 0, -90).Add(-23*time.Hour - 59*time.Minute - 59*time.Second)
 	var from, to time.Time
-	var err error
+	var err, rangeErr error
 	if fromQuery != "" {
 		from, err = time.ParseInLocation("2006-01-02T15:04", fromQuery, h.timezone)
 		if err != nil {
-			slog.Warn(fmt.Sprintf("Unable to parse 'from': %s", fromQuery))
+			rangeErr = fmt.Errorf("unable to parse 'from': %s", fromQuery)
 			from = defaultFrom
 		}
 	} else {
@@ -93,19 +148,151 @@ func (h *HTTPServer) index(w http.ResponseWriter, r *http.Request) {
 	if toQuery != "" {
 		to, err = time.ParseInLocation("2006-01-02T15:04", toQuery, h.timezone)
 		if err != nil {
-			slog.Warn(fmt.Sprintf("Unable to parse 'to': %s", toQuery))
+			rangeErr = errors.Join(rangeErr, fmt.Errorf("unable to parse 'to': %s", toQuery))
 			to = defaultLast
 		}
 	} else {
 		to = defaultLast
 	}
-	report, err := h.repository.GetChoreReport(r.Context(), from, to)
+	return from, to, rangeErr
+}
+
+func (h *HTTPServer) index(w http.ResponseWriter, r *http.Request) {
+	from, to, err := h.reportRange(r)
+	if err != nil {
+		slog.WarnContext(r.Context(), err.Error())
+	}
+	metric, err := repository.ParseReportMetric(r.URL.Query().Get("metric"))
 	if err != nil {
+		slog.WarnContext(r.Context(), fmt.Sprintf("Unable to parse 'metric': %v", err))
+		metric = repository.MetricMinutes
+	}
+	bucket, err := repository.ParseTimelineBucket(r.URL.Query().Get("bucket"))
+	if err != nil {
+		slog.WarnContext(r.Context(), fmt.Sprintf("Unable to parse 'bucket': %v", err))
+		bucket = repository.BucketWeek
+	}
+	report, err := h.repository.GetChoreReport(r.Context(), from, to, metric)
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to generate report: %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	chart := html.CreateBarChart(report)
-	html.Index(chart, h.timezone, from, to).Render(r.Context(), w)
+	timeline, err := h.repository.GetTimeline(r.Context(), from, to, bucket, metric, h.timezone)
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to generate timeline: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	fairness, err := h.repository.GetFairnessReport(r.Context(), from, to, metric)
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to generate fairness report: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	userID := ""
+	if account, ok := accountFromContext(r.Context()); ok && account.UserID.Valid {
+		userID = strconv.FormatInt(int64(account.UserID.Int32), 10)
+	}
+	dashboard, err := h.dashboard(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to build dashboard: %v", err))
+		return
+	}
+	html.Index(dashboard, chart, html.CreateLineChart(timeline), fairness, h.timezone, from, to, metric, timeline.Bucket).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) dashboard(ctx context.Context, userID string) (html.Dashboard, error) {
+	urgencies, err := h.repository.ListChoresUrgency(ctx, time.Now(), h.timezone)
+	if err != nil {
+		return html.Dashboard{}, err
+	}
+	users, err := h.repository.ListUsers(ctx)
+	if err != nil {
+		return html.Dashboard{}, err
+	}
+	timers, err := h.repository.ListTimers(ctx)
+	if err != nil {
+		return html.Dashboard{}, err
+	}
+	return html.Dashboard{Urgencies: urgencies, Users: users, UserID: userID, Timers: timers, Now: time.Now()}, nil
+}
+
+// viewDashboard renders the dashboard alone, for the home page to refresh it
+// when the household changes.
+func (h *HTTPServer) viewDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	h.renderDashboard(w, r, r.URL.Query().Get("user-id"), "")
+}
+
+// doneChore logs a task of the chore started now, lasting its default
+// duration, and renders the updated dashboard.
+func (h *HTTPServer) doneChore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	choreID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	chore, err := h.repository.GetChore(r.Context(), int32(choreID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+		return
+	}
+	now := time.Now().In(h.timezone)
+	taskParams := repository.TaskParams{
+		ChoreID:    strconv.FormatInt(int64(chore.ID), 10),
+		UserID:     r.FormValue("user-id"),
+		StartedAt:  now.Format("2006-01-02T15:04"),
+		DurationMn: strconv.FormatInt(int64(chore.DefaultDurationMn), 10),
+	}
+	dashboardError := ""
+	taskParamsValidated, err := h.repository.ValidateTask(r.Context(), &taskParams, *h.timezone)
+	if err != nil {
+		if !errors.Is(err, repository.ErrValidation) {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate task: %v", err))
+			return
+		}
+		dashboardError = taskParams.Errors.First()
+		if dashboardError == "" {
+			dashboardError = "Unable to log this chore, please try again"
+		}
+	} else {
+		taskParamsValidated.StartedAt = now
+		if _, err = h.repository.CreateTask(r.Context(), taskParamsValidated); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create task: %v", err))
+			return
+		}
+	}
+	h.renderDashboard(w, r, taskParams.UserID, dashboardError)
+}
+
+// renderDashboard renders the dashboard of the user after an action on it,
+// with the error the action ended with.
+func (h *HTTPServer) renderDashboard(w http.ResponseWriter, r *http.Request, userID string, dashboardError string) {
+	dashboard, err := h.dashboard(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to build dashboard: %v", err))
+		return
+	}
+	dashboard.Error = dashboardError
+	html.DashboardList(dashboard, h.timezone).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) chores(w http.ResponseWriter, r *http.Request) {
@@ -128,37 +315,58 @@ func (h *HTTPServer) viewChore(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	choreParams := repository.ChoreParams{
-		ID:                chore.ID,
-		Name:              chore.Name,
-		Description:       chore.Description,
-		DefaultDurationMn: strconv.FormatInt(int64(chore.DefaultDurationMn), 10),
-	}
+	choreParams := repository.NewChoreParams(chore)
 	tasks, err := h.repository.GetChoreTasks(r.Context(), chore.ID)
 	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list chore tasks: %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	dues, err := h.repository.ListChoresDue(r.Context(), []postgres.Chore{chore}, time.Now(), h.timezone)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to compute chore due date: %v", err))
+		return
+	}
+	timeline, err := repository.ChoreTimeline(tasks, time.Now(), h.timezone)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to generate chore timeline: %v", err))
+		return
 	}
-	html.ChoreView(choreParams, tasks, h.timezone).Render(r.Context(), w)
+	html.ChoreView(choreParams, dues[chore.ID], tasks, html.CreatePeriodBarChart(timeline), h.timezone).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) viewChores(w http.ResponseWriter, r *http.Request) {
 	chores, err := h.repository.ListChores(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		slog.Error(fmt.Sprintf("unable to list chores: %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list chores: %v", err))
+		return
+	}
+	dues, err := h.repository.ListChoresDue(r.Context(), chores, time.Now(), h.timezone)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to compute chores due dates: %v", err))
+		return
+	}
+	archived, err := h.repository.ListArchivedChores(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list archived chores: %v", err))
 		return
 	}
-	html.Chores(chores).Render(r.Context(), w)
+	html.Chores(chores, archived, dues, h.timezone).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) createChore(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "POST" {
 		if err := r.ParseForm(); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			slog.Warn(fmt.Sprintf("unable to parse form: %v", err))
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
 			return
 		}
-		choreParams := repository.ChoreParams{ID: -1, Name: strings.TrimSpace(r.FormValue("name")), Description: strings.TrimSpace(r.FormValue("description")), DefaultDurationMn: r.FormValue("default_duration")}
+		choreParams := choreParamsFromForm(r, -1)
 		choreParamsValidated, err := h.repository.ValidateChore(r.Context(), &choreParams)
 		if err != nil {
 			if errors.Is(err, repository.ErrValidation) {
@@ -167,12 +375,12 @@ func (h *HTTPServer) createChore(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to validate chore: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate chore: %v", err))
 			return
 		}
 		if _, err := h.repository.CreateChore(r.Context(), choreParamsValidated); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to create chore: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create chore: %v", err))
 			return
 		}
 		http.Redirect(w, r, "/chores", http.StatusSeeOther)
@@ -182,7 +390,21 @@ func (h *HTTPServer) createChore(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	html.ChoreCreate(repository.ChoreParams{}).Render(r.Context(), w)
+	html.ChoreCreate(repository.ChoreParams{Difficulty: strconv.Itoa(repository.MinDifficulty)}).Render(r.Context(), w)
+}
+
+func choreParamsFromForm(r *http.Request, id int32) repository.ChoreParams {
+	return repository.ChoreParams{
+		ID:                   id,
+		Name:                 strings.TrimSpace(r.FormValue("name")),
+		Description:          strings.TrimSpace(r.FormValue("description")),
+		DefaultDurationMn:    r.FormValue("default_duration"),
+		ScheduleKind:         r.FormValue("schedule-kind"),
+		ScheduleIntervalDays: r.FormValue("schedule-interval"),
+		ScheduleWeekdays:     r.Form["schedule-weekday"],
+		ScheduleMonthDay:     r.FormValue("schedule-month-day"),
+		Difficulty:           r.FormValue("difficulty"),
+	}
 }
 
 func (h *HTTPServer) editChore(w http.ResponseWriter, r *http.Request) {
@@ -198,7 +420,12 @@ func (h *HTTPServer) editChore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if r.Method == "PUT" {
-		choreParams := repository.ChoreParams{ID: chore.ID, Name: strings.TrimSpace(r.FormValue("name")), Description: strings.TrimSpace(r.FormValue("description")), DefaultDurationMn: r.FormValue("default_duration")}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		choreParams := choreParamsFromForm(r, chore.ID)
 		choreParamsValidated, err := h.repository.ValidateChore(r.Context(), &choreParams)
 		if err != nil {
 			if errors.Is(err, repository.ErrValidation) {
@@ -207,13 +434,13 @@ func (h *HTTPServer) editChore(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to validate chore: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate chore: %v", err))
 			return
 		}
 		chore, err = h.repository.UpdateChore(r.Context(), chore.ID, choreParamsValidated)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to edit chore: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to edit chore: %v", err))
 			return
 		}
 		w.Header().Add("HX-Location", fmt.Sprintf("/chores/%d", chore.ID))
@@ -222,22 +449,21 @@ func (h *HTTPServer) editChore(w http.ResponseWriter, r *http.Request) {
 	}
 	if r.Method == "DELETE" {
 		err = h.repository.DeleteChore(r.Context(), chore.ID)
+		if errors.Is(err, repository.ErrStillInUse) {
+			w.Header().Add("HX-Location", fmt.Sprintf("/chores/%d/delete", chore.ID))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to delete chore: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to delete chore: %v", err))
 			return
 		}
 		w.Header().Add("HX-Location", "/chores")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	choreParams := repository.ChoreParams{
-		ID:                chore.ID,
-		Name:              chore.Name,
-		Description:       chore.Description,
-		DefaultDurationMn: strconv.FormatInt(int64(chore.DefaultDurationMn), 10),
-	}
-	html.ChoreEdit(choreParams).Render(r.Context(), w)
+	html.ChoreEdit(repository.NewChoreParams(chore)).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) users(w http.ResponseWriter, r *http.Request) {
@@ -260,51 +486,64 @@ func (h *HTTPServer) viewUser(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	userParams := repository.UserParams{
-		ID:   user.ID,
-		Name: user.Name,
-	}
+	userParams := repository.NewUserParams(user)
 	tasks, err := h.repository.GetUserTasks(r.Context(), user.ID)
 	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list user tasks: %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
-	html.UserView(userParams, tasks, h.timezone).Render(r.Context(), w)
+	userTasks := make([]postgres.Task, len(tasks))
+	for index, row := range tasks {
+		userTasks[index] = row.Task
+	}
+	chores := html.CreatePieChart(repository.UserChoreReport(repository.User(user), tasks))
+	activity := html.CreateActivityCalendar(repository.DailyActivity(userTasks, h.timezone), time.Now().In(h.timezone))
+	html.UserView(userParams, tasks, chores, activity, h.timezone).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) viewUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := h.repository.ListUsers(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		slog.Error(fmt.Sprintf("unable to get users: %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to get users: %v", err))
 		return
 	}
-	html.Users(users).Render(r.Context(), w)
+	archived, err := h.repository.ListArchivedUsers(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to get archived users: %v", err))
+		return
+	}
+	html.Users(users, archived).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) createUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "POST" {
 		if err := r.ParseForm(); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("user create parsing: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("user create parsing: %v", err))
 			return
 		}
 		userParams := repository.UserParams{
-			ID:   -1,
-			Name: r.FormValue("name"),
+			ID:    -1,
+			Name:  r.FormValue("name"),
+			Share: r.FormValue("share"),
 		}
-		validatedName, err := h.repository.ValidateUser(r.Context(), &userParams)
+		userParamsValidated, err := h.repository.ValidateUser(r.Context(), &userParams)
 		if err != nil {
 			if errors.Is(err, repository.ErrValidation) {
 				w.WriteHeader(http.StatusOK)
 				html.UserCreate(userParams).Render(r.Context(), w)
 				return
 			}
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate user: %v", err))
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		if _, err := h.repository.CreateUser(r.Context(), validatedName); err != nil {
+		if _, err := h.repository.CreateUser(r.Context(), userParamsValidated); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("user create error: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("user create error: %v", err))
 			return
 		}
 		http.Redirect(w, r, "/users", http.StatusSeeOther)
@@ -314,7 +553,7 @@ func (h *HTTPServer) createUser(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	html.UserCreate(repository.UserParams{}).Render(r.Context(), w)
+	html.UserCreate(repository.UserParams{Share: strconv.Itoa(repository.MinShare)}).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) editUser(w http.ResponseWriter, r *http.Request) {
@@ -331,10 +570,11 @@ func (h *HTTPServer) editUser(w http.ResponseWriter, r *http.Request) {
 	}
 	if r.Method == "PUT" {
 		userParams := repository.UserParams{
-			ID:   user.ID,
-			Name: strings.TrimSpace(r.FormValue("name")),
+			ID:    user.ID,
+			Name:  strings.TrimSpace(r.FormValue("name")),
+			Share: r.FormValue("share"),
 		}
-		validatedName, err := h.repository.ValidateUser(r.Context(), &userParams)
+		userParamsValidated, err := h.repository.ValidateUser(r.Context(), &userParams)
 		if err != nil {
 			if errors.Is(err, repository.ErrValidation) {
 				w.WriteHeader(http.StatusOK)
@@ -342,13 +582,13 @@ func (h *HTTPServer) editUser(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to validate user: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate user: %v", err))
 			return
 		}
-		user, err = h.repository.UpdateUser(r.Context(), user.ID, validatedName)
+		user, err = h.repository.UpdateUser(r.Context(), user.ID, userParamsValidated)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to edit user: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to edit user: %v", err))
 			return
 		}
 		w.Header().Add("HX-Location", fmt.Sprintf("/users/%d", user.ID))
@@ -357,51 +597,128 @@ func (h *HTTPServer) editUser(w http.ResponseWriter, r *http.Request) {
 	}
 	if r.Method == "DELETE" {
 		err = h.repository.DeleteUser(r.Context(), user.ID)
+		if errors.Is(err, repository.ErrStillInUse) {
+			w.Header().Add("HX-Location", fmt.Sprintf("/users/%d/delete", user.ID))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("user delete error: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("user delete error: %v", err))
 			return
 		}
 		w.Header().Add("HX-Location", "/users")
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	userParams := repository.UserParams{
-		ID:   user.ID,
-		Name: user.Name,
-	}
-	html.UserEdit(userParams).Render(r.Context(), w)
+	html.UserEdit(repository.NewUserParams(user)).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) tasks(w http.ResponseWriter, r *http.Request) {
 	h.viewTasks(w, r)
 }
 
+// queryID returns the ID given by a query parameter, 0 when missing or
+// invalid.
+func queryID(r *http.Request, name string) int32 {
+	query := r.URL.Query().Get(name)
+	if query == "" {
+		return 0
+	}
+	id, err := strconv.ParseInt(query, 10, 32)
+	if err != nil {
+		slog.WarnContext(r.Context(), fmt.Sprintf("Unable to parse '%s': %s", name, query))
+		return 0
+	}
+	return int32(id)
+}
+
+// taskFilter returns the filter given by the query parameters: 'user' and
+// 'chore' IDs, 'from' and 'to' days, both included, 'q' searched in the
+// descriptions, 'sort' and 'order'. Invalid parameters are ignored.
+func (h *HTTPServer) taskFilter(r *http.Request) repository.TaskFilter {
+	queries := r.URL.Query()
+	filter := repository.TaskFilter{
+		UserID:    queryID(r, "user"),
+		ChoreID:   queryID(r, "chore"),
+		Search:    strings.TrimSpace(queries.Get("q")),
+		Sort:      queries.Get("sort"),
+		Ascending: queries.Get("order") == "asc",
+	}
+	if query := queries.Get("from"); query != "" {
+		from, err := time.ParseInLocation(time.DateOnly, query, h.timezone)
+		if err != nil {
+			slog.WarnContext(r.Context(), fmt.Sprintf("Unable to parse 'from': %s", query))
+		} else {
+			filter.From = from
+		}
+	}
+	if query := queries.Get("to"); query != "" {
+		to, err := time.ParseInLocation(time.DateOnly, query, h.timezone)
+		if err != nil {
+			slog.WarnContext(r.Context(), fmt.Sprintf("Unable to parse 'to': %s", query))
+		} else {
+			filter.To = to.AddDate(0, 0, 1)
+		}
+	}
+	if !slices.Contains(repository.TaskSorts, filter.Sort) {
+		filter.Sort = repository.TaskSortStartedAt
+	}
+	return filter
+}
+
+// viewTasks shows the first page of the tasks matching the filter. The next
+// pages, requested with the 'cursor' query parameter as the table is
+// scrolled, only render their rows.
 func (h *HTTPServer) viewTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.repository.ListUsersTasks(r.Context())
+	filter := h.taskFilter(r)
+	cursor := r.URL.Query().Get("cursor")
+	page, err := h.repository.SearchTasks(r.Context(), filter, cursor, repository.TasksPageSize)
 	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list tasks: %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
-	html.Tasks(tasks, h.timezone).Render(r.Context(), w)
+	if cursor != "" {
+		html.TaskRows(page, filter, h.timezone).Render(r.Context(), w)
+		return
+	}
+	chores, err := h.repository.ListAllChores(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list chores: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	users, err := h.repository.ListAllUsers(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list users: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	html.Tasks(page, filter, chores, users, h.timezone).Render(r.Context(), w)
 }
 
 func (h *HTTPServer) createTask(w http.ResponseWriter, r *http.Request) {
 	chores, err := h.repository.ListChores(r.Context())
 	if err != nil {
-		slog.Error(fmt.Sprintf("Unable to list chores %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list chores %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	users, err := h.repository.ListUsers(r.Context())
 	if err != nil {
-		slog.Error(fmt.Sprintf("Unable to list users %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list users %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	if r.Method == "POST" {
 		if err := r.ParseForm(); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			slog.Warn(fmt.Sprintf("unable to parse form: %v", err))
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
 			return
 		}
 		taskParams := repository.TaskParams{
@@ -419,21 +736,31 @@ func (h *HTTPServer) createTask(w http.ResponseWriter, r *http.Request) {
 				html.TaskCreate(taskParams, chores, users).Render(r.Context(), w)
 				return
 			}
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate task: %v", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if _, err := h.repository.CreateTask(r.Context(), taskParamsValidated); err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create task: %v", err))
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		h.repository.CreateTask(r.Context(), taskParamsValidated)
 		http.Redirect(w, r, "/tasks", http.StatusSeeOther)
 		return
 	}
 	taskParams := repository.TaskParams{
 		ID:          uuid.UUID{},
-		UserID:      strconv.FormatInt(int64(users[0].ID), 10),
-		ChoreID:     strconv.FormatInt(int64(chores[0].ID), 10),
 		StartedAt:   time.Now().In(h.timezone).Format("2006-01-02T15:04"),
 		DurationMn:  "",
 		Description: "",
 	}
+	// The first chore and user are selected, when there are some.
+	if len(users) > 0 {
+		taskParams.UserID = strconv.FormatInt(int64(users[0].ID), 10)
+	}
+	if len(chores) > 0 {
+		taskParams.ChoreID = strconv.FormatInt(int64(chores[0].ID), 10)
+	}
 	html.TaskCreate(taskParams, chores, users).Render(r.Context(), w)
 }
 
@@ -446,11 +773,13 @@ func (h *HTTPServer) editTask(w http.ResponseWriter, r *http.Request) {
 	task, err := h.repository.GetTask(r.Context(), taskID)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
+		html.NotFound().Render(r.Context(), w)
 		return
 	}
 	if r.Method == "DELETE" {
 		err = h.repository.DeleteTask(r.Context(), task.ID)
 		if err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to delete task: %v", err))
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -458,22 +787,22 @@ func (h *HTTPServer) editTask(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	chores, err := h.repository.ListChores(r.Context())
+	chores, err := h.repository.ListAllChores(r.Context())
 	if err != nil {
-		slog.Error(fmt.Sprintf("Unable to list chores %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list chores %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	users, err := h.repository.ListUsers(r.Context())
+	users, err := h.repository.ListAllUsers(r.Context())
 	if err != nil {
-		slog.Error(fmt.Sprintf("Unable to list users %v", err))
+		slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list users %v", err))
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	if r.Method == "PUT" {
 		if err := r.ParseForm(); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			slog.Warn(fmt.Sprintf("unable to parse form: %v", err))
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
 			return
 		}
 		taskParams := repository.TaskParams{
@@ -491,6 +820,7 @@ func (h *HTTPServer) editTask(w http.ResponseWriter, r *http.Request) {
 				html.TaskEdit(taskParams, chores, users).Render(r.Context(), w)
 				return
 			}
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate task: %v", err))
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -503,9 +833,12 @@ func (h *HTTPServer) editTask(w http.ResponseWriter, r *http.Request) {
 		})
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			slog.Error(fmt.Sprintf("unable to edit task: %v", err))
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to edit task: %v", err))
 			return
 		}
+		w.Header().Add("HX-Location", "/tasks")
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
 	taskParams := repository.TaskParams{
 		ID:          task.ID,
@@ -517,3 +850,137 @@ func (h *HTTPServer) editTask(w http.ResponseWriter, r *http.Request) {
 	}
 	html.TaskEdit(taskParams, chores, users).Render(r.Context(), w)
 }
+
+func (h *HTTPServer) accounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.repository.ListAccounts(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list accounts: %v", err))
+		return
+	}
+	users, err := h.repository.ListAllUsers(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list users: %v", err))
+		return
+	}
+	html.Accounts(accounts, users).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) createAccount(w http.ResponseWriter, r *http.Request) {
+	users, err := h.repository.ListUsers(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list users %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		accountParams := repository.AccountParams{
+			ID:              -1,
+			Username:        strings.TrimSpace(r.FormValue("username")),
+			Password:        r.FormValue("password"),
+			PasswordConfirm: r.FormValue("password-confirm"),
+			UserID:          r.FormValue("user-id"),
+		}
+		validatedAccount, err := h.repository.ValidateAccount(r.Context(), &accountParams)
+		if err != nil {
+			if errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusOK)
+				html.AccountCreate(accountParams, users).Render(r.Context(), w)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate account: %v", err))
+			return
+		}
+		if _, err := h.repository.CreateAccount(r.Context(), validatedAccount); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create account: %v", err))
+			return
+		}
+		http.Redirect(w, r, "/accounts", http.StatusSeeOther)
+		return
+	}
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	html.AccountCreate(repository.AccountParams{}, users).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) editAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	account, err := h.repository.GetAccount(r.Context(), int32(accountID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		html.NotFound().Render(r.Context(), w)
+		return
+	}
+	users, err := h.repository.ListAllUsers(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list users %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if r.Method == "PUT" {
+		accountParams := repository.AccountParams{
+			ID:              account.ID,
+			Username:        strings.TrimSpace(r.FormValue("username")),
+			Password:        r.FormValue("password"),
+			PasswordConfirm: r.FormValue("password-confirm"),
+			UserID:          r.FormValue("user-id"),
+		}
+		validatedAccount, err := h.repository.ValidateAccount(r.Context(), &accountParams)
+		if err != nil {
+			if errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusOK)
+				html.AccountEdit(accountParams, users).Render(r.Context(), w)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate account: %v", err))
+			return
+		}
+		if _, err = h.repository.UpdateAccount(r.Context(), account.ID, validatedAccount); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to edit account: %v", err))
+			return
+		}
+		w.Header().Add("HX-Location", "/accounts")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method == "DELETE" {
+		if current, ok := accountFromContext(r.Context()); ok && current.ID == account.ID {
+			w.WriteHeader(http.StatusConflict)
+			slog.WarnContext(r.Context(), "refusing to delete the account of the current session")
+			return
+		}
+		err = h.repository.DeleteAccount(r.Context(), account.ID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("account delete error: %v", err))
+			return
+		}
+		w.Header().Add("HX-Location", "/accounts")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	accountParams := repository.AccountParams{
+		ID:       account.ID,
+		Username: account.Username,
+	}
+	if account.UserID.Valid {
+		accountParams.UserID = strconv.FormatInt(int64(account.UserID.Int32), 10)
+	}
+	html.AccountEdit(accountParams, users).Render(r.Context(), w)
+}
@@ -0,0 +1,1118 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/config"
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/memory"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer serves the application from an in-memory store, with a
+// household whose "admin" account is logged in.
+type testServer struct {
+	t       *testing.T
+	handler http.Handler
+	repo    *repository.Repository
+	// ctx is bound to the household of the admin account.
+	ctx     context.Context
+	account postgres.Account
+	session *http.Cookie
+}
+
+func newTestServer(t *testing.T, conf config.Config) *testServer {
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	conf.TimeZone = "UTC"
+	conf.Session.Duration = time.Hour
+	repo := repository.New(repository.NewRepositoryParams{Store: memory.New()})
+	schemaVersion, err := database.LatestVersion("postgres")
+	require.NoError(t, err)
+	return &testServer{
+		t:       t,
+		handler: New(repo, conf, schemaVersion, nil),
+		repo:    repo,
+		ctx:     context.Background(),
+	}
+}
+
+// login creates the household of the server and logs its admin account in.
+func (s *testServer) login() *testServer {
+	household, account, err := s.repo.CreateHousehold(context.Background(), "Home", repository.ValidatedAccount{Username: "admin", Password: "password"})
+	require.NoError(s.t, err)
+	s.ctx = repository.WithHousehold(context.Background(), household.ID)
+	s.account = account
+	token, _, err := s.repo.CreateSession(s.ctx, account.ID, time.Hour)
+	require.NoError(s.t, err)
+	s.session = &http.Cookie{Name: sessionCookieName, Value: token}
+	return s
+}
+
+// request serves a request with the session of the admin account, if logged
+// in, sending form as an url-encoded body when it isn't nil.
+func (s *testServer) request(method string, target string, form url.Values, headers ...string) *httptest.ResponseRecorder {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	request := httptest.NewRequest(method, target, body)
+	if form != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	if s.session != nil {
+		request.AddCookie(s.session)
+	}
+	response := httptest.NewRecorder()
+	s.handler.ServeHTTP(response, request)
+	return response
+}
+
+// requestJSON serves a request of the JSON API and decodes its response in v
+// when it isn't nil.
+func (s *testServer) requestJSON(method string, target string, body string, v any) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.AddCookie(s.session)
+	response := httptest.NewRecorder()
+	s.handler.ServeHTTP(response, request)
+	if v != nil {
+		require.NoError(s.t, json.Unmarshal(response.Body.Bytes(), v), response.Body.String())
+	}
+	return response
+}
+
+func (s *testServer) createChore(name string) postgres.Chore {
+	chore, err := s.repo.CreateChore(s.ctx, postgres.CreateChoreParams{Name: name, DefaultDurationMn: 15, ScheduleKind: "none"})
+	require.NoError(s.t, err)
+	return chore
+}
+
+func (s *testServer) createUser(name string) postgres.User {
+	user, err := s.repo.CreateUser(s.ctx, postgres.CreateUserParams{Name: name})
+	require.NoError(s.t, err)
+	return user
+}
+
+func (s *testServer) createTask(user postgres.User, chore postgres.Chore) postgres.Task {
+	task, err := s.repo.CreateTask(s.ctx, postgres.CreateTaskParams{UserID: user.ID, ChoreID: chore.ID, StartedAt: time.Now().Add(-time.Hour), DurationMn: 20})
+	require.NoError(s.t, err)
+	return task
+}
+
+func TestPublicRoutes(t *testing.T) {
+	s := newTestServer(t, config.Config{})
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		response := s.request("GET", path, nil)
+		assert.Equal(t, http.StatusOK, response.Code, path)
+		assert.Equal(t, "ok\n", response.Body.String(), path)
+		assert.Equal(t, http.StatusOK, s.request("HEAD", path, nil).Code, path)
+		assert.Equal(t, http.StatusMethodNotAllowed, s.request("POST", path, nil).Code, path)
+	}
+
+	// A schema migrated by a newer version is still served.
+	s.handler = New(s.repo, config.Config{TimeZone: "UTC"}, 1, nil)
+	assert.Equal(t, http.StatusOK, s.request("GET", "/readyz", nil).Code)
+	latest, err := database.LatestVersion("postgres")
+	require.NoError(t, err)
+	s.handler = New(s.repo, config.Config{TimeZone: "UTC"}, latest+1, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, s.request("GET", "/readyz", nil).Code)
+	s = newTestServer(t, config.Config{})
+
+	response := s.request("GET", "/static/htmx-2.0.3.js", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/javascript", response.Header().Get("Content-Type"))
+
+	// Every other route needs a session.
+	response = s.request("GET", "/chores?sort=name", nil)
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/login?next=%2Fchores%3Fsort%3Dname", response.Header().Get("Location"))
+	response = s.request("DELETE", "/chores/1/edit", nil, "HX-Request", "true")
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, "/login?next=%2Fchores%2F1%2Fedit", response.Header().Get("HX-Redirect"))
+	response = s.request("GET", "/api/v1/chores", nil)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.NotEmpty(t, response.Header().Get("WWW-Authenticate"))
+	s.session = &http.Cookie{Name: sessionCookieName, Value: "unknown"}
+	assert.Equal(t, http.StatusSeeOther, s.request("GET", "/", nil).Code)
+}
+
+func TestSetup(t *testing.T) {
+	s := newTestServer(t, config.Config{})
+
+	response := s.request("GET", "/login", nil)
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/setup", response.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, s.request("GET", "/setup", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", "/setup", nil).Code)
+
+	response = s.request("POST", "/setup", url.Values{"household-name": {"Home"}, "username": {"admin"}, "password": {"password"}, "password-confirm": {"other"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	count, err := s.repo.CountAccounts(s.ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count, "an invalid form is rendered again")
+
+	response = s.request("POST", "/setup", url.Values{"household-name": {"Home"}, "username": {"admin"}, "password": {"password"}, "password-confirm": {"password"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/", response.Header().Get("Location"))
+	cookies := response.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, sessionCookieName, cookies[0].Name)
+
+	// Once set up, the setup page is only reachable with registration allowed.
+	response = s.request("GET", "/setup", nil)
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/login", response.Header().Get("Location"))
+}
+
+func TestRegistration(t *testing.T) {
+	s := newTestServer(t, config.Config{AllowRegistration: true}).login()
+
+	assert.Equal(t, http.StatusOK, s.request("GET", "/setup", nil).Code)
+	response := s.request("POST", "/setup", url.Values{"household-name": {"Flat"}, "username": {"admin"}, "password": {"password"}, "password-confirm": {"password"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Username already taken")
+	response = s.request("POST", "/setup", url.Values{"household-name": {"Flat"}, "username": {"flatmate"}, "password": {"password"}, "password-confirm": {"password"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	households, err := s.repo.ListHouseholds(s.ctx)
+	require.NoError(t, err)
+	assert.Len(t, households, 2)
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	session := s.session
+	s.session = nil
+
+	response := s.request("GET", "/login?next=/chores", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `value="/chores"`)
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", "/login", nil).Code)
+
+	response = s.request("POST", "/login", url.Values{"username": {"admin"}, "password": {"wrong"}, "next": {"/chores"}})
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Contains(t, response.Body.String(), "Invalid username or password")
+
+	response = s.request("POST", "/login", url.Values{"username": {"admin"}, "password": {"password"}, "next": {"//example.com"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/", response.Header().Get("Location"), "only local redirections are allowed")
+	response = s.request("POST", "/login", url.Values{"username": {"admin"}, "password": {"password"}, "next": {"/chores"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/chores", response.Header().Get("Location"))
+	cookies := response.Result().Cookies()
+	require.Len(t, cookies, 1)
+	s.session = cookies[0]
+	assert.Equal(t, http.StatusOK, s.request("GET", "/", nil).Code)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("GET", "/logout", nil).Code)
+	response = s.request("POST", "/logout", nil)
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/login", response.Header().Get("Location"))
+	assert.Equal(t, http.StatusSeeOther, s.request("GET", "/", nil).Code, "the session is deleted")
+
+	s.session = session
+	assert.Equal(t, http.StatusOK, s.request("GET", "/", nil).Code, "other sessions are kept")
+}
+
+func TestIndex(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	chore := s.createChore("Dishes")
+	user := s.createUser("Alice")
+	s.createUser("Bob")
+	s.createTask(user, chore)
+
+	response := s.request("GET", "/", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Dishes")
+	assert.Contains(t, response.Body.String(), "Bob owes Alice 10 minutes")
+	assert.Equal(t, http.StatusOK, s.request("GET", "/?from=2024-01-01T00:00&to=not-a-date", nil).Code)
+	response = s.request("GET", "/?metric=points", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<option value="points" selected>`)
+	assert.Contains(t, response.Body.String(), "metric=points")
+	assert.Equal(t, http.StatusOK, s.request("GET", "/?metric=unknown", nil).Code)
+	response = s.request("GET", "/?bucket=month", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<option value="month" selected>`)
+	assert.Equal(t, http.StatusOK, s.request("GET", "/?bucket=unknown", nil).Code)
+	// A range too long for its bucket is grouped by longer periods.
+	response = s.request("GET", "/?from=0001-01-01T00:00&bucket=day", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<option value="month" selected>`)
+
+	var fairness fairnessResponse
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/reports/fairness?metric=tasks", "", &fairness).Code)
+	assert.Equal(t, repository.MetricTasks, fairness.Metric)
+	assert.Equal(t, int64(1), fairness.Total)
+	assert.Equal(t, []repository.UserFairness{
+		{User: "Alice", Share: 1, Done: 1, Target: 1, Balance: 0},
+		{User: "Bob", Share: 1, Done: 0, Target: 0, Balance: 0},
+	}, fairness.Users, "a single task cannot be split")
+	assert.Empty(t, fairness.Debts)
+	assert.Equal(t, http.StatusBadRequest, s.requestJSON("GET", "/api/v1/reports/fairness?metric=unknown", "", nil).Code)
+	response = s.requestJSON("GET", "/api/v1/reports/fairness?from=yesterday", "", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), `"code":"invalid_range"`)
+
+	response = s.request("GET", "/unknown", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestChores(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+
+	response := s.request("GET", "/chores/new", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", "/chores/new", nil).Code)
+	response = s.request("POST", "/chores/new", url.Values{"name": {"Dishes"}, "default_duration": {"15"}, "schedule-kind": {"yearly"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select a valid schedule")
+	response = s.request("POST", "/chores/new", url.Values{"name": {"Dishes"}, "default_duration": {"15"}, "schedule-kind": {"none"}, "difficulty": {"11"}})
+	assert.Contains(t, response.Body.String(), "Please enter a difficulty between 1 and 10")
+	response = s.request("POST", "/chores/new", url.Values{"name": {" Dishes "}, "default_duration": {"15"}, "schedule-kind": {"weekly"}, "schedule-weekday": {"1", "3"}, "difficulty": {"3"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/chores", response.Header().Get("Location"))
+	response = s.request("POST", "/chores/new", url.Values{"name": {"Dishes"}, "default_duration": {"15"}, "schedule-kind": {"none"}})
+	assert.Contains(t, response.Body.String(), "Name already taken")
+
+	chores, err := s.repo.ListChores(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, chores, 1)
+	chore := chores[0]
+	assert.Equal(t, "Dishes", chore.Name)
+	assert.Equal(t, int32(0b1010), chore.ScheduleWeekdays)
+	assert.Equal(t, int32(3), chore.Difficulty)
+
+	response = s.request("GET", "/chores", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Dishes")
+	choreURL := fmt.Sprintf("/chores/%d", chore.ID)
+	response = s.request("GET", choreURL, nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Dishes")
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", choreURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/chores/abc", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/chores/999", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/chores/999/edit", nil).Code)
+
+	editURL := choreURL + "/edit"
+	response = s.request("GET", editURL, nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Dishes")
+	response = s.request("PUT", editURL, url.Values{"name": {"Dishes"}, "default_duration": {"-1"}, "schedule-kind": {"none"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Default duration can")
+	response = s.request("PUT", editURL, url.Values{"name": {"Washing up"}, "default_duration": {"20"}, "schedule-kind": {"interval"}, "schedule-interval": {"2"}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, choreURL, response.Header().Get("HX-Location"))
+	chore, err = s.repo.GetChore(s.ctx, chore.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Washing up", chore.Name)
+	assert.Equal(t, int32(2), chore.ScheduleIntervalDays)
+
+	response = s.request("DELETE", editURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/chores", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("GET", choreURL, nil).Code)
+}
+
+func TestDoneChore(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	chore := s.createChore("Dishes")
+	user := s.createUser("Alice")
+	doneURL := fmt.Sprintf("/chores/%d/done", chore.ID)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("GET", doneURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", "/chores/abc/done", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", "/chores/999/done", nil).Code)
+
+	response := s.request("POST", doneURL, url.Values{"user-id": {""}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select an existing user")
+	response = s.request("POST", doneURL, url.Values{"user-id": {fmt.Sprint(user.ID)}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	tasks, err := s.repo.ListTasks(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, user.ID, tasks[0].UserID)
+	assert.Equal(t, chore.DefaultDurationMn, tasks[0].DurationMn)
+	assert.WithinDuration(t, time.Now(), tasks[0].StartedAt, time.Minute)
+
+	// The error of any field is shown, not only the one of the user.
+	broken, err := s.repo.CreateChore(s.ctx, postgres.CreateChoreParams{Name: "Broken", DefaultDurationMn: -5, ScheduleKind: "none"})
+	require.NoError(t, err)
+	response = s.request("POST", fmt.Sprintf("/chores/%d/done", broken.ID), url.Values{"user-id": {fmt.Sprint(user.ID)}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Duration can&#39;t be negative")
+}
+
+func TestTimers(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	chore := s.createChore("Dishes")
+	user := s.createUser("Alice")
+	startURL := fmt.Sprintf("/chores/%d/timer", chore.ID)
+	form := url.Values{"user-id": {fmt.Sprint(user.ID)}}
+
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("GET", startURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", "/chores/abc/timer", form).Code)
+	response := s.request("POST", startURL, url.Values{"user-id": {""}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select an existing user")
+	response = s.request("POST", "/chores/999/timer", form)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select an existing user and chore")
+
+	response = s.request("POST", startURL, form)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `hx-get="/timers"`)
+	response = s.request("POST", startURL, form)
+	assert.Contains(t, response.Body.String(), "This user already has a timer")
+	timers, err := s.repo.ListTimers(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, timers, 1)
+	timerURL := fmt.Sprintf("/timers/%d", timers[0].Timer.ID)
+
+	response = s.request("GET", "/timers", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Pause")
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("GET", timerURL+"/pause", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", timerURL+"/restart", form).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", "/timers/999/pause", form).Code)
+	response = s.request("POST", timerURL+"/pause", form)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Paused")
+	response = s.request("POST", timerURL+"/resume", form)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "Paused")
+
+	response = s.request("POST", timerURL+"/stop", form)
+	assert.Equal(t, http.StatusOK, response.Code)
+	tasks, err := s.repo.ListTasks(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, user.ID, tasks[0].UserID)
+	assert.Equal(t, timers[0].Timer.StartedAt, tasks[0].StartedAt)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", timerURL+"/discard", form).Code)
+	response = s.request("GET", "/timers", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "hx-get")
+}
+
+func TestEvents(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	listenCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listening := make(chan struct{})
+	go func() {
+		s.repo.ListenChanges(listenCtx)
+		close(listening)
+	}()
+	server := httptest.NewServer(s.handler)
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL+"/events", nil)
+	require.NoError(t, err)
+	request.AddCookie(s.session)
+	response, err := server.Client().Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	assert.Equal(t, "retry: 5000", <-lines)
+	assert.Equal(t, "", <-lines)
+
+	// The listener starts in the background: chores are created until their
+	// changes are streamed.
+	streamed := false
+	for attempt := 0; attempt < 100 && !streamed; attempt++ {
+		s.createChore(fmt.Sprintf("Chore %d", attempt))
+		select {
+		case line := <-lines:
+			assert.Equal(t, "event: chore", line)
+			assert.Equal(t, "data: chore.created", <-lines)
+			streamed = true
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	require.True(t, streamed, "no event streamed")
+
+	dashboard := s.request("GET", "/dashboard?user-id=1", nil)
+	assert.Equal(t, http.StatusOK, dashboard.Code)
+	assert.Contains(t, dashboard.Body.String(), `hx-get="/dashboard"`)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("POST", "/dashboard", nil).Code)
+
+	// The stream ends with the listener.
+	cancel()
+	<-listening
+	for range lines {
+	}
+}
+
+func TestUsers(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+
+	assert.Equal(t, http.StatusOK, s.request("GET", "/users/new", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("DELETE", "/users/new", nil).Code)
+	response := s.request("POST", "/users/new", url.Values{"name": {"Alice"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/users", response.Header().Get("Location"))
+	response = s.request("POST", "/users/new", url.Values{"name": {"Alice"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Name already taken")
+
+	users, err := s.repo.ListUsers(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	user := users[0]
+	response = s.request("GET", "/users", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Alice")
+	userURL := fmt.Sprintf("/users/%d", user.ID)
+	response = s.request("GET", userURL, nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Alice")
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", userURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/users/abc", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/users/999", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("PUT", "/users/999/edit", url.Values{"name": {"Bob"}}).Code)
+
+	editURL := userURL + "/edit"
+	assert.Equal(t, http.StatusOK, s.request("GET", editURL, nil).Code)
+	s.createUser("Bob")
+	response = s.request("PUT", editURL, url.Values{"name": {"Bob"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Name already taken")
+	response = s.request("PUT", editURL, url.Values{"name": {"Alicia"}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, userURL, response.Header().Get("HX-Location"))
+	user, err = s.repo.GetUser(s.ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alicia", user.Name)
+	response = s.request("PUT", editURL, url.Values{"name": {"Alicia"}, "share": {"0"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please enter a share between 1 and 100")
+	response = s.request("PUT", editURL, url.Values{"name": {"Alicia"}, "share": {"3"}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	user, err = s.repo.GetUser(s.ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), user.Share)
+
+	response = s.request("DELETE", editURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/users", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("GET", userURL, nil).Code)
+}
+
+func TestArchive(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	alice := s.createUser("Alice")
+	bob := s.createUser("Bob")
+	dishes := s.createChore("Dishes")
+	laundry := s.createChore("Laundry")
+	task := s.createTask(alice, dishes)
+
+	// Deleting a chore with tasks leads to the choice between archiving it and
+	// moving its tasks.
+	choreURL := fmt.Sprintf("/chores/%d", dishes.ID)
+	response := s.request("DELETE", choreURL+"/edit", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, choreURL+"/delete", response.Header().Get("HX-Location"))
+	response = s.request("GET", choreURL+"/delete", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Archive Instead")
+	assert.Contains(t, response.Body.String(), "Laundry")
+
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("GET", choreURL+"/archive", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", "/chores/999/archive", nil).Code)
+	response = s.request("POST", choreURL+"/archive", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, choreURL, response.Header().Get("HX-Location"))
+	response = s.request("GET", choreURL, nil)
+	assert.Contains(t, response.Body.String(), "Archived")
+	assert.Contains(t, response.Body.String(), "Restore")
+	response = s.request("GET", "/chores", nil)
+	assert.Contains(t, response.Body.String(), "Archived Chores")
+
+	// Archived chores are left out of the task forms, unless the task is theirs.
+	assert.NotContains(t, s.request("GET", "/tasks/new", nil).Body.String(), "Dishes")
+	assert.Contains(t, s.request("GET", "/tasks/"+task.ID.String(), nil).Body.String(), "Dishes")
+
+	assert.Equal(t, http.StatusNoContent, s.request("POST", choreURL+"/restore", nil).Code)
+	chore, err := s.repo.GetChore(s.ctx, dishes.ID)
+	require.NoError(t, err)
+	assert.False(t, chore.Archived)
+
+	response = s.request("POST", choreURL+"/delete", url.Values{"reassign-to": {choreURL}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select another chore")
+	response = s.request("POST", choreURL+"/delete", url.Values{"reassign-to": {strconv.Itoa(int(laundry.ID))}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/chores", response.Header().Get("Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("GET", choreURL, nil).Code)
+
+	userURL := fmt.Sprintf("/users/%d", alice.ID)
+	response = s.request("DELETE", userURL+"/edit", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, userURL+"/delete", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNoContent, s.request("POST", userURL+"/archive", nil).Code)
+	response = s.request("GET", "/users", nil)
+	assert.Contains(t, response.Body.String(), "Archived Users")
+	assert.NotContains(t, s.request("GET", "/tasks/new", nil).Body.String(), "Alice")
+	response = s.request("POST", userURL+"/delete", url.Values{"reassign-to": {strconv.Itoa(int(bob.ID))}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	moved, err := s.repo.GetTask(s.ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, moved.UserID)
+	assert.Equal(t, laundry.ID, moved.ChoreID)
+}
+
+func TestArchivedTasks(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	alice := s.createUser("Alice")
+	bob := s.createUser("Bob")
+	dishes := s.createChore("Dishes")
+	laundry := s.createChore("Laundry")
+	task := s.createTask(alice, dishes)
+	_, err := s.repo.SetChoreArchived(s.ctx, dishes.ID, true)
+	require.NoError(t, err)
+	_, err = s.repo.SetUserArchived(s.ctx, alice.ID, true)
+	require.NoError(t, err)
+	form := func(user postgres.User, chore postgres.Chore) url.Values {
+		return url.Values{"chore-id": {fmt.Sprint(chore.ID)}, "user-id": {fmt.Sprint(user.ID)}, "start-time": {"2024-05-01T10:00"}, "duration": {"45"}}
+	}
+
+	// New tasks can't be done on an archived chore or by an archived user.
+	response := s.request("POST", "/tasks/new", form(bob, dishes))
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "This chore is archived")
+	response = s.request("POST", "/tasks/new", form(alice, laundry))
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "This user is archived")
+	response = s.request("POST", fmt.Sprintf("/chores/%d/done", dishes.ID), url.Values{"user-id": {fmt.Sprint(bob.ID)}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "This chore is archived")
+	body := fmt.Sprintf(`{"user_id":%d,"chore_id":%d,"started_at":"2024-05-01T10:00:00Z","duration_mn":10}`, alice.ID, laundry.ID)
+	assert.Equal(t, http.StatusUnprocessableEntity, s.requestJSON("POST", "/api/v1/tasks", body, nil).Code)
+	response = s.request("POST", fmt.Sprintf("/chores/%d/timer", laundry.ID), url.Values{"user-id": {fmt.Sprint(alice.ID)}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Archived users and chores can&#39;t start a timer")
+	tasks, err := s.repo.ListTasks(s.ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	timers, err := s.repo.ListTimers(s.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, timers)
+
+	// An edited task keeps its archived chore and user.
+	s.request("PUT", "/tasks/"+task.ID.String(), form(alice, dishes))
+	edited, err := s.repo.GetTask(s.ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(45), edited.DurationMn)
+	body = fmt.Sprintf(`{"user_id":%d,"chore_id":%d,"started_at":"2024-05-01T10:00:00Z","duration_mn":30}`, alice.ID, dishes.ID)
+	assert.Equal(t, http.StatusOK, s.requestJSON("PUT", "/api/v1/tasks/"+task.ID.String(), body, nil).Code)
+	body = fmt.Sprintf(`{"user_id":%d,"chore_id":%d,"started_at":"2024-05-01T10:00:00Z","duration_mn":30}`, bob.ID, dishes.ID)
+	assert.Equal(t, http.StatusOK, s.requestJSON("PUT", "/api/v1/tasks/"+task.ID.String(), body, nil).Code, "only the user changes")
+	body = fmt.Sprintf(`{"user_id":%d,"chore_id":%d,"started_at":"2024-05-01T10:00:00Z","duration_mn":30}`, alice.ID, dishes.ID)
+	assert.Equal(t, http.StatusUnprocessableEntity, s.requestJSON("PUT", "/api/v1/tasks/"+task.ID.String(), body, nil).Code, "the task isn't Alice's anymore")
+}
+
+func TestTasks(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+
+	// The form can be shown before any chore or user exists.
+	assert.Equal(t, http.StatusOK, s.request("GET", "/tasks/new", nil).Code)
+	response := s.request("POST", "/tasks/new", url.Values{"chore-id": {""}, "user-id": {""}, "start-time": {"2024-05-01T10:00"}, "duration": {"10"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select an existing chore")
+
+	chore := s.createChore("Dishes")
+	user := s.createUser("Alice")
+	response = s.request("GET", "/tasks/new", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Dishes")
+	response = s.request("POST", "/tasks/new", url.Values{"chore-id": {fmt.Sprint(chore.ID)}, "user-id": {fmt.Sprint(user.ID)}, "start-time": {"yesterday"}, "duration": {"10"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please enter a valid date")
+	response = s.request("POST", "/tasks/new", url.Values{"chore-id": {fmt.Sprint(chore.ID)}, "user-id": {fmt.Sprint(user.ID)}, "start-time": {"2024-05-01T10:00"}, "duration": {"10"}, "description": {"After lunch"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/tasks", response.Header().Get("Location"))
+
+	tasks, err := s.repo.ListTasks(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	task := tasks[0]
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), task.StartedAt.UTC())
+	assert.Equal(t, "After lunch", task.Description)
+	response = s.request("GET", "/tasks", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "After lunch")
+
+	// The pages of the chore and the user chart their tasks.
+	response = s.request("GET", fmt.Sprintf("/chores/%d", chore.ID), nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"05/2024"`)
+	response = s.request("GET", fmt.Sprintf("/users/%d", user.ID), nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"type":"pie"`)
+	assert.Contains(t, response.Body.String(), `"coordinateSystem":"calendar"`)
+
+	taskURL := "/tasks/" + task.ID.String()
+	response = s.request("GET", taskURL, nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "2024-05-01T10:00")
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/tasks/abc", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/tasks/00000000-0000-0000-0000-000000000000", nil).Code)
+
+	response = s.request("PUT", taskURL, url.Values{"chore-id": {"999"}, "user-id": {fmt.Sprint(user.ID)}, "start-time": {"2024-05-01T10:00"}, "duration": {"10"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Chore not found")
+	response = s.request("PUT", taskURL, url.Values{"chore-id": {fmt.Sprint(chore.ID)}, "user-id": {fmt.Sprint(user.ID)}, "start-time": {"2024-05-02T08:30"}, "duration": {"25"}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/tasks", response.Header().Get("HX-Location"))
+	assert.Empty(t, response.Body.String())
+	task, err = s.repo.GetTask(s.ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(25), task.DurationMn)
+	assert.Equal(t, time.Date(2024, 5, 2, 8, 30, 0, 0, time.UTC), task.StartedAt.UTC())
+
+	response = s.request("DELETE", taskURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/tasks", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("GET", taskURL, nil).Code)
+}
+
+func TestTasksList(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	alice := s.createUser("Alice")
+	bob := s.createUser("Bob")
+	dishes := s.createChore("Dishes")
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := range repository.TasksPageSize + 5 {
+		_, err := s.repo.CreateTask(s.ctx, postgres.CreateTaskParams{UserID: alice.ID, ChoreID: dishes.ID, StartedAt: start.Add(time.Duration(i) * time.Hour), DurationMn: 10, Description: fmt.Sprintf("Alice task %d", i)})
+		require.NoError(t, err)
+	}
+	_, err := s.repo.CreateTask(s.ctx, postgres.CreateTaskParams{UserID: bob.ID, ChoreID: dishes.ID, StartedAt: start.AddDate(0, 1, 0), DurationMn: 45, Description: "Bob task"})
+	require.NoError(t, err)
+
+	response := s.request("GET", "/tasks", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	body := response.Body.String()
+	assert.Contains(t, body, "Bob task")
+	assert.NotContains(t, body, "Alice task 4<", "the oldest tasks are on the next page")
+	next := strings.Index(body, `hx-get="/tasks?cursor=`)
+	require.NotEqual(t, -1, next)
+	nextURL, _, _ := strings.Cut(body[next+len(`hx-get="`):], `"`)
+	response = s.request("GET", strings.ReplaceAll(nextURL, "&amp;", "&"), nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "<html")
+	assert.Contains(t, response.Body.String(), "Alice task 0<")
+	assert.NotContains(t, response.Body.String(), "cursor=", "the last page has no next page")
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/tasks?cursor=invalid", nil).Code)
+
+	response = s.request("GET", fmt.Sprintf("/tasks?user=%d", bob.ID), nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Bob task")
+	assert.NotContains(t, response.Body.String(), "Alice task")
+	response = s.request("GET", "/tasks?q=TASK+12&from=2024-05-01&to=2024-05-01", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Alice task 12<")
+	assert.NotContains(t, response.Body.String(), "Alice task 1<")
+	response = s.request("GET", "/tasks?q=task&from=2024-05-04", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Bob task")
+	assert.NotContains(t, response.Body.String(), "Alice task")
+	response = s.request("GET", "/tasks?user=abc&q=nothing", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "No task found")
+
+	// Sorting on a column links to the other order.
+	response = s.request("GET", "/tasks?sort=duration", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	body = response.Body.String()
+	assert.Less(t, strings.Index(body, "Bob task"), strings.Index(body, "Alice task"))
+	assert.Contains(t, body, `href="/tasks?order=asc&amp;sort=duration"`)
+}
+
+func TestImportTasks(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	file := "chore,user,started_at,duration_mn,description\nDishes,Alice,2024-05-01T10:00,10,\n"
+
+	assert.Equal(t, http.StatusOK, s.request("GET", "/tasks/import", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", "/tasks/import", nil).Code)
+	response := s.request("POST", "/tasks/import", url.Values{"csv": {"not,a\nvalid"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = s.request("POST", "/tasks/import", url.Values{"csv": {file}, "create-missing": {"on"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Dishes")
+	tasks, err := s.repo.ListTasks(s.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tasks, "the preview doesn't import anything")
+
+	response = s.request("POST", "/tasks/import", url.Values{"csv": {file}, "action": {"import"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code, "the chore and the user are missing")
+	response = s.request("POST", "/tasks/import", url.Values{"csv": {file}, "create-missing": {"on"}, "action": {"import"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/tasks", response.Header().Get("Location"))
+	tasks, err = s.repo.ListTasks(s.ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestExport(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	chore := s.createChore("Dishes")
+	user := s.createUser("Alice")
+	task := s.createTask(user, chore)
+
+	response := s.request("GET", "/export/tasks", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="tasks.csv"`, response.Header().Get("Content-Disposition"))
+	assert.Contains(t, response.Body.String(), task.ID.String()+",")
+
+	response = s.request("GET", "/export/tasks?format=json&from=2000-01-01T00:00&to=2000-01-02T00:00", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, "[]", response.Body.String())
+
+	response = s.request("GET", "/export/report?format=json", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var report exportedReport
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.Equal(t, map[string]map[string]int64{"Dishes": {"Alice": 20}}, report.Report.Report)
+	response = s.request("GET", "/export/report", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "chore,Alice\nDishes,20\n", response.Body.String())
+	response = s.request("GET", "/export/report?metric=tasks", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "chore,Alice\nDishes,1\n", response.Body.String())
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/export/report?metric=unknown", nil).Code)
+
+	response = s.request("GET", "/export/tasks?format=json&from="+time.Now().Add(-48*time.Hour).Format("2006-01-02T15:04"), nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), task.ID.String())
+
+	for _, path := range []string{"/export/tasks", "/export/report"} {
+		assert.Equal(t, http.StatusBadRequest, s.request("GET", path+"?from=yesterday", nil).Code, path)
+		assert.Equal(t, http.StatusBadRequest, s.request("GET", path+"?to=2024-13-01T00:00", nil).Code, path)
+		assert.Equal(t, http.StatusBadRequest, s.request("GET", path+"?format=xml", nil).Code, path)
+		assert.Equal(t, http.StatusMethodNotAllowed, s.request("POST", path, nil).Code, path)
+	}
+}
+
+func TestExportEscapesFormulas(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	chore := s.createChore("=HYPERLINK(\"http://evil\")")
+	user := s.createUser("@Alice")
+	task, err := s.repo.CreateTask(s.ctx, postgres.CreateTaskParams{UserID: user.ID, ChoreID: chore.ID, StartedAt: time.Now().Add(-time.Hour), DurationMn: 20, Description: "-1+1"})
+	require.NoError(t, err)
+
+	response := s.request("GET", "/export/tasks", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	records, err := csv.NewReader(response.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{task.ID.String(), "'=HYPERLINK(\"http://evil\")", "'@Alice", "20", "'-1+1"},
+		[]string{records[1][0], records[1][3], records[1][5], records[1][6], records[1][7]})
+
+	response = s.request("GET", "/export/report", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "chore,'@Alice\n\"'=HYPERLINK(\"\"http://evil\"\")\",20\n", response.Body.String())
+}
+
+func TestAccounts(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	user := s.createUser("Alice")
+
+	response := s.request("GET", "/accounts/new", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Alice")
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", "/accounts/new", nil).Code)
+	response = s.request("POST", "/accounts/new", url.Values{"username": {"admin"}, "password": {"password"}, "password-confirm": {"password"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Username already taken")
+	response = s.request("POST", "/accounts/new", url.Values{"username": {"alice"}, "password": {"password"}, "password-confirm": {"password"}, "user-id": {fmt.Sprint(user.ID)}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, "/accounts", response.Header().Get("Location"))
+
+	response = s.request("GET", "/accounts", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "alice")
+	accounts, err := s.repo.ListAccounts(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	account := accounts[1]
+	assert.Equal(t, pgtype.Int4{Int32: user.ID, Valid: true}, account.UserID)
+
+	editURL := fmt.Sprintf("/accounts/%d/edit", account.ID)
+	assert.Equal(t, http.StatusOK, s.request("GET", editURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/accounts/abc/edit", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/accounts/999/edit", nil).Code)
+	response = s.request("PUT", editURL, url.Values{"username": {"admin"}, "user-id": {fmt.Sprint(user.ID)}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Username already taken")
+	response = s.request("PUT", editURL, url.Values{"username": {"ali"}, "user-id": {""}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/accounts", response.Header().Get("HX-Location"))
+	account, err = s.repo.GetAccount(s.ctx, account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ali", account.Username)
+	assert.False(t, account.UserID.Valid)
+
+	response = s.request("DELETE", fmt.Sprintf("/accounts/%d/edit", s.account.ID), nil)
+	assert.Equal(t, http.StatusConflict, response.Code, "the current account can't be deleted")
+	response = s.request("DELETE", editURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/accounts", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("GET", editURL, nil).Code)
+}
+
+func TestWebhooks(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+
+	assert.Equal(t, http.StatusOK, s.request("GET", "/webhooks/new", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("PUT", "/webhooks/new", nil).Code)
+	response := s.request("POST", "/webhooks/new", url.Values{"url": {"ftp://example.com"}, "secret": {"secret"}, "events": {"task"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please enter an http or https URL")
+	response = s.request("POST", "/webhooks/new", url.Values{"url": {"https://example.com/hook"}, "secret": {"secret"}, "events": {"task"}})
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+
+	webhooks, err := s.repo.ListWebhooks(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	webhook := webhooks[0]
+	webhookURL := fmt.Sprintf("/webhooks/%d", webhook.ID)
+	assert.Equal(t, webhookURL, response.Header().Get("Location"))
+
+	response = s.request("GET", "/webhooks", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "https://example.com/hook")
+	s.createTask(s.createUser("Alice"), s.createChore("Dishes"))
+	response = s.request("GET", webhookURL, nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), repository.EventTaskCreated)
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", webhookURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", "/webhooks/abc", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/webhooks/999", nil).Code)
+
+	deliveries, err := s.repo.ListWebhookDeliveries(s.ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	retryURL := fmt.Sprintf("%s/deliveries/%s/retry", webhookURL, deliveries[0].ID)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.request("GET", retryURL, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", webhookURL+"/deliveries/abc/retry", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", fmt.Sprintf("%s/deliveries/%s/retry", webhookURL, uuid.New()), nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("POST", fmt.Sprintf("/webhooks/999/deliveries/%s/retry", deliveries[0].ID), nil).Code)
+	response = s.request("POST", retryURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, webhookURL, response.Header().Get("HX-Location"))
+
+	editURL := webhookURL + "/edit"
+	assert.Equal(t, http.StatusOK, s.request("GET", editURL, nil).Code)
+	response = s.request("PUT", editURL, url.Values{"url": {"https://example.com/hook"}, "secret": {"secret"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "Please select at least one kind of change")
+	response = s.request("PUT", editURL, url.Values{"url": {"https://example.com/other"}, "secret": {"secret"}, "events": {"chore", "user"}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, webhookURL, response.Header().Get("HX-Location"))
+	webhook, err = s.repo.GetWebhook(s.ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/other", webhook.Url)
+	assert.Equal(t, []string{"chore", "user"}, webhook.Events)
+
+	response = s.request("DELETE", editURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/webhooks", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("GET", webhookURL, nil).Code)
+}
+
+func TestCalendars(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	alice := s.createUser("Alice")
+	s.createTask(alice, s.createChore("Dishes"))
+
+	assert.Equal(t, http.StatusOK, s.request("GET", "/calendars", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", "/calendars", url.Values{"user-id": {"abc"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, s.request("POST", "/calendars", url.Values{"user-id": {"999"}}).Code)
+	response := s.request("POST", "/calendars", url.Values{"user-id": {strconv.Itoa(int(alice.ID))}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	body := response.Body.String()
+	start := strings.Index(body, "http://example.com/ical/")
+	require.NotEqual(t, -1, start, body)
+	feedURL, _, _ := strings.Cut(body[start:], `"`)
+	feedPath := strings.TrimPrefix(feedURL, "http://example.com")
+	assert.True(t, strings.HasSuffix(feedPath, ".ics"))
+	response = s.request("GET", "/calendars", nil)
+	assert.NotContains(t, response.Body.String(), feedURL, "the URL is only shown once")
+	assert.Contains(t, response.Body.String(), "Alice")
+
+	// Calendar applications fetch the feed without session.
+	session := s.session
+	s.session = nil
+	response = s.request("GET", feedPath, nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), "X-WR-CALNAME:Alice chores (Home)")
+	assert.Contains(t, response.Body.String(), "SUMMARY:Dishes")
+	assert.Equal(t, http.StatusNotFound, s.request("GET", "/ical/unknown.ics", nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", strings.TrimSuffix(feedPath, ".ics"), nil).Code)
+	assert.Equal(t, http.StatusSeeOther, s.request("GET", "/calendars", nil).Code)
+	s.session = session
+
+	feeds, err := s.repo.ListCalendarFeeds(s.ctx)
+	require.NoError(t, err)
+	require.Len(t, feeds, 1)
+	calendarURL := fmt.Sprintf("/calendars/%d", feeds[0].ID)
+	assert.Equal(t, http.StatusBadRequest, s.request("GET", calendarURL, nil).Code)
+	response = s.request("DELETE", calendarURL, nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, "/calendars", response.Header().Get("HX-Location"))
+	assert.Equal(t, http.StatusNotFound, s.request("DELETE", calendarURL, nil).Code)
+	assert.Equal(t, http.StatusNotFound, s.request("GET", feedPath, nil).Code)
+}
+
+func TestAPI(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+
+	var chore repository.Chore
+	response := s.requestJSON("POST", "/api/v1/chores", `{"name":"Dishes","default_duration_mn":15,"schedule_kind":"none"}`, &chore)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, fmt.Sprintf("/api/v1/chores/%d", chore.ID), response.Header().Get("Location"))
+	var apiErr map[string]apiError
+	response = s.requestJSON("POST", "/api/v1/chores", `{"name":"","schedule_kind":"none"}`, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, "name", apiErr["error"].Fields[0].Field)
+	response = s.requestJSON("POST", "/api/v1/chores", `{"title":"Dishes"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = s.requestJSON("PUT", fmt.Sprintf("/api/v1/chores/%d", chore.ID), `{"name":"Washing up","default_duration_mn":10,"schedule_kind":"none"}`, &chore)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "Washing up", chore.Name)
+	var chores []repository.Chore
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/chores", "", &chores).Code)
+	assert.Equal(t, []repository.Chore{chore}, chores)
+
+	var user repository.User
+	assert.Equal(t, http.StatusCreated, s.requestJSON("POST", "/api/v1/users", `{"name":"Alice"}`, &user).Code)
+	response = s.requestJSON("POST", "/api/v1/users", `{"name":"Alice"}`, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, http.StatusOK, s.requestJSON("PUT", fmt.Sprintf("/api/v1/users/%d", user.ID), `{"name":"Alicia"}`, &user).Code)
+	assert.Equal(t, "Alicia", user.Name)
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", fmt.Sprintf("/api/v1/users/%d", user.ID), "", &user).Code)
+
+	var task repository.Task
+	body := fmt.Sprintf(`{"user_id":%d,"chore_id":%d,"started_at":"2024-05-01T10:00:00Z","duration_mn":10}`, user.ID, chore.ID)
+	response = s.requestJSON("POST", "/api/v1/tasks", body, &task)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "/api/v1/tasks/"+task.ID.String(), response.Header().Get("Location"))
+	response = s.requestJSON("POST", "/api/v1/tasks", `{"user_id":999,"chore_id":999,"started_at":"2024-05-01T10:00:00Z"}`, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	body = fmt.Sprintf(`{"user_id":%d,"chore_id":%d,"started_at":"2024-05-01T11:00:00+02:00","duration_mn":30}`, user.ID, chore.ID)
+	assert.Equal(t, http.StatusOK, s.requestJSON("PUT", "/api/v1/tasks/"+task.ID.String(), body, &task).Code)
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), task.StartedAt.UTC())
+	var tasks []repository.Task
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/tasks", "", &tasks).Code)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/tasks/"+task.ID.String(), "", nil).Code)
+
+	response = s.requestJSON("DELETE", fmt.Sprintf("/api/v1/chores/%d", chore.ID), "", &apiErr)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "still_in_use", apiErr["error"].Code)
+	assert.Equal(t, http.StatusNoContent, s.requestJSON("DELETE", "/api/v1/tasks/"+task.ID.String(), "", nil).Code)
+	assert.Equal(t, http.StatusNoContent, s.requestJSON("DELETE", fmt.Sprintf("/api/v1/chores/%d", chore.ID), "", nil).Code)
+	assert.Equal(t, http.StatusNoContent, s.requestJSON("DELETE", fmt.Sprintf("/api/v1/users/%d", user.ID), "", nil).Code)
+
+	response = s.requestJSON("GET", fmt.Sprintf("/api/v1/chores/%d", chore.ID), "", &apiErr)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "not_found", apiErr["error"].Code)
+	assert.Equal(t, http.StatusNotFound, s.requestJSON("GET", "/api/v1/tasks/"+task.ID.String(), "", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.requestJSON("GET", "/api/v1/users/abc", "", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, s.requestJSON("GET", "/api/v1/tasks/abc", "", nil).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, s.requestJSON("PATCH", "/api/v1/chores/1", "", nil).Code)
+
+	// Scripts can authenticate with basic auth instead of a session.
+	request := httptest.NewRequest("GET", "/api/v1/users", nil)
+	request.SetBasicAuth("admin", "password")
+	basicResponse := httptest.NewRecorder()
+	s.handler.ServeHTTP(basicResponse, request)
+	assert.Equal(t, http.StatusOK, basicResponse.Code)
+	assert.JSONEq(t, "[]", basicResponse.Body.String())
+}
+
+func TestAPIArchive(t *testing.T) {
+	s := newTestServer(t, config.Config{}).login()
+	alice := s.createUser("Alice")
+	bob := s.createUser("Bob")
+	dishes := s.createChore("Dishes")
+	laundry := s.createChore("Laundry")
+	task := s.createTask(alice, dishes)
+
+	var chore repository.Chore
+	response := s.requestJSON("POST", fmt.Sprintf("/api/v1/chores/%d/archive", dishes.ID), "", &chore)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, chore.Archived)
+	var chores []repository.Chore
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/chores", "", &chores).Code)
+	require.Len(t, chores, 1)
+	assert.Equal(t, laundry.ID, chores[0].ID)
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/chores?archived=true", "", &chores).Code)
+	assert.Equal(t, []repository.Chore{chore}, chores)
+	assert.Equal(t, http.StatusBadRequest, s.requestJSON("GET", "/api/v1/chores?archived=maybe", "", nil).Code)
+	assert.Equal(t, http.StatusOK, s.requestJSON("POST", fmt.Sprintf("/api/v1/chores/%d/restore", dishes.ID), "", &chore).Code)
+	assert.False(t, chore.Archived)
+	assert.Equal(t, http.StatusNotFound, s.requestJSON("POST", "/api/v1/chores/999/archive", "", nil).Code)
+
+	var apiErr map[string]apiError
+	response = s.requestJSON("DELETE", fmt.Sprintf("/api/v1/chores/%d?reassign_to=%d", dishes.ID, dishes.ID), "", &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(t, "reassign_to", apiErr["error"].Fields[0].Field)
+	assert.Equal(t, http.StatusBadRequest, s.requestJSON("DELETE", fmt.Sprintf("/api/v1/chores/%d?reassign_to=abc", dishes.ID), "", nil).Code)
+	response = s.requestJSON("DELETE", fmt.Sprintf("/api/v1/chores/%d?reassign_to=%d", dishes.ID, laundry.ID), "", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	var user repository.User
+	assert.Equal(t, http.StatusOK, s.requestJSON("POST", fmt.Sprintf("/api/v1/users/%d/archive", alice.ID), "", &user).Code)
+	assert.True(t, user.Archived)
+	var users []repository.User
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/users?archived=true", "", &users).Code)
+	assert.Equal(t, []repository.User{user}, users)
+	response = s.requestJSON("DELETE", fmt.Sprintf("/api/v1/users/%d?reassign_to=%d", alice.ID, bob.ID), "", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	var moved repository.Task
+	assert.Equal(t, http.StatusOK, s.requestJSON("GET", "/api/v1/tasks/"+task.ID.String(), "", &moved).Code)
+	assert.Equal(t, bob.ID, moved.UserID)
+	assert.Equal(t, laundry.ID, moved.ChoreID)
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (h *HTTPServer) archiveChore(w http.ResponseWriter, r *http.Request) {
+	h.setChoreArchived(w, r, true)
+}
+
+func (h *HTTPServer) restoreChore(w http.ResponseWriter, r *http.Request) {
+	h.setChoreArchived(w, r, false)
+}
+
+func (h *HTTPServer) setChoreArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	choreID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	chore, err := h.repository.SetChoreArchived(r.Context(), int32(choreID), archived)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			html.NotFound().Render(r.Context(), w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to archive chore: %v", err))
+		return
+	}
+	w.Header().Add("HX-Location", fmt.Sprintf("/chores/%d", chore.ID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteChore offers the choice between archiving a chore that still has
+// tasks and moving its tasks to another chore before deleting it.
+func (h *HTTPServer) deleteChore(w http.ResponseWriter, r *http.Request) {
+	choreID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	chore, err := h.repository.GetChore(r.Context(), int32(choreID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		html.NotFound().Render(r.Context(), w)
+		return
+	}
+	deleteError := ""
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		toID, err := strconv.Atoi(r.FormValue("reassign-to"))
+		if err == nil {
+			err = h.repository.ReassignAndDeleteChore(r.Context(), chore.ID, int32(toID))
+			if err == nil {
+				http.Redirect(w, r, "/chores", http.StatusSeeOther)
+				return
+			}
+			if !errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusInternalServerError)
+				slog.ErrorContext(r.Context(), fmt.Sprintf("unable to reassign and delete chore: %v", err))
+				return
+			}
+		}
+		deleteError = "Please select another chore"
+	} else if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	tasks, err := h.repository.GetChoreTasks(r.Context(), chore.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list chore tasks: %v", err))
+		return
+	}
+	chores, err := h.repository.ListChores(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list chores: %v", err))
+		return
+	}
+	chores = slices.DeleteFunc(chores, func(other postgres.Chore) bool { return other.ID == chore.ID })
+	html.ChoreDelete(chore, len(tasks), chores, deleteError).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) archiveUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserArchived(w, r, true)
+}
+
+func (h *HTTPServer) restoreUser(w http.ResponseWriter, r *http.Request) {
+	h.setUserArchived(w, r, false)
+}
+
+func (h *HTTPServer) setUserArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	userID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	user, err := h.repository.SetUserArchived(r.Context(), int32(userID), archived)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			html.NotFound().Render(r.Context(), w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to archive user: %v", err))
+		return
+	}
+	w.Header().Add("HX-Location", fmt.Sprintf("/users/%d", user.ID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteUser offers the choice between archiving a user who still has tasks
+// and moving their tasks to another user before deleting them.
+func (h *HTTPServer) deleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	user, err := h.repository.GetUser(r.Context(), int32(userID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		html.NotFound().Render(r.Context(), w)
+		return
+	}
+	deleteError := ""
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		toID, err := strconv.Atoi(r.FormValue("reassign-to"))
+		if err == nil {
+			err = h.repository.ReassignAndDeleteUser(r.Context(), user.ID, int32(toID))
+			if err == nil {
+				http.Redirect(w, r, "/users", http.StatusSeeOther)
+				return
+			}
+			if !errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusInternalServerError)
+				slog.ErrorContext(r.Context(), fmt.Sprintf("unable to reassign and delete user: %v", err))
+				return
+			}
+		}
+		deleteError = "Please select another user"
+	} else if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	tasks, err := h.repository.GetUserTasks(r.Context(), user.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list user tasks: %v", err))
+		return
+	}
+	users, err := h.repository.ListUsers(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list users: %v", err))
+		return
+	}
+	users = slices.DeleteFunc(users, func(other postgres.User) bool { return other.ID == user.ID })
+	html.UserDelete(user, len(tasks), users, deleteError).Render(r.Context(), w)
+}
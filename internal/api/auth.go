@@ -0,0 +1,291 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+const sessionCookieName = "wdtc_session"
+
+type contextKey int
+
+const (
+	accountContextKey contextKey = iota
+	sessionContextKey
+)
+
+// publicPaths can be reached without being authenticated.
+var publicPaths = []string{"/login", "/setup", "/healthz", "/readyz"}
+
+func isPublicPath(path string) bool {
+	// Calendar feeds are protected by the token in their URL instead.
+	if strings.HasPrefix(path, "/static/") || strings.HasPrefix(path, "/ical/") {
+		return true
+	}
+	for _, publicPath := range publicPaths {
+		if path == publicPath {
+			return true
+		}
+	}
+	return false
+}
+
+func isAPIPath(path string) bool {
+	return strings.HasPrefix(path, "/api/")
+}
+
+func accountFromContext(ctx context.Context) (postgres.Account, bool) {
+	account, ok := ctx.Value(accountContextKey).(postgres.Account)
+	return account, ok
+}
+
+// authenticate wraps the application handler and only lets requests with a
+// valid session through. The JSON API additionally accepts HTTP basic auth so
+// that scripts don't have to handle cookies.
+func (h *HTTPServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := r.Context()
+		var account postgres.Account
+		var err error
+		if cookie, cookieErr := r.Cookie(sessionCookieName); cookieErr == nil {
+			account, err = h.repository.GetSessionAccount(ctx, cookie.Value)
+			ctx = context.WithValue(ctx, sessionContextKey, cookie.Value)
+		} else if username, password, ok := r.BasicAuth(); ok && isAPIPath(r.URL.Path) {
+			account, err = h.repository.Authenticate(ctx, username, password)
+		} else {
+			err = repository.ErrNotFound
+		}
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) && !errors.Is(err, repository.ErrInvalidCredentials) {
+				slog.ErrorContext(ctx, fmt.Sprintf("unable to authenticate request: %v", err))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			h.unauthorized(w, r)
+			return
+		}
+		ctx = context.WithValue(ctx, accountContextKey, account)
+		ctx = repository.WithHousehold(ctx, account.HouseholdID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (h *HTTPServer) unauthorized(w http.ResponseWriter, r *http.Request) {
+	if isAPIPath(r.URL.Path) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="whodidthechores"`)
+		writeJSONError(w, r, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+	loginURL := "/login?next=" + url.QueryEscape(r.URL.RequestURI())
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", loginURL)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	http.Redirect(w, r, loginURL, http.StatusSeeOther)
+}
+
+// safeRedirect only allows redirections to local paths.
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+func (h *HTTPServer) startSession(w http.ResponseWriter, r *http.Request, account postgres.Account) error {
+	token, expiresAt, err := h.repository.CreateSession(r.Context(), account.ID, h.session.Duration)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   h.session.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (h *HTTPServer) login(w http.ResponseWriter, r *http.Request) {
+	count, err := h.repository.CountAccounts(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to count accounts: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if count == 0 {
+		http.Redirect(w, r, "/setup", http.StatusSeeOther)
+		return
+	}
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		loginParams := html.LoginParams{
+			Username: strings.TrimSpace(r.FormValue("username")),
+			Next:     safeRedirect(r.FormValue("next")),
+		}
+		account, err := h.repository.Authenticate(r.Context(), loginParams.Username, r.FormValue("password"))
+		if err != nil {
+			if errors.Is(err, repository.ErrInvalidCredentials) {
+				slog.WarnContext(r.Context(), fmt.Sprintf("failed login attempt for %q", loginParams.Username))
+				loginParams.Error = "Invalid username or password"
+				w.WriteHeader(http.StatusUnauthorized)
+				html.Login(loginParams, h.allowRegistration).Render(r.Context(), w)
+				return
+			}
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to authenticate: %v", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err = h.startSession(w, r, account); err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create session: %v", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, loginParams.Next, http.StatusSeeOther)
+		return
+	}
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	html.Login(html.LoginParams{Next: safeRedirect(r.URL.Query().Get("next"))}, h.allowRegistration).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if token, ok := r.Context().Value(sessionContextKey).(string); ok {
+		if err := h.repository.DeleteSession(r.Context(), token); err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to delete session: %v", err))
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.session.SecureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// setup creates the first account of a fresh installation, attached to the
+// household created when migrating existing data if there is one. When
+// registration is allowed, it also lets visitors create new households.
+func (h *HTTPServer) setup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	count, err := h.repository.CountAccounts(ctx)
+	if err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to count accounts: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if count > 0 && !h.allowRegistration {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	var existingHousehold *postgres.Household
+	users := []postgres.User{}
+	if count == 0 {
+		households, err := h.repository.ListHouseholds(ctx)
+		if err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list households: %v", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if len(households) > 0 {
+			existingHousehold = &households[0]
+			ctx = repository.WithHousehold(ctx, existingHousehold.ID)
+			users, err = h.repository.ListUsers(ctx)
+			if err != nil {
+				slog.ErrorContext(r.Context(), fmt.Sprintf("Unable to list users %v", err))
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	householdParams := repository.HouseholdParams{}
+	if existingHousehold != nil {
+		householdParams.Name = existingHousehold.Name
+	}
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		accountParams := repository.AccountParams{
+			ID:              -1,
+			Username:        strings.TrimSpace(r.FormValue("username")),
+			Password:        r.FormValue("password"),
+			PasswordConfirm: r.FormValue("password-confirm"),
+		}
+		if existingHousehold != nil {
+			accountParams.UserID = r.FormValue("user-id")
+		} else {
+			householdParams.Name = strings.TrimSpace(r.FormValue("household-name"))
+		}
+		_, householdErr := h.repository.ValidateHousehold(&householdParams)
+		validatedAccount, err := h.repository.ValidateAccount(ctx, &accountParams)
+		if err != nil || householdErr != nil {
+			if err == nil || errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusOK)
+				html.Setup(householdParams, accountParams, users, existingHousehold == nil).Render(r.Context(), w)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate account: %v", err))
+			return
+		}
+		var account postgres.Account
+		if existingHousehold != nil {
+			account, err = h.repository.CreateAccount(ctx, validatedAccount)
+		} else {
+			_, account, err = h.repository.CreateHousehold(ctx, householdParams.Name, validatedAccount)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create account: %v", err))
+			return
+		}
+		if err = h.startSession(w, r, account); err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create session: %v", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	html.Setup(householdParams, repository.AccountParams{}, users, existingHousehold == nil).Render(r.Context(), w)
+}
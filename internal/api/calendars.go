@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// calendarFeedURL returns the absolute URL of the feed of the token, on the
+// host the request was made to since calendar applications need a full URL.
+func calendarFeedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/ical/%s.ics", scheme, r.Host, token)
+}
+
+// calendars lists the calendar feeds of the household and creates new ones.
+// The URL of a new feed is only shown once, its token not being stored.
+func (h *HTTPServer) calendars(w http.ResponseWriter, r *http.Request) {
+	var feedURL string
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		var userID pgtype.Int4
+		if rawUserID := r.FormValue("user-id"); rawUserID != "" {
+			id, err := strconv.Atoi(rawUserID)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			userID = pgtype.Int4{Int32: int32(id), Valid: true}
+		}
+		_, token, err := h.repository.CreateCalendarFeed(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create calendar feed: %v", err))
+			return
+		}
+		feedURL = calendarFeedURL(r, token)
+	} else if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	feeds, err := h.repository.ListCalendarFeeds(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list calendar feeds: %v", err))
+		return
+	}
+	users, err := h.repository.ListAllUsers(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list users: %v", err))
+		return
+	}
+	html.Calendars(feeds, users, feedURL, h.timezone).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) deleteCalendar(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if r.Method != "DELETE" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err = h.repository.DeleteCalendarFeed(r.Context(), int32(feedID)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			html.NotFound().Render(r.Context(), w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to delete calendar feed: %v", err))
+		return
+	}
+	w.Header().Add("HX-Location", "/calendars")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// calendarFeed serves the iCalendar file of a feed to calendar applications.
+// It is public: the token in the file name is the credential.
+func (h *HTTPServer) calendarFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	calendar, err := h.repository.GetCalendar(r.Context(), token, time.Now(), h.timezone)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to get calendar: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err = calendar.Encode(w); err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to write calendar: %v", err))
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// eventsRetry is the delay before the browser reconnects after losing the
+	// stream of events.
+	eventsRetry = 5 * time.Second
+	// eventsKeepAlive is the interval of the comments sent to keep idle
+	// proxies from closing the stream.
+	eventsKeepAlive = 30 * time.Second
+)
+
+// events streams the changes of the household as server-sent events named
+// after the changed resource, with the event as data, e.g. "event: task" and
+// "data: task.created". The pages refresh what they show on these events.
+func (h *HTTPServer) events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	changes, err := h.repository.SubscribeChanges(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to subscribe to changes: %v", err))
+		return
+	}
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Reverse proxies such as nginx buffer the responses otherwise.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventsRetry.Milliseconds())
+	if err := controller.Flush(); err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to stream events: %v", err))
+		return
+	}
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Resource(), change.Event)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// exportedTask is a task as exported, with the names of its chore and user.
+type exportedTask struct {
+	ID          uuid.UUID `json:"id"`
+	StartedAt   string    `json:"started_at"`
+	ChoreID     int32     `json:"chore_id"`
+	Chore       string    `json:"chore"`
+	UserID      int32     `json:"user_id"`
+	User        string    `json:"user"`
+	DurationMn  int32     `json:"duration_mn"`
+	Description string    `json:"description"`
+	Points      int64     `json:"points"`
+}
+
+type exportedReport struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	TimeZone string `json:"timezone"`
+	repository.Report
+}
+
+// exportFormat returns the format asked with the 'format' query parameter,
+// "csv" by default.
+func exportFormat(r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return "csv", true
+	case "csv", "json":
+		return format, true
+	}
+	return "", false
+}
+
+func setAttachment(w http.ResponseWriter, name string, format string) {
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+}
+
+func (h *HTTPServer) exportTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	format, ok := exportFormat(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// Tasks are only restricted to a range when one is asked for.
+	var taskRows []postgres.ListUsersTasksRow
+	var err error
+	if r.URL.Query().Has("from") || r.URL.Query().Has("to") {
+		from, to, rangeErr := h.reportRange(r)
+		if rangeErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var rangeRows []postgres.ListUsersTasksBetweenRow
+		rangeRows, err = h.repository.ListUsersTasksBetween(r.Context(), 0, from, to)
+		for _, row := range rangeRows {
+			taskRows = append(taskRows, postgres.ListUsersTasksRow(row))
+		}
+	} else {
+		taskRows, err = h.repository.ListUsersTasks(r.Context())
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list tasks: %v", err))
+		return
+	}
+	tasks := make([]exportedTask, 0, len(taskRows))
+	for _, taskRow := range taskRows {
+		tasks = append(tasks, h.exportedTask(taskRow))
+	}
+	setAttachment(w, "tasks", format)
+	if format == "json" {
+		if err = json.NewEncoder(w).Encode(tasks); err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to export tasks: %v", err))
+		}
+		return
+	}
+	if err = writeTasksCSV(w, tasks); err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to export tasks: %v", err))
+	}
+}
+
+func (h *HTTPServer) exportedTask(taskRow postgres.ListUsersTasksRow) exportedTask {
+	return exportedTask{
+		ID:          taskRow.Task.ID,
+		StartedAt:   taskRow.Task.StartedAt.In(h.timezone).Format(time.RFC3339),
+		ChoreID:     taskRow.Chore.ID,
+		Chore:       taskRow.Chore.Name,
+		UserID:      taskRow.User.ID,
+		User:        taskRow.User.Name,
+		DurationMn:  taskRow.Task.DurationMn,
+		Description: taskRow.Task.Description,
+		Points:      taskRow.Task.Points,
+	}
+}
+
+// csvCell escapes a text value so that a spreadsheet opening the file doesn't
+// evaluate it as a formula.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func writeTasksCSV(w io.Writer, tasks []exportedTask) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "started_at", "chore_id", "chore", "user_id", "user", "duration_mn", "description", "points"})
+	for _, task := range tasks {
+		writer.Write([]string{
+			task.ID.String(),
+			task.StartedAt,
+			strconv.FormatInt(int64(task.ChoreID), 10),
+			csvCell(task.Chore),
+			strconv.FormatInt(int64(task.UserID), 10),
+			csvCell(task.User),
+			strconv.FormatInt(int64(task.DurationMn), 10),
+			csvCell(task.Description),
+			strconv.FormatInt(task.Points, 10),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (h *HTTPServer) exportReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	format, ok := exportFormat(r)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	metric, err := repository.ParseReportMetric(r.URL.Query().Get("metric"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	from, to, err := h.reportRange(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	report, err := h.repository.GetChoreReport(r.Context(), from, to, metric)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to generate report: %v", err))
+		return
+	}
+	setAttachment(w, "report", format)
+	if format == "json" {
+		err = json.NewEncoder(w).Encode(exportedReport{
+			From:     from.In(h.timezone).Format(time.RFC3339),
+			To:       to.In(h.timezone).Format(time.RFC3339),
+			TimeZone: h.timezone.String(),
+			Report:   report,
+		})
+		if err != nil {
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to export report: %v", err))
+		}
+		return
+	}
+	if err = writeReportCSV(w, report); err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to export report: %v", err))
+	}
+}
+
+// writeReportCSV writes the metric of the report for each user on each chore,
+// one line per chore and one column per user.
+func writeReportCSV(w io.Writer, report repository.Report) error {
+	writer := csv.NewWriter(w)
+	header := []string{"chore"}
+	for _, user := range report.Users {
+		header = append(header, csvCell(user))
+	}
+	writer.Write(header)
+	for _, chore := range report.Chores {
+		line := []string{csvCell(chore)}
+		for _, user := range report.Users {
+			line = append(line, strconv.FormatInt(report.Report[chore][user], 10))
+		}
+		writer.Write(line)
+	}
+	writer.Flush()
+	return writer.Error()
+}
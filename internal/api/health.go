@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds the database checks of a readiness probe.
+const readyTimeout = 2 * time.Second
+
+// healthz tells that the process is alive, without checking its dependencies
+// so that a database outage doesn't restart every replica.
+func (h *HTTPServer) healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+// readyz tells whether requests can be served: the database can be reached
+// and its schema is at least the one expected by this version of the
+// application. A newer schema is accepted because migrations only add to the
+// schema, so that the replicas of the previous version stay ready while a
+// rolling update migrates the database.
+func (h *HTTPServer) readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+	if err := h.repository.Ping(ctx); err != nil {
+		slog.WarnContext(r.Context(), fmt.Sprintf("not ready, unable to reach the database: %v", err))
+		http.Error(w, "database unreachable", http.StatusServiceUnavailable)
+		return
+	}
+	version, dirty, err := h.repository.SchemaVersion(ctx)
+	if err != nil {
+		slog.WarnContext(r.Context(), fmt.Sprintf("not ready: %v", err))
+		http.Error(w, "unable to get schema version", http.StatusServiceUnavailable)
+		return
+	}
+	if dirty || version < h.schemaVersion {
+		slog.WarnContext(r.Context(), fmt.Sprintf("not ready, schema version is %d (dirty: %t), %d or later expected", version, dirty, h.schemaVersion))
+		http.Error(w, fmt.Sprintf("schema version %d, %d or later expected", version, h.schemaVersion), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// maxImportSize is the maximum size of an imported CSV file.
+const maxImportSize = 10 << 20
+
+// importTasks previews an uploaded CSV file of tasks, then imports it once
+// confirmed. The content of the file is sent back by the preview page so that
+// it doesn't have to be uploaded twice.
+func (h *HTTPServer) importTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		html.TasksImport(html.TaskImport{CreateMissing: true}).Render(r.Context(), w)
+		return
+	}
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		w.WriteHeader(http.StatusBadRequest)
+		slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+		return
+	}
+	taskImport := html.TaskImport{
+		CSV:           r.FormValue("csv"),
+		CreateMissing: r.FormValue("create-missing") != "",
+	}
+	if file, _, err := r.FormFile("file"); err == nil {
+		content, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to read imported file: %v", err))
+			return
+		}
+		taskImport.CSV = string(content)
+	}
+	rows, err := repository.ParseTasksCSV(strings.NewReader(taskImport.CSV))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		taskImport.Error = fmt.Sprintf("Unable to read this file: %v", err)
+		html.TasksImport(taskImport).Render(r.Context(), w)
+		return
+	}
+	taskImport.Rows = rows
+	options := repository.ImportOptions{CreateMissing: taskImport.CreateMissing, Location: h.timezone}
+	if r.FormValue("action") != "import" {
+		err = h.repository.ValidateImport(r.Context(), rows, options)
+		if err != nil && !errors.Is(err, repository.ErrValidation) {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate import: %v", err))
+			return
+		}
+		taskImport.Previewed = true
+		html.TasksImport(taskImport).Render(r.Context(), w)
+		return
+	}
+	result, err := h.repository.ImportTasks(r.Context(), rows, options)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			taskImport.Previewed = true
+			html.TasksImport(taskImport).Render(r.Context(), w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to import tasks: %v", err))
+		return
+	}
+	slog.InfoContext(r.Context(), fmt.Sprintf("imported %d tasks, %d chores and %d users", result.Tasks, result.Chores, result.Users))
+	http.Redirect(w, r, "/tasks", http.StatusSeeOther)
+}
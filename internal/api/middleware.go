@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// statusRecorder keeps the status and size of a response for the access log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.size += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the original writer, e.g. to
+// flush it.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// validRequestID tells whether a request ID sent by a client or a proxy can
+// be reused, to follow a request across services.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 64 {
+		return false
+	}
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID gives each request an ID, the one of the X-Request-ID header when
+// valid, carried by its context and sent back in the response.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// loggedPath returns the path of a request as logged, without the token of a
+// calendar feed: it is the credential of the feed.
+func loggedPath(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/ical/") {
+		return "/ical/{file}"
+	}
+	return r.URL.Path
+}
+
+// accessLog logs every request with its status and duration. The probes of
+// the health endpoints are only logged at debug level.
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			level = slog.LevelDebug
+		}
+		slog.Log(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", loggedPath(r)),
+			slog.Int("status", recorder.status),
+			slog.Int("size", recorder.size),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
+
+// recoverPanic turns a panic of a handler into a 500 response instead of
+// closing the connection, and logs it with its stack trace.
+func recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			slog.ErrorContext(r.Context(), fmt.Sprintf("panic serving %s %s: %v", r.Method, loggedPath(r), recovered),
+				slog.String("stack", string(debug.Stack())))
+			if isAPIPath(r.URL.Path) {
+				writeJSONError(w, r, http.StatusInternalServerError, "internal_error", "internal server error")
+				return
+			}
+			requestID, _ := logging.RequestID(r.Context())
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusInternalServerError)
+			html.InternalError(requestID).Render(r.Context(), w)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
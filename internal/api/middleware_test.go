@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mqufflc/whodidthechores/internal/config"
+	"github.com/mqufflc/whodidthechores/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewares(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(logging.New(&logs, config.LogConfig{Level: "info", Format: "text"}))
+	defer slog.SetDefault(defaultLogger)
+
+	handler := requestID(accessLog(recoverPanic(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" || r.URL.Path == "/api/v1/panic" {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusTeapot)
+	}))))
+
+	request := httptest.NewRequest("GET", "/teapot", nil)
+	request.Header.Set(requestIDHeader, "abc-123")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusTeapot, response.Code)
+	assert.Equal(t, "abc-123", response.Header().Get(requestIDHeader))
+	assert.Contains(t, logs.String(), "path=/teapot status=418")
+	assert.Contains(t, logs.String(), "request_id=abc-123")
+
+	logs.Reset()
+	request = httptest.NewRequest("GET", "/ical/s3cr3t-token.ics", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Contains(t, logs.String(), "path=/ical/{file} status=418")
+	assert.NotContains(t, logs.String(), "s3cr3t-token", "the feed token is a credential")
+
+	logs.Reset()
+	request = httptest.NewRequest("GET", "/panic", nil)
+	request.Header.Set(requestIDHeader, "not valid")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	generatedID := response.Header().Get(requestIDHeader)
+	assert.Len(t, generatedID, 36)
+	assert.Contains(t, response.Body.String(), generatedID)
+	assert.Contains(t, logs.String(), "panic serving GET /panic: boom")
+	assert.Contains(t, logs.String(), "status=500")
+
+	request = httptest.NewRequest("GET", "/api/v1/panic", nil)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.JSONEq(t, `{"error":{"code":"internal_error","message":"internal server error"}}`, response.Body.String())
+}
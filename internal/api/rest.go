@@ -0,0 +1,683 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/metrics"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+type apiError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []fieldError `json:"fields,omitempty"`
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type choreRequest struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	DefaultDurationMn    int32  `json:"default_duration_mn"`
+	ScheduleKind         string `json:"schedule_kind"`
+	ScheduleIntervalDays int32  `json:"schedule_interval_days"`
+	ScheduleWeekdays     int32  `json:"schedule_weekdays"`
+	ScheduleMonthDay     int32  `json:"schedule_month_day"`
+	// Difficulty is the lowest one when omitted.
+	Difficulty int32 `json:"difficulty"`
+}
+
+type userRequest struct {
+	Name string `json:"name"`
+	// Share is the lowest one when omitted.
+	Share int32 `json:"share"`
+}
+
+type taskRequest struct {
+	UserID      int32  `json:"user_id"`
+	ChoreID     int32  `json:"chore_id"`
+	StartedAt   string `json:"started_at"`
+	DurationMn  int32  `json:"duration_mn"`
+	Description string `json:"description"`
+}
+
+// apiV1 returns the JSON API handler. It is a dedicated mux so that unknown
+// methods on known routes are answered with 405 instead of the HTML 404 page.
+func (h *HTTPServer) apiV1(httpMetrics *metrics.HTTPMetrics) http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler http.HandlerFunc) {
+		mux.Handle(pattern, httpMetrics.Instrument(pattern, handler))
+	}
+	handle("GET /api/v1/chores", h.apiListChores)
+	handle("POST /api/v1/chores", h.apiCreateChore)
+	handle("GET /api/v1/chores/{id}", h.apiGetChore)
+	handle("PUT /api/v1/chores/{id}", h.apiUpdateChore)
+	handle("DELETE /api/v1/chores/{id}", h.apiDeleteChore)
+	handle("POST /api/v1/chores/{id}/archive", h.apiArchiveChore)
+	handle("POST /api/v1/chores/{id}/restore", h.apiRestoreChore)
+	handle("GET /api/v1/users", h.apiListUsers)
+	handle("POST /api/v1/users", h.apiCreateUser)
+	handle("GET /api/v1/users/{id}", h.apiGetUser)
+	handle("PUT /api/v1/users/{id}", h.apiUpdateUser)
+	handle("DELETE /api/v1/users/{id}", h.apiDeleteUser)
+	handle("POST /api/v1/users/{id}/archive", h.apiArchiveUser)
+	handle("POST /api/v1/users/{id}/restore", h.apiRestoreUser)
+	handle("GET /api/v1/tasks", h.apiListTasks)
+	handle("POST /api/v1/tasks", h.apiCreateTask)
+	handle("GET /api/v1/tasks/{id}", h.apiGetTask)
+	handle("PUT /api/v1/tasks/{id}", h.apiUpdateTask)
+	handle("DELETE /api/v1/tasks/{id}", h.apiDeleteTask)
+	handle("GET /api/v1/reports/fairness", h.apiFairnessReport)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to encode json response: %v", err))
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, r *http.Request, status int, code string, message string, fields ...fieldError) {
+	writeJSON(w, r, status, map[string]apiError{"error": {Code: code, Message: message, Fields: fields}})
+}
+
+// writeRepositoryError maps repository errors to their HTTP status code.
+func writeRepositoryError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		writeJSONError(w, r, http.StatusNotFound, "not_found", "resource not found")
+	case errors.Is(err, repository.ErrStillInUse):
+		writeJSONError(w, r, http.StatusConflict, "still_in_use", "resource is still referenced by tasks")
+	case errors.Is(err, repository.ErrDuplicateName):
+		writeJSONError(w, r, http.StatusConflict, "duplicate_name", "name already taken")
+	default:
+		slog.ErrorContext(r.Context(), fmt.Sprintf("api repository error: %v", err))
+		writeJSONError(w, r, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_json", fmt.Sprintf("unable to decode request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func pathInt32(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 32)
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_id", "id must be an integer")
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func pathUUID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_id", "id must be a uuid")
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func appendFieldError(fields []fieldError, field string, message string) []fieldError {
+	if message == "" {
+		return fields
+	}
+	return append(fields, fieldError{Field: field, Message: message})
+}
+
+func choreFieldErrors(e repository.ChoreParamsError) []fieldError {
+	var fields []fieldError
+	fields = appendFieldError(fields, "name", e.Name)
+	fields = appendFieldError(fields, "description", e.Description)
+	fields = appendFieldError(fields, "default_duration_mn", e.DefaultDurationMn)
+	fields = appendFieldError(fields, "schedule", e.Schedule)
+	fields = appendFieldError(fields, "difficulty", e.Difficulty)
+	return fields
+}
+
+func userFieldErrors(e repository.UserParamsError) []fieldError {
+	var fields []fieldError
+	fields = appendFieldError(fields, "name", e.Name)
+	fields = appendFieldError(fields, "share", e.Share)
+	return fields
+}
+
+func taskFieldErrors(e repository.TaskParamsError) []fieldError {
+	var fields []fieldError
+	fields = appendFieldError(fields, "user_id", e.UserID)
+	fields = appendFieldError(fields, "chore_id", e.ChoreID)
+	fields = appendFieldError(fields, "started_at", e.StartedAt)
+	fields = appendFieldError(fields, "duration_mn", e.DurationMn)
+	fields = appendFieldError(fields, "description", e.Description)
+	return fields
+}
+
+// queryArchived reads the 'archived' query parameter of the lists: archived
+// resources are listed instead of the others when it is true.
+func queryArchived(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	rawArchived := r.URL.Query().Get("archived")
+	if rawArchived == "" {
+		return false, true
+	}
+	archived, err := strconv.ParseBool(rawArchived)
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_archived", "archived must be true or false")
+		return false, false
+	}
+	return archived, true
+}
+
+// queryReassignTo reads the 'reassign_to' query parameter of the deletions:
+// the ID the tasks of the deleted resource are moved to, 0 when absent.
+func queryReassignTo(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	rawID := r.URL.Query().Get("reassign_to")
+	if rawID == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseInt(rawID, 10, 32)
+	if err != nil || id <= 0 {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_reassign_to", "reassign_to must be an id")
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (u userRequest) userParams(id int32) repository.UserParams {
+	if u.Share == 0 {
+		u.Share = repository.MinShare
+	}
+	return repository.UserParams{
+		ID:    id,
+		Name:  strings.TrimSpace(u.Name),
+		Share: strconv.FormatInt(int64(u.Share), 10),
+	}
+}
+
+func (c choreRequest) choreParams(id int32) repository.ChoreParams {
+	var weekdays []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if c.ScheduleWeekdays&(1<<day) != 0 {
+			weekdays = append(weekdays, strconv.Itoa(int(day)))
+		}
+	}
+	if c.ScheduleWeekdays&^0x7f != 0 {
+		// Keep invalid bits so that validation rejects them.
+		weekdays = append(weekdays, "-1")
+	}
+	if c.Difficulty == 0 {
+		c.Difficulty = repository.MinDifficulty
+	}
+	return repository.ChoreParams{
+		ID:                   id,
+		Name:                 strings.TrimSpace(c.Name),
+		Description:          strings.TrimSpace(c.Description),
+		DefaultDurationMn:    strconv.FormatInt(int64(c.DefaultDurationMn), 10),
+		ScheduleKind:         c.ScheduleKind,
+		ScheduleIntervalDays: strconv.FormatInt(int64(c.ScheduleIntervalDays), 10),
+		ScheduleWeekdays:     weekdays,
+		ScheduleMonthDay:     strconv.FormatInt(int64(c.ScheduleMonthDay), 10),
+		Difficulty:           strconv.FormatInt(int64(c.Difficulty), 10),
+	}
+}
+
+func (h *HTTPServer) apiListChores(w http.ResponseWriter, r *http.Request) {
+	archived, ok := queryArchived(w, r)
+	if !ok {
+		return
+	}
+	list := h.repository.ListChores
+	if archived {
+		list = h.repository.ListArchivedChores
+	}
+	chores, err := list(r.Context())
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	response := make([]repository.Chore, len(chores))
+	for index, chore := range chores {
+		response[index] = repository.Chore(chore)
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h *HTTPServer) apiGetChore(w http.ResponseWriter, r *http.Request) {
+	choreID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	chore, err := h.repository.GetChore(r.Context(), choreID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.Chore(chore))
+}
+
+func (h *HTTPServer) apiCreateChore(w http.ResponseWriter, r *http.Request) {
+	var request choreRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	choreParams := request.choreParams(-1)
+	choreParamsValidated, err := h.repository.ValidateChore(r.Context(), &choreParams)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid chore", choreFieldErrors(choreParams.Errors)...)
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	chore, err := h.repository.CreateChore(r.Context(), choreParamsValidated)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/chores/%d", chore.ID))
+	writeJSON(w, r, http.StatusCreated, repository.Chore(chore))
+}
+
+func (h *HTTPServer) apiUpdateChore(w http.ResponseWriter, r *http.Request) {
+	choreID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	chore, err := h.repository.GetChore(r.Context(), choreID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	var request choreRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	choreParams := request.choreParams(chore.ID)
+	choreParamsValidated, err := h.repository.ValidateChore(r.Context(), &choreParams)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid chore", choreFieldErrors(choreParams.Errors)...)
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	chore, err = h.repository.UpdateChore(r.Context(), chore.ID, choreParamsValidated)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.Chore(chore))
+}
+
+func (h *HTTPServer) apiDeleteChore(w http.ResponseWriter, r *http.Request) {
+	choreID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	reassignTo, ok := queryReassignTo(w, r)
+	if !ok {
+		return
+	}
+	chore, err := h.repository.GetChore(r.Context(), choreID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	if reassignTo != 0 {
+		err = h.repository.ReassignAndDeleteChore(r.Context(), chore.ID, reassignTo)
+	} else {
+		err = h.repository.DeleteChore(r.Context(), chore.ID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid chore to reassign the tasks to",
+				fieldError{Field: "reassign_to", Message: "must be another chore of the household"})
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HTTPServer) apiArchiveChore(w http.ResponseWriter, r *http.Request) {
+	h.apiSetChoreArchived(w, r, true)
+}
+
+func (h *HTTPServer) apiRestoreChore(w http.ResponseWriter, r *http.Request) {
+	h.apiSetChoreArchived(w, r, false)
+}
+
+func (h *HTTPServer) apiSetChoreArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	choreID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	chore, err := h.repository.SetChoreArchived(r.Context(), choreID, archived)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.Chore(chore))
+}
+
+func (h *HTTPServer) apiListUsers(w http.ResponseWriter, r *http.Request) {
+	archived, ok := queryArchived(w, r)
+	if !ok {
+		return
+	}
+	list := h.repository.ListUsers
+	if archived {
+		list = h.repository.ListArchivedUsers
+	}
+	users, err := list(r.Context())
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	response := make([]repository.User, len(users))
+	for index, user := range users {
+		response[index] = repository.User(user)
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h *HTTPServer) apiGetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.repository.GetUser(r.Context(), userID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.User(user))
+}
+
+func (h *HTTPServer) apiCreateUser(w http.ResponseWriter, r *http.Request) {
+	var request userRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	userParams := request.userParams(-1)
+	userParamsValidated, err := h.repository.ValidateUser(r.Context(), &userParams)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid user", userFieldErrors(userParams.Errors)...)
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	user, err := h.repository.CreateUser(r.Context(), userParamsValidated)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/users/%d", user.ID))
+	writeJSON(w, r, http.StatusCreated, repository.User(user))
+}
+
+func (h *HTTPServer) apiUpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.repository.GetUser(r.Context(), userID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	var request userRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	userParams := request.userParams(user.ID)
+	userParamsValidated, err := h.repository.ValidateUser(r.Context(), &userParams)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid user", userFieldErrors(userParams.Errors)...)
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	user, err = h.repository.UpdateUser(r.Context(), user.ID, userParamsValidated)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.User(user))
+}
+
+func (h *HTTPServer) apiDeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	reassignTo, ok := queryReassignTo(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.repository.GetUser(r.Context(), userID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	if reassignTo != 0 {
+		err = h.repository.ReassignAndDeleteUser(r.Context(), user.ID, reassignTo)
+	} else {
+		err = h.repository.DeleteUser(r.Context(), user.ID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid user to reassign the tasks to",
+				fieldError{Field: "reassign_to", Message: "must be another user of the household"})
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HTTPServer) apiArchiveUser(w http.ResponseWriter, r *http.Request) {
+	h.apiSetUserArchived(w, r, true)
+}
+
+func (h *HTTPServer) apiRestoreUser(w http.ResponseWriter, r *http.Request) {
+	h.apiSetUserArchived(w, r, false)
+}
+
+func (h *HTTPServer) apiSetUserArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	userID, ok := pathInt32(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.repository.SetUserArchived(r.Context(), userID, archived)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.User(user))
+}
+
+func (h *HTTPServer) apiListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.repository.ListTasks(r.Context())
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	response := make([]repository.Task, len(tasks))
+	for index, task := range tasks {
+		response[index] = repository.Task(task)
+	}
+	writeJSON(w, r, http.StatusOK, response)
+}
+
+func (h *HTTPServer) apiGetTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := pathUUID(w, r)
+	if !ok {
+		return
+	}
+	task, err := h.repository.GetTask(r.Context(), taskID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.Task(task))
+}
+
+// taskParamsFromRequest converts a JSON task into the form parameters used by
+// ValidateTask. RFC 3339 timestamps are accepted and returned so that their
+// precision survives the minute based form layout.
+func (h *HTTPServer) taskParamsFromRequest(id uuid.UUID, request taskRequest) (repository.TaskParams, *time.Time) {
+	taskParams := repository.TaskParams{
+		ID:          id,
+		UserID:      strconv.FormatInt(int64(request.UserID), 10),
+		ChoreID:     strconv.FormatInt(int64(request.ChoreID), 10),
+		StartedAt:   request.StartedAt,
+		DurationMn:  strconv.FormatInt(int64(request.DurationMn), 10),
+		Description: strings.TrimSpace(request.Description),
+	}
+	startedAt, err := time.Parse(time.RFC3339, request.StartedAt)
+	if err != nil {
+		return taskParams, nil
+	}
+	taskParams.StartedAt = startedAt.In(h.timezone).Format("2006-01-02T15:04")
+	return taskParams, &startedAt
+}
+
+func (h *HTTPServer) apiCreateTask(w http.ResponseWriter, r *http.Request) {
+	var request taskRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	taskParams, startedAt := h.taskParamsFromRequest(uuid.UUID{}, request)
+	taskParamsValidated, err := h.repository.ValidateTask(r.Context(), &taskParams, *h.timezone)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid task", taskFieldErrors(taskParams.Errors)...)
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	if startedAt != nil {
+		taskParamsValidated.StartedAt = *startedAt
+	}
+	task, err := h.repository.CreateTask(r.Context(), taskParamsValidated)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/tasks/%v", task.ID.String()))
+	writeJSON(w, r, http.StatusCreated, repository.Task(task))
+}
+
+func (h *HTTPServer) apiUpdateTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := pathUUID(w, r)
+	if !ok {
+		return
+	}
+	task, err := h.repository.GetTask(r.Context(), taskID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	var request taskRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	taskParams, startedAt := h.taskParamsFromRequest(task.ID, request)
+	taskParamsValidated, err := h.repository.ValidateTask(r.Context(), &taskParams, *h.timezone)
+	if err != nil {
+		if errors.Is(err, repository.ErrValidation) {
+			writeJSONError(w, r, http.StatusUnprocessableEntity, "validation_error", "invalid task", taskFieldErrors(taskParams.Errors)...)
+			return
+		}
+		writeRepositoryError(w, r, err)
+		return
+	}
+	if startedAt != nil {
+		taskParamsValidated.StartedAt = *startedAt
+	}
+	task, err = h.repository.UpdateTask(r.Context(), task.ID, taskParamsValidated)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, repository.Task(task))
+}
+
+func (h *HTTPServer) apiDeleteTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := pathUUID(w, r)
+	if !ok {
+		return
+	}
+	task, err := h.repository.GetTask(r.Context(), taskID)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	if err = h.repository.DeleteTask(r.Context(), task.ID); err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fairnessResponse is a fairness report with the range it covers.
+type fairnessResponse struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	TimeZone string `json:"timezone"`
+	repository.FairnessReport
+}
+
+// apiFairnessReport compares what each user did with their share, over the
+// same 'from', 'to' and 'metric' query parameters as the home page.
+func (h *HTTPServer) apiFairnessReport(w http.ResponseWriter, r *http.Request) {
+	metric, err := repository.ParseReportMetric(r.URL.Query().Get("metric"))
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_metric", "metric must be minutes, tasks or points")
+		return
+	}
+	from, to, err := h.reportRange(r)
+	if err != nil {
+		writeJSONError(w, r, http.StatusBadRequest, "invalid_range", "from and to must be formatted as 2006-01-02T15:04")
+		return
+	}
+	report, err := h.repository.GetFairnessReport(r.Context(), from, to, metric)
+	if err != nil {
+		writeRepositoryError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, fairnessResponse{
+		From:           from.In(h.timezone).Format(time.RFC3339),
+		To:             to.In(h.timezone).Format(time.RFC3339),
+		TimeZone:       h.timezone.String(),
+		FairnessReport: report,
+	})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// timers renders the timers alone, for the dashboard to refresh them while
+// they run.
+func (h *HTTPServer) timers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	timers, err := h.repository.ListTimers(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list timers: %v", err))
+		return
+	}
+	html.TimersList(timers, time.Now()).Render(r.Context(), w)
+}
+
+// startTimer starts a timer of the chore for the user of the dashboard and
+// renders the updated dashboard.
+func (h *HTTPServer) startTimer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	choreID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+		return
+	}
+	userID := r.FormValue("user-id")
+	dashboardError := ""
+	if id, err := strconv.Atoi(userID); err != nil {
+		dashboardError = "Please select an existing user"
+	} else if _, err = h.repository.StartTimer(r.Context(), int32(id), int32(choreID), time.Now()); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTimerRunning):
+			dashboardError = "This user already has a timer, stop it first"
+		case errors.Is(err, repository.ErrNotFound):
+			dashboardError = "Please select an existing user and chore"
+		case errors.Is(err, repository.ErrArchived):
+			dashboardError = "Archived users and chores can't start a timer"
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to start timer: %v", err))
+			return
+		}
+	}
+	h.renderDashboard(w, r, userID, dashboardError)
+}
+
+// timerAction pauses, resumes, stops or discards a timer and renders the
+// updated dashboard. Stopping a timer logs its task.
+func (h *HTTPServer) timerAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	timerID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+		return
+	}
+	now := time.Now()
+	switch r.PathValue("action") {
+	case "pause":
+		_, err = h.repository.PauseTimer(r.Context(), int32(timerID), now)
+	case "resume":
+		_, err = h.repository.ResumeTimer(r.Context(), int32(timerID), now)
+	case "stop":
+		_, err = h.repository.StopTimer(r.Context(), int32(timerID), now)
+	case "discard":
+		err = h.repository.DiscardTimer(r.Context(), int32(timerID))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to %s timer: %v", r.PathValue("action"), err))
+		return
+	}
+	h.renderDashboard(w, r, r.FormValue("user-id"), "")
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/html"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+)
+
+// deliveriesLogSize is the number of deliveries shown on a webhook page.
+const deliveriesLogSize = 50
+
+func webhookParamsFromForm(r *http.Request, id int32) repository.WebhookParams {
+	return repository.WebhookParams{
+		ID:     id,
+		URL:    strings.TrimSpace(r.FormValue("url")),
+		Secret: strings.TrimSpace(r.FormValue("secret")),
+		Events: r.Form["events"],
+	}
+}
+
+func (h *HTTPServer) webhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.repository.ListWebhooks(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list webhooks: %v", err))
+		return
+	}
+	html.Webhooks(webhooks).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) viewWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	webhook, err := h.repository.GetWebhook(r.Context(), int32(webhookID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		html.NotFound().Render(r.Context(), w)
+		return
+	}
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	deliveries, err := h.repository.ListWebhookDeliveries(r.Context(), webhook.ID, deliveriesLogSize)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to list webhook deliveries: %v", err))
+		return
+	}
+	html.WebhookView(repository.NewWebhookParams(webhook), deliveries, h.timezone).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) createWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		webhookParams := webhookParamsFromForm(r, -1)
+		validatedWebhook, err := h.repository.ValidateWebhook(&webhookParams)
+		if err != nil {
+			if errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusOK)
+				html.WebhookCreate(webhookParams).Render(r.Context(), w)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate webhook: %v", err))
+			return
+		}
+		webhook, err := h.repository.CreateWebhook(r.Context(), validatedWebhook)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to create webhook: %v", err))
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/webhooks/%d", webhook.ID), http.StatusSeeOther)
+		return
+	}
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	html.WebhookCreate(repository.WebhookParams{Events: repository.WebhookResources}).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) editWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	webhook, err := h.repository.GetWebhook(r.Context(), int32(webhookID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		html.NotFound().Render(r.Context(), w)
+		return
+	}
+	if r.Method == "PUT" {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			slog.WarnContext(r.Context(), fmt.Sprintf("unable to parse form: %v", err))
+			return
+		}
+		webhookParams := webhookParamsFromForm(r, webhook.ID)
+		validatedWebhook, err := h.repository.ValidateWebhook(&webhookParams)
+		if err != nil {
+			if errors.Is(err, repository.ErrValidation) {
+				w.WriteHeader(http.StatusOK)
+				html.WebhookEdit(webhookParams).Render(r.Context(), w)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to validate webhook: %v", err))
+			return
+		}
+		webhook, err = h.repository.UpdateWebhook(r.Context(), webhook.ID, validatedWebhook)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to edit webhook: %v", err))
+			return
+		}
+		w.Header().Add("HX-Location", fmt.Sprintf("/webhooks/%d", webhook.ID))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method == "DELETE" {
+		if err = h.repository.DeleteWebhook(r.Context(), webhook.ID); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), fmt.Sprintf("unable to delete webhook: %v", err))
+			return
+		}
+		w.Header().Add("HX-Location", "/webhooks")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	html.WebhookEdit(repository.NewWebhookParams(webhook)).Render(r.Context(), w)
+}
+
+func (h *HTTPServer) retryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	webhookID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	deliveryID, err := uuid.Parse(r.PathValue("delivery"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err = h.repository.RetryWebhookDelivery(r.Context(), int32(webhookID), deliveryID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			html.NotFound().Render(r.Context(), w)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), fmt.Sprintf("unable to retry webhook delivery: %v", err))
+		return
+	}
+	w.Header().Add("HX-Location", fmt.Sprintf("/webhooks/%d", webhookID))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -13,15 +13,33 @@ import (
 )
 
 type DbConfig struct {
+	// Driver is the database storing the data: 'postgres', or 'sqlite' for an
+	// embedded database file, e.g. on a single-board computer.
+	Driver string `mapstructure:"driver"`
+	// Path is the file of the SQLite database.
+	Path     string `mapstructure:"path"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	Hostname string `mapstructure:"hostname"`
 	Port     int    `mapstructure:"port"`
 	Database string `mapstructure:"database"`
 	SslMode  string `mapstructure:"sslmode"`
+	// AutoMigrate applies the migrations when the server starts. It can be
+	// disabled when they are applied separately with the migrate command.
+	AutoMigrate bool `mapstructure:"automigrate"`
 }
 
 func (c DbConfig) Validate() error {
+	switch c.Driver {
+	case "postgres":
+	case "sqlite":
+		if c.Path == "" {
+			return errors.New("database path is required with sqlite")
+		}
+		return nil
+	default:
+		return errors.New("only 'postgres' or 'sqlite' are supported for database driver")
+	}
 	if c.Username == "" {
 		return errors.New("database username is required")
 	}
@@ -38,19 +56,109 @@ func (c DbConfig) Validate() error {
 	return nil
 }
 
+type SessionConfig struct {
+	Duration     time.Duration `mapstructure:"duration"`
+	SecureCookie bool          `mapstructure:"securecookie"`
+}
+
+func (c SessionConfig) Validate() error {
+	if c.Duration < time.Minute {
+		return errors.New("session duration must be at least one minute")
+	}
+	return nil
+}
+
+type WebhooksConfig struct {
+	PollInterval time.Duration `mapstructure:"pollinterval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	// Backoff is the delay before the first retry of a failed delivery, doubled
+	// at each new attempt.
+	Backoff     time.Duration `mapstructure:"backoff"`
+	MaxAttempts int           `mapstructure:"maxattempts"`
+	// AllowPrivate lets webhooks reach loopback, private and link-local
+	// addresses, e.g. a home automation server on the local network.
+	AllowPrivate bool `mapstructure:"allowprivate"`
+}
+
+func (c WebhooksConfig) Validate() error {
+	if c.PollInterval < time.Second {
+		return errors.New("webhooks poll interval must be at least one second")
+	}
+	if c.Timeout < time.Second {
+		return errors.New("webhooks timeout must be at least one second")
+	}
+	if c.Backoff < time.Second {
+		return errors.New("webhooks backoff must be at least one second")
+	}
+	if c.MaxAttempts < 1 {
+		return errors.New("webhooks max attempts must be at least 1")
+	}
+	return nil
+}
+
+type LogConfig struct {
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
+}
+
+func (c LogConfig) Validate() error {
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, c.Level) {
+		return errors.New("only 'debug', 'info', 'warn' or 'error' are supported for log level")
+	}
+	if !slices.Contains([]string{"text", "json"}, c.Format) {
+		return errors.New("only 'text' or 'json' are supported for log format")
+	}
+	return nil
+}
+
+// SlogLevel returns the level of the logger, info when unknown.
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
 type Config struct {
-	Port     int      `mapstructure:"port"`
-	Database DbConfig `mapstructure:"database"`
-	TimeZone string   `mapstructure:"timezone"`
+	Port     int            `mapstructure:"port"`
+	Database DbConfig       `mapstructure:"database"`
+	TimeZone string         `mapstructure:"timezone"`
+	Session  SessionConfig  `mapstructure:"session"`
+	Webhooks WebhooksConfig `mapstructure:"webhooks"`
+	Log      LogConfig      `mapstructure:"log"`
+	// MetricsPort serves the Prometheus metrics, apart from the application so
+	// that they aren't exposed with it. 0 disables them.
+	MetricsPort int `mapstructure:"metricsport"`
+	// ShutdownTimeout is how long the requests in progress are waited for
+	// once a stop signal is received.
+	ShutdownTimeout time.Duration `mapstructure:"shutdowntimeout"`
+	// AllowRegistration lets visitors create new households from the login page.
+	AllowRegistration bool `mapstructure:"allowregistration"`
 }
 
 func (c *Config) Validate() error {
 	if c.Port < 1024 || c.Port > 65_535 {
 		return errors.New("application port must be between 1024 and 65 535")
 	}
+	if c.MetricsPort != 0 && (c.MetricsPort < 1024 || c.MetricsPort > 65_535 || c.MetricsPort == c.Port) {
+		return errors.New("metrics port must be between 1024 and 65 535 and differ from the application port, or 0 to disable metrics")
+	}
+	if c.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout can't be negative")
+	}
 	if err := c.Database.Validate(); err != nil {
 		return err
 	}
+	if err := c.Session.Validate(); err != nil {
+		return err
+	}
+	if err := c.Webhooks.Validate(); err != nil {
+		return err
+	}
+	if err := c.Log.Validate(); err != nil {
+		return err
+	}
 	_, err := time.LoadLocation(c.TimeZone)
 	if err != nil {
 		slog.Error(fmt.Sprintf("Unrecognized time zone: %v, UTC will be used instead", c.TimeZone))
@@ -80,13 +188,28 @@ func New() (Config, error) {
 	viperInstance.AutomaticEnv()
 
 	viperInstance.SetDefault("port", 8080)
+	viperInstance.SetDefault("metricsPort", 8081)
 	viperInstance.SetDefault("timezone", "UTC")
+	viperInstance.SetDefault("shutdownTimeout", "20s")
+	viperInstance.SetDefault("database.driver", "postgres")
+	viperInstance.SetDefault("database.path", "whodidthechores.db")
 	viperInstance.SetDefault("database.username", "")
 	viperInstance.SetDefault("database.password", "")
 	viperInstance.SetDefault("database.hostname", "")
 	viperInstance.SetDefault("database.database", "whodidthechores")
 	viperInstance.SetDefault("database.port", 5432)
 	viperInstance.SetDefault("database.sslMode", "disable")
+	viperInstance.SetDefault("database.autoMigrate", true)
+	viperInstance.SetDefault("session.duration", "720h")
+	viperInstance.SetDefault("session.secureCookie", false)
+	viperInstance.SetDefault("allowRegistration", false)
+	viperInstance.SetDefault("webhooks.pollInterval", "10s")
+	viperInstance.SetDefault("webhooks.timeout", "10s")
+	viperInstance.SetDefault("webhooks.backoff", "30s")
+	viperInstance.SetDefault("webhooks.maxAttempts", 8)
+	viperInstance.SetDefault("webhooks.allowPrivate", false)
+	viperInstance.SetDefault("log.level", "info")
+	viperInstance.SetDefault("log.format", "text")
 
 	err = viperInstance.Unmarshal(&config)
 	if err != nil {
@@ -6,12 +6,14 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/url"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mqufflc/whodidthechores/internal/config"
@@ -20,6 +22,24 @@ import (
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
+//go:embed sqlite/*.sql
+var embedSQLiteMigrations embed.FS
+
+// migrationSource returns the embedded migrations of a database driver.
+func migrationSource(driver string) (source.Driver, error) {
+	var sourceDriver source.Driver
+	var err error
+	if driver == "sqlite" {
+		sourceDriver, err = iofs.New(embedSQLiteMigrations, "sqlite")
+	} else {
+		sourceDriver, err = iofs.New(embedMigrations, "migrations")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to access embeded migrations: %w", err)
+	}
+	return sourceDriver, nil
+}
+
 type Effector func(str string) error
 
 func retry(effector Effector, retries int, delay time.Duration) Effector {
@@ -52,57 +72,167 @@ func checkDatabaseConnectiviy(connString string) error {
 	return nil
 }
 
-func Migrate(connString string) error {
+// WaitForDatabase checks the connectivity to the database, retrying for up
+// to five minutes while it starts.
+func WaitForDatabase(connString string) error {
 	slog.Info("checking database connectivity")
 	r := retry(checkDatabaseConnectiviy, 30, 10*time.Second)
-	err := r(connString)
-	if err != nil {
+	if err := r(connString); err != nil {
 		return fmt.Errorf("all attempts to connect to database failed: %w", err)
 	}
-	slog.Info("applying migrations")
+	return nil
+}
+
+// Migrator manages the schema of the database with the embedded migrations.
+type Migrator struct {
+	m  *migrate.Migrate
+	db *sql.DB
+}
+
+// NewMigrator opens a dedicated connection to the database. It must be closed
+// once done.
+func NewMigrator(connString string) (*Migrator, error) {
 	db, err := sql.Open("pgx", connString)
 	if err != nil {
-		return fmt.Errorf("unable to connect to database before migrations: %w", err)
+		return nil, fmt.Errorf("unable to connect to database before migrations: %w", err)
 	}
-	defer func() {
-		if closeErr := db.Close(); closeErr != nil {
-			slog.Warn("failed to close migration db connection")
-		}
-	}()
 
 	pg_driver, err := pgx.WithInstance(db, &pgx.Config{})
 	if err != nil {
-		return err
+		db.Close()
+		return nil, err
 	}
 
-	source_driver, err := iofs.New(embedMigrations, "migrations")
+	source_driver, err := migrationSource("postgres")
 	if err != nil {
-		return fmt.Errorf("unable to access embeded migrations: %w", err)
+		db.Close()
+		return nil, err
 	}
 
 	m, err := migrate.NewWithInstance("iofs", source_driver, "pgx5", pg_driver)
 	if err != nil {
-		return fmt.Errorf("unable to create migration instance: %w", err)
+		db.Close()
+		return nil, fmt.Errorf("unable to create migration instance: %w", err)
 	}
-	if err := m.Up(); err != nil {
-		switch {
-		case errors.Is(err, migrate.ErrNoChange):
+	return &Migrator{m: m, db: db}, nil
+}
+
+// Up applies every migration not applied yet.
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
 			slog.Info("No new migration to apply.")
 			return nil
-		default:
-			return err
 		}
-	} else {
-		slog.Info("migrations applied")
-		return nil
+		return err
+	}
+	slog.Info("migrations applied")
+	return nil
+}
+
+// Down reverts the given number of migrations.
+func (m *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return errors.New("at least one migration must be reverted")
+	}
+	if err := m.m.Steps(-steps); err != nil {
+		return err
+	}
+	slog.Info(fmt.Sprintf("%d migrations reverted", steps))
+	return nil
+}
+
+// Version returns the version of the last applied migration, 0 when none
+// was, and whether the last migration failed, leaving the schema dirty.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
 	}
+	return version, dirty, err
+}
+
+// Force sets the version of the schema without running any migration, to
+// recover from a failed one once the schema was fixed by hand.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return dbErr
+}
+
+// Migrate waits for the database and applies every migration not applied yet.
+func Migrate(connString string) error {
+	if err := WaitForDatabase(connString); err != nil {
+		return err
+	}
+	slog.Info("applying migrations")
+	migrator, err := NewMigrator(connString)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := migrator.Close(); closeErr != nil {
+			slog.Warn("failed to close migration db connection")
+		}
+	}()
+	return migrator.Up()
+}
+
+// OpenMigrator waits for the configured database and returns the migrator of
+// its driver. It must be closed once done.
+func OpenMigrator(config config.DbConfig) (*Migrator, error) {
+	if config.Driver == "sqlite" {
+		return NewSQLiteMigrator(config.Path)
+	}
+	connString := ConnectionString(config)
+	if err := WaitForDatabase(connString); err != nil {
+		return nil, err
+	}
+	return NewMigrator(connString)
+}
+
+// LatestVersion returns the version of the last embedded migration of a
+// database driver, the version of an up to date schema.
+func LatestVersion(driver string) (uint, error) {
+	source, err := migrationSource(driver)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// ConnectionString returns the URL of the configured database.
+func ConnectionString(config config.DbConfig) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s", config.Username, url.QueryEscape(config.Password), config.Hostname, config.Port, config.Database, config.SslMode)
 }
 
+// Connect waits for the database and opens a pool of connections to it. The
+// migrations are not applied.
 func Connect(ctx context.Context, config config.DbConfig) (*pgxpool.Pool, error) {
-	connectionString := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s", config.Username, url.QueryEscape(config.Password), config.Hostname, config.Port, config.Database, config.SslMode)
+	connectionString := ConnectionString(config)
 
-	if err := Migrate(connectionString); err != nil {
-		return nil, fmt.Errorf("applying migrations failed: %w", err)
+	if err := WaitForDatabase(connectionString); err != nil {
+		return nil, err
 	}
 
 	dbpool, err := pgxpool.New(ctx, connectionString)
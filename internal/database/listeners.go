@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+// Listeners stands in for LISTEN/NOTIFY in the stores that don't have it:
+// the payloads notified are sent to the listeners of the process only. The
+// zero value has no listeners.
+type Listeners struct {
+	mu        sync.Mutex
+	listeners map[int]func(payload string)
+	next      int
+}
+
+// Notify calls every listener with the payload. Listeners must not block.
+func (l *Listeners) Notify(payload string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, notify := range l.listeners {
+		notify(payload)
+	}
+}
+
+// Listen calls notify with the payloads notified until ctx is canceled.
+func (l *Listeners) Listen(ctx context.Context, notify func(payload string)) error {
+	l.mu.Lock()
+	if l.listeners == nil {
+		l.listeners = map[int]func(payload string){}
+	}
+	id := l.next
+	l.next++
+	l.listeners[id] = notify
+	l.mu.Unlock()
+
+	<-ctx.Done()
+	l.mu.Lock()
+	delete(l.listeners, id)
+	l.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS accounts (
+	id SERIAL PRIMARY KEY,
+	username TEXT NOT NULL UNIQUE CHECK (username != ''),
+	password_hash TEXT NOT NULL,
+	user_id INT UNIQUE REFERENCES users (id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	token_hash TEXT PRIMARY KEY,
+	account_id INT REFERENCES accounts (id) ON DELETE CASCADE NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,21 @@
+ALTER TABLE accounts DROP CONSTRAINT accounts_user_id_fkey;
+ALTER TABLE accounts ADD CONSTRAINT accounts_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE accounts DROP COLUMN household_id;
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_user_id_fkey;
+ALTER TABLE tasks DROP CONSTRAINT tasks_chore_id_fkey;
+ALTER TABLE tasks ADD CONSTRAINT tasks_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE tasks ADD CONSTRAINT tasks_chore_id_fkey FOREIGN KEY (chore_id) REFERENCES chores (id) ON DELETE RESTRICT;
+ALTER TABLE tasks DROP COLUMN household_id;
+
+ALTER TABLE users DROP CONSTRAINT users_household_id_id_key;
+ALTER TABLE users DROP CONSTRAINT users_household_id_name_key;
+ALTER TABLE users ADD CONSTRAINT users_name_key UNIQUE (name);
+ALTER TABLE users DROP COLUMN household_id;
+
+ALTER TABLE chores DROP CONSTRAINT chores_household_id_id_key;
+ALTER TABLE chores DROP CONSTRAINT chores_household_id_name_key;
+ALTER TABLE chores ADD CONSTRAINT chores_name_key UNIQUE (name);
+ALTER TABLE chores DROP COLUMN household_id;
+
+DROP TABLE IF EXISTS households;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS households (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL CHECK (name != ''),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Existing data is moved to a default household.
+INSERT INTO households (name)
+SELECT 'Home'
+WHERE EXISTS (SELECT 1 FROM chores) OR EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM accounts);
+
+ALTER TABLE chores ADD COLUMN household_id INT REFERENCES households (id) ON DELETE CASCADE;
+UPDATE chores SET household_id = (SELECT MIN(id) FROM households);
+ALTER TABLE chores ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE chores DROP CONSTRAINT chores_name_key;
+ALTER TABLE chores ADD CONSTRAINT chores_household_id_name_key UNIQUE (household_id, name);
+ALTER TABLE chores ADD CONSTRAINT chores_household_id_id_key UNIQUE (household_id, id);
+
+ALTER TABLE users ADD COLUMN household_id INT REFERENCES households (id) ON DELETE CASCADE;
+UPDATE users SET household_id = (SELECT MIN(id) FROM households);
+ALTER TABLE users ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE users DROP CONSTRAINT users_name_key;
+ALTER TABLE users ADD CONSTRAINT users_household_id_name_key UNIQUE (household_id, name);
+ALTER TABLE users ADD CONSTRAINT users_household_id_id_key UNIQUE (household_id, id);
+
+-- Tasks reference chores and users of their own household only.
+ALTER TABLE tasks ADD COLUMN household_id INT REFERENCES households (id) ON DELETE CASCADE;
+UPDATE tasks SET household_id = (SELECT MIN(id) FROM households);
+ALTER TABLE tasks ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE tasks DROP CONSTRAINT tasks_user_id_fkey;
+ALTER TABLE tasks DROP CONSTRAINT tasks_chore_id_fkey;
+ALTER TABLE tasks ADD CONSTRAINT tasks_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE RESTRICT;
+ALTER TABLE tasks ADD CONSTRAINT tasks_chore_id_fkey FOREIGN KEY (household_id, chore_id) REFERENCES chores (household_id, id) ON DELETE RESTRICT;
+
+ALTER TABLE accounts ADD COLUMN household_id INT REFERENCES households (id) ON DELETE CASCADE;
+UPDATE accounts SET household_id = (SELECT MIN(id) FROM households);
+ALTER TABLE accounts ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE accounts DROP CONSTRAINT accounts_user_id_fkey;
+ALTER TABLE accounts ADD CONSTRAINT accounts_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE SET NULL (user_id);
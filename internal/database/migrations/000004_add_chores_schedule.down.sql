@@ -0,0 +1,4 @@
+ALTER TABLE chores DROP COLUMN schedule_month_day;
+ALTER TABLE chores DROP COLUMN schedule_weekdays;
+ALTER TABLE chores DROP COLUMN schedule_interval_days;
+ALTER TABLE chores DROP COLUMN schedule_kind;
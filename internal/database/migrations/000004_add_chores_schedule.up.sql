@@ -0,0 +1,5 @@
+-- schedule_weekdays is a bitmask of the days of the week, bit 0 being Sunday.
+ALTER TABLE chores ADD COLUMN schedule_kind TEXT NOT NULL DEFAULT 'none' CHECK (schedule_kind IN ('none', 'interval', 'weekly', 'monthly'));
+ALTER TABLE chores ADD COLUMN schedule_interval_days INT NOT NULL DEFAULT 0 CHECK (schedule_interval_days >= 0);
+ALTER TABLE chores ADD COLUMN schedule_weekdays INT NOT NULL DEFAULT 0 CHECK (schedule_weekdays BETWEEN 0 AND 127);
+ALTER TABLE chores ADD COLUMN schedule_month_day INT NOT NULL DEFAULT 0 CHECK (schedule_month_day BETWEEN 0 AND 31);
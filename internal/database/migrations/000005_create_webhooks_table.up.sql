@@ -0,0 +1,27 @@
+-- events lists the resources a webhook is subscribed to: 'task', 'chore' or 'user'.
+CREATE TABLE IF NOT EXISTS webhooks (
+	id SERIAL PRIMARY KEY,
+	household_id INT NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	url TEXT NOT NULL CHECK (url != ''),
+	secret TEXT NOT NULL CHECK (secret != ''),
+	events TEXT[] NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- status is 'pending' until the delivery succeeded or ran out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+	webhook_id INT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+	event TEXT NOT NULL,
+	payload JSONB NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+	attempts INT NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_status_code INT NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
@@ -0,0 +1,5 @@
+-- points is the duration of a task weighted by the difficulty of its chore when
+-- the task was saved. Existing tasks are worth their duration.
+ALTER TABLE chores ADD COLUMN difficulty INT NOT NULL DEFAULT 1 CHECK (difficulty BETWEEN 1 AND 10);
+ALTER TABLE tasks ADD COLUMN points BIGINT NOT NULL DEFAULT 0 CHECK (points >= 0);
+UPDATE tasks SET points = duration_mn;
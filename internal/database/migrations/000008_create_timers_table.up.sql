@@ -0,0 +1,15 @@
+-- A timer measures a chore being done by a user, at most one per user. While it
+-- runs, resumed_at is when it was last started or resumed, and elapsed_seconds
+-- the time measured before. A paused timer has no resumed_at.
+CREATE TABLE IF NOT EXISTS timers (
+	id SERIAL PRIMARY KEY,
+	household_id INT NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	user_id INT NOT NULL,
+	chore_id INT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	resumed_at TIMESTAMPTZ,
+	elapsed_seconds BIGINT NOT NULL DEFAULT 0 CHECK (elapsed_seconds >= 0),
+	CONSTRAINT timers_user_id_key UNIQUE (user_id),
+	CONSTRAINT timers_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE CASCADE,
+	CONSTRAINT timers_chore_id_fkey FOREIGN KEY (household_id, chore_id) REFERENCES chores (household_id, id) ON DELETE CASCADE
+);
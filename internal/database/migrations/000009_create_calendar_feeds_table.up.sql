@@ -0,0 +1,12 @@
+-- A calendar feed lists the tasks of a user, or of the whole household when
+-- user_id is NULL, and the upcoming chores. It is reached with a token of its
+-- own, of which only a hash is kept.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+	id SERIAL PRIMARY KEY,
+	household_id INT NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	user_id INT,
+	token_hash TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT calendar_feeds_token_hash_key UNIQUE (token_hash),
+	CONSTRAINT calendar_feeds_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_household_id_chore_id_idx;
+DROP INDEX IF EXISTS tasks_household_id_user_id_idx;
+DROP INDEX IF EXISTS tasks_household_id_started_at_idx;
@@ -0,0 +1,5 @@
+-- The tasks list is filtered by user, chore and start time. Every query is
+-- scoped to a household, so the indexes start with it.
+CREATE INDEX IF NOT EXISTS tasks_household_id_started_at_idx ON tasks (household_id, started_at);
+CREATE INDEX IF NOT EXISTS tasks_household_id_user_id_idx ON tasks (household_id, user_id, started_at);
+CREATE INDEX IF NOT EXISTS tasks_household_id_chore_id_idx ON tasks (household_id, chore_id, started_at);
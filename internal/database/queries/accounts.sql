@@ -0,0 +1,65 @@
+-- name: CountAccounts :one
+SELECT COUNT(*) FROM accounts;
+
+-- name: ListAccounts :many
+SELECT * FROM accounts
+WHERE household_id = $1
+ORDER BY username;
+
+-- name: GetAccount :one
+SELECT * FROM accounts
+WHERE household_id = $1 AND id = $2;
+
+-- name: GetAccountByUsername :one
+SELECT * FROM accounts
+WHERE username = $1;
+
+-- name: CreateAccount :one
+INSERT INTO accounts (
+    household_id, username, password_hash, user_id
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UpdateAccount :one
+UPDATE accounts SET
+username = $3,
+user_id = $4
+WHERE household_id = $1 AND id = $2
+RETURNING *;
+
+-- name: UpdateAccountPassword :exec
+UPDATE accounts SET
+password_hash = $2
+WHERE id = $1;
+
+-- name: DeleteAccount :exec
+DELETE FROM accounts
+WHERE household_id = $1 AND id = $2;
+
+-- name: CreateSession :one
+INSERT INTO sessions (
+    token_hash, account_id, expires_at
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetSessionAccount :one
+SELECT accounts.*
+FROM sessions
+JOIN accounts ON sessions.account_id = accounts.id
+WHERE sessions.token_hash = $1 AND sessions.expires_at > now();
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE token_hash = $1;
+
+-- name: DeleteAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = $1;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions
+WHERE expires_at <= now();
@@ -0,0 +1,39 @@
+-- name: CountHouseholds :one
+SELECT COUNT(*) FROM households;
+
+-- name: RestoreHousehold :exec
+INSERT INTO households (
+    id, name, created_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: RestoreChore :exec
+INSERT INTO chores (
+    id, household_id, name, description, default_duration_mn, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+);
+
+-- name: RestoreUser :exec
+INSERT INTO users (
+    id, household_id, name, share, archived
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: RestoreTask :exec
+INSERT INTO tasks (
+    id, household_id, user_id, chore_id, started_at, duration_mn, description, points
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: ResetHouseholdsSequence :exec
+SELECT setval(pg_get_serial_sequence('households', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM households;
+
+-- name: ResetChoresSequence :exec
+SELECT setval(pg_get_serial_sequence('chores', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM chores;
+
+-- name: ResetUsersSequence :exec
+SELECT setval(pg_get_serial_sequence('users', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM users;
@@ -0,0 +1,20 @@
+-- name: ListCalendarFeeds :many
+SELECT * FROM calendar_feeds
+WHERE household_id = $1
+ORDER BY id;
+
+-- name: GetCalendarFeed :one
+SELECT * FROM calendar_feeds
+WHERE token_hash = $1;
+
+-- name: CreateCalendarFeed :one
+INSERT INTO calendar_feeds (
+    household_id, user_id, token_hash
+) VALUES (
+    $1, $2, $3
+)
+RETURNING *;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feeds
+WHERE household_id = $1 AND id = $2;
@@ -0,0 +1,2 @@
+-- name: NotifyChange :exec
+SELECT pg_notify('changes', sqlc.arg(payload)::text);
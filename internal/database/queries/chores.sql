@@ -1,27 +1,53 @@
 -- name: ListChores :many
 SELECT * FROM chores
+WHERE household_id = $1
 ORDER BY name;
 
 -- name: GetChore :one
 SELECT * FROM chores
-WHERE id = $1;
+WHERE household_id = $1 AND id = $2;
 
 -- name: CreateChore :one
 INSERT INTO chores (
-    name, description, default_duration_mn
+    household_id, name, description, default_duration_mn, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 RETURNING *;
 
 -- name: UpdateChore :one
 UPDATE chores SET 
-name = $2,
-description = $3,
-default_duration_mn = $4
-WHERE id = $1
+name = $3,
+description = $4,
+default_duration_mn = $5,
+schedule_kind = $6,
+schedule_interval_days = $7,
+schedule_weekdays = $8,
+schedule_month_day = $9,
+difficulty = $10
+WHERE household_id = $1 AND id = $2
 RETURNING *;
 
--- name: DeleteChore :exec
+-- name: SetChoreArchived :one
+UPDATE chores SET
+archived = $3
+WHERE household_id = $1 AND id = $2
+RETURNING *;
+
+-- name: ReassignChoreTasks :many
+UPDATE tasks SET
+chore_id = sqlc.arg(to_chore_id),
+points = duration_mn * sqlc.arg(difficulty)::bigint
+WHERE household_id = sqlc.arg(household_id) AND chore_id = sqlc.arg(from_chore_id)
+RETURNING *;
+
+-- name: DeleteChore :execrows
 DELETE FROM chores
-WHERE id = $1;
\ No newline at end of file
+WHERE household_id = $1 AND id = $2;
+
+
+-- name: ChoresTasksStats :many
+SELECT chore_id, COUNT(*) AS tasks_count, MIN(started_at)::timestamptz AS first_started_at, MAX(started_at)::timestamptz AS last_started_at
+FROM tasks
+WHERE household_id = $1
+GROUP BY chore_id;
@@ -0,0 +1,21 @@
+-- name: ListHouseholds :many
+SELECT * FROM households
+ORDER BY id;
+
+-- name: GetHousehold :one
+SELECT * FROM households
+WHERE id = $1;
+
+-- name: CreateHousehold :one
+INSERT INTO households (
+    name
+) VALUES (
+    $1
+)
+RETURNING *;
+
+-- name: UpdateHousehold :one
+UPDATE households SET
+name = $2
+WHERE id = $1
+RETURNING *;
@@ -1,31 +1,33 @@
 -- name: ListTasks :many
 SELECT * FROM tasks
+WHERE household_id = $1
 ORDER BY started_at;
 
 -- name: GetTask :one
 SELECT * FROM tasks
-WHERE id = $1;
+WHERE household_id = $1 AND id = $2;
 
 -- name: CreateTask :one
 INSERT INTO tasks (
-    user_id, chore_id, started_at, duration_mn, description
+    household_id, user_id, chore_id, started_at, duration_mn, description, points
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING *;
 
--- name: DeleteTask :exec
+-- name: DeleteTask :execrows
 DELETE FROM tasks
-WHERE id = $1;
+WHERE household_id = $1 AND id = $2;
 
 -- name: UpdateTask :one
 UPDATE tasks SET 
-user_id = $2,
-chore_id = $3,
-started_at = $4,
-duration_mn = $5,
-description = $6
-WHERE id = $1
+user_id = $3,
+chore_id = $4,
+started_at = $5,
+duration_mn = $6,
+description = $7,
+points = $8
+WHERE household_id = $1 AND id = $2
 RETURNING *;
 
 -- name: GetUserTasks :many
@@ -33,14 +35,14 @@ SELECT sqlc.embed(tasks), sqlc.embed(chores)
 FROM tasks
 JOIN chores ON tasks.chore_id = chores.id
 JOIN users ON tasks.user_id = users.id
-WHERE users.id = $1
+WHERE tasks.household_id = $1 AND users.id = $2
 ORDER BY tasks.started_at DESC;
 
 -- name: GetChoreTasks :many
 SELECT sqlc.embed(tasks), sqlc.embed(users)
 FROM tasks
 JOIN users ON tasks.user_id = users.id
-WHERE tasks.chore_id = $1
+WHERE tasks.household_id = $1 AND tasks.chore_id = $2
 ORDER BY tasks.started_at DESC;
 
 -- name: ListUsersTasks :many
@@ -48,12 +50,54 @@ SELECT sqlc.embed(tasks), sqlc.embed(chores), sqlc.embed(users)
 FROM tasks
 JOIN chores ON tasks.chore_id = chores.id
 JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = $1
 ORDER BY tasks.started_at DESC;
 
+-- name: ListUsersTasksBetween :many
+SELECT sqlc.embed(tasks), sqlc.embed(chores), sqlc.embed(users)
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = sqlc.arg(household_id)
+    AND (sqlc.arg(user_id)::int = 0 OR tasks.user_id = sqlc.arg(user_id))
+    AND tasks.started_at >= sqlc.arg(not_before) AND tasks.started_at <= sqlc.arg(not_after)
+ORDER BY tasks.started_at DESC;
+
+-- name: SearchTasks :many
+-- Tasks are sorted on a key, the duration, the points or 0 to sort on the start
+-- time only, then on their start time and ID. The page after a task is
+-- selected with the values of its sort columns.
+SELECT sqlc.embed(tasks), sqlc.embed(chores), sqlc.embed(users)
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = sqlc.arg(household_id)
+    AND (sqlc.arg(user_id)::int = 0 OR tasks.user_id = sqlc.arg(user_id))
+    AND (sqlc.arg(chore_id)::int = 0 OR tasks.chore_id = sqlc.arg(chore_id))
+    AND tasks.started_at >= sqlc.arg(not_before) AND tasks.started_at < sqlc.arg(not_after)
+    AND strpos(lower(tasks.description), lower(sqlc.arg(search)::text)) > 0
+    AND (NOT sqlc.arg(after)::boolean
+        OR (sqlc.arg(descending)::boolean AND (CASE sqlc.arg(sort)::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END, tasks.started_at, tasks.id) < (sqlc.arg(after_key)::bigint, sqlc.arg(after_started_at)::timestamptz, sqlc.arg(after_id)::uuid))
+        OR (NOT sqlc.arg(descending)::boolean AND (CASE sqlc.arg(sort)::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END, tasks.started_at, tasks.id) > (sqlc.arg(after_key)::bigint, sqlc.arg(after_started_at)::timestamptz, sqlc.arg(after_id)::uuid)))
+ORDER BY
+    CASE WHEN sqlc.arg(descending)::boolean THEN CASE sqlc.arg(sort)::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END END DESC,
+    CASE WHEN sqlc.arg(descending)::boolean THEN tasks.started_at END DESC,
+    CASE WHEN sqlc.arg(descending)::boolean THEN tasks.id END DESC,
+    CASE sqlc.arg(sort)::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END, tasks.started_at, tasks.id
+LIMIT sqlc.arg(max_tasks);
+
 -- name: TasksReport :many
-SELECT sqlc.embed(users), sqlc.embed(chores), SUM(duration_mn)
+SELECT sqlc.embed(users), sqlc.embed(chores), SUM(tasks.duration_mn)::bigint AS minutes, COUNT(*) AS tasks_count, SUM(tasks.points)::bigint AS points
 FROM tasks
 JOIN chores ON tasks.chore_id = chores.id
 JOIN users ON tasks.user_id = users.id
-WHERE tasks.started_at > sqlc.arg(not_before) AND tasks.started_at < sqlc.arg(not_after)
+WHERE tasks.household_id = sqlc.arg(household_id) AND tasks.started_at > sqlc.arg(not_before) AND tasks.started_at < sqlc.arg(not_after)
 GROUP BY chores.id, users.id;
+
+-- name: TasksTimeline :many
+SELECT sqlc.embed(users), date_trunc(sqlc.arg(unit)::text, tasks.started_at, sqlc.arg(time_zone)::text)::timestamptz AS period, SUM(tasks.duration_mn)::bigint AS minutes, COUNT(*) AS tasks_count, SUM(tasks.points)::bigint AS points
+FROM tasks
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = sqlc.arg(household_id) AND tasks.started_at > sqlc.arg(not_before) AND tasks.started_at < sqlc.arg(not_after)
+GROUP BY users.id, period
+ORDER BY period, users.id;
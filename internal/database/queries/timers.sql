@@ -0,0 +1,35 @@
+-- name: ListTimers :many
+SELECT sqlc.embed(timers), sqlc.embed(chores), sqlc.embed(users)
+FROM timers
+JOIN chores ON timers.chore_id = chores.id
+JOIN users ON timers.user_id = users.id
+WHERE timers.household_id = $1
+ORDER BY timers.started_at;
+
+-- name: GetTimer :one
+SELECT * FROM timers
+WHERE household_id = $1 AND id = $2;
+
+-- name: GetTimerForUpdate :one
+SELECT * FROM timers
+WHERE household_id = $1 AND id = $2
+FOR UPDATE;
+
+-- name: CreateTimer :one
+INSERT INTO timers (
+    household_id, user_id, chore_id, started_at, resumed_at
+) VALUES (
+    sqlc.arg(household_id), sqlc.arg(user_id), sqlc.arg(chore_id), sqlc.arg(started_at), sqlc.arg(started_at)
+)
+RETURNING *;
+
+-- name: UpdateTimer :one
+UPDATE timers SET
+resumed_at = $3,
+elapsed_seconds = $4
+WHERE household_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteTimer :execrows
+DELETE FROM timers
+WHERE household_id = $1 AND id = $2;
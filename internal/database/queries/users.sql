@@ -1,25 +1,39 @@
 -- name: ListUsers :many
 SELECT * FROM users
+WHERE household_id = $1
 ORDER BY name;
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE id = $1;
+WHERE household_id = $1 AND id = $2;
 
 -- name: CreateUser :one
 INSERT INTO users (
-    name
+    household_id, name, share
 ) VALUES (
-    $1
+    $1, $2, $3
 )
 RETURNING *;
 
--- name: DeleteUser :exec
+-- name: SetUserArchived :one
+UPDATE users SET
+archived = $3
+WHERE household_id = $1 AND id = $2
+RETURNING *;
+
+-- name: ReassignUserTasks :many
+UPDATE tasks SET
+user_id = sqlc.arg(to_user_id)
+WHERE household_id = sqlc.arg(household_id) AND user_id = sqlc.arg(from_user_id)
+RETURNING *;
+
+-- name: DeleteUser :execrows
 DELETE FROM users
-WHERE id = $1;
+WHERE household_id = $1 AND id = $2;
 
 -- name: UpdateUser :one
 UPDATE users SET 
-name = $2
-WHERE id = $1
+name = $3,
+share = $4
+WHERE household_id = $1 AND id = $2
 RETURNING *;
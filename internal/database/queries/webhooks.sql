@@ -0,0 +1,73 @@
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+WHERE household_id = $1
+ORDER BY id;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE household_id = $1 AND id = $2;
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    household_id, url, secret, events
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks SET
+url = $3,
+secret = $4,
+events = $5
+WHERE household_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE household_id = $1 AND id = $2;
+
+-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+SELECT webhooks.id, sqlc.arg(event)::text, sqlc.arg(payload)::jsonb
+FROM webhooks
+WHERE webhooks.household_id = sqlc.arg(household_id) AND sqlc.arg(resource)::text = ANY(webhooks.events);
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries SET
+next_attempt_at = sqlc.arg(lease_until)
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id AND webhook_deliveries.id IN (
+    SELECT pending.id FROM webhook_deliveries AS pending
+    WHERE pending.status = 'pending' AND pending.next_attempt_at <= sqlc.arg(now)
+    ORDER BY pending.next_attempt_at
+    LIMIT sqlc.arg(max_deliveries)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING webhook_deliveries.id, webhook_deliveries.event, webhook_deliveries.payload, webhook_deliveries.attempts, webhooks.url, webhooks.secret;
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries SET
+status = $2,
+attempts = attempts + 1,
+next_attempt_at = $3,
+last_status_code = $4,
+last_error = $5,
+delivered_at = $6
+WHERE id = $1;
+
+-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries SET
+status = 'pending',
+attempts = 0,
+last_error = '',
+next_attempt_at = now()
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id AND webhooks.household_id = $1
+    AND webhook_deliveries.webhook_id = $2 AND webhook_deliveries.id = $3;
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+)
+
+// sqliteDSN enables the foreign keys, waits for the locks of the other
+// connections instead of failing and starts the transactions as writers, so
+// that they never fail to upgrade their lock. With the write-ahead log, reads
+// aren't blocked by a write.
+func sqliteDSN(path string) string {
+	return "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+}
+
+// OpenSQLite opens the SQLite database file, created when missing. The
+// migrations are not applied.
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the database: %w", err)
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to open the database: %w", err)
+	}
+	return db, nil
+}
+
+// NewSQLiteMigrator opens a dedicated connection to the SQLite database. It
+// must be closed once done.
+func NewSQLiteMigrator(path string) (*Migrator, error) {
+	db, err := OpenSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlite_driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	source_driver, err := migrationSource("sqlite")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source_driver, "sqlite", sqlite_driver)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create migration instance: %w", err)
+	}
+	return &Migrator{m: m, db: db}, nil
+}
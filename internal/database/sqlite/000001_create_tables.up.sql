@@ -0,0 +1,143 @@
+-- The schema of the PostgreSQL migrations for SQLite. Constraints get the names
+-- PostgreSQL gives them. SQLite doesn't name the foreign key that failed, so the
+-- ones of tasks and accounts are also checked by triggers raising their name.
+-- Timestamps are UTC RFC 3339 texts with microseconds, sorted like the times.
+CREATE TABLE IF NOT EXISTS households (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL CONSTRAINT households_name_check CHECK (name != ''),
+	created_at TEXT NOT NULL
+);
+
+-- schedule_weekdays is a bitmask of the days of the week, bit 0 being Sunday.
+CREATE TABLE IF NOT EXISTS chores (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL CONSTRAINT chores_name_check CHECK (name != ''),
+	description TEXT NOT NULL,
+	default_duration_mn INTEGER NOT NULL,
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	schedule_kind TEXT NOT NULL DEFAULT 'none' CONSTRAINT chores_schedule_kind_check CHECK (schedule_kind IN ('none', 'interval', 'weekly', 'monthly')),
+	schedule_interval_days INTEGER NOT NULL DEFAULT 0 CONSTRAINT chores_schedule_interval_days_check CHECK (schedule_interval_days >= 0),
+	schedule_weekdays INTEGER NOT NULL DEFAULT 0 CONSTRAINT chores_schedule_weekdays_check CHECK (schedule_weekdays BETWEEN 0 AND 127),
+	schedule_month_day INTEGER NOT NULL DEFAULT 0 CONSTRAINT chores_schedule_month_day_check CHECK (schedule_month_day BETWEEN 0 AND 31),
+	CONSTRAINT chores_household_id_name_key UNIQUE (household_id, name),
+	CONSTRAINT chores_household_id_id_key UNIQUE (household_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL CONSTRAINT users_name_check CHECK (name != ''),
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	CONSTRAINT users_household_id_name_key UNIQUE (household_id, name),
+	CONSTRAINT users_household_id_id_key UNIQUE (household_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+	id TEXT PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	chore_id INTEGER NOT NULL,
+	started_at TEXT NOT NULL,
+	duration_mn INTEGER NOT NULL,
+	description TEXT NOT NULL,
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	CONSTRAINT tasks_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE RESTRICT,
+	CONSTRAINT tasks_chore_id_fkey FOREIGN KEY (household_id, chore_id) REFERENCES chores (household_id, id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS tasks_household_id_started_at_idx ON tasks (household_id, started_at);
+
+-- Unlike PostgreSQL, SQLite can't only set user_id to NULL with a foreign key
+-- on (household_id, user_id): the household is checked by triggers instead.
+CREATE TABLE IF NOT EXISTS accounts (
+	id INTEGER PRIMARY KEY,
+	username TEXT NOT NULL CONSTRAINT accounts_username_check CHECK (username != ''),
+	password_hash TEXT NOT NULL,
+	user_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+	created_at TEXT NOT NULL,
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	CONSTRAINT accounts_username_key UNIQUE (username),
+	CONSTRAINT accounts_user_id_key UNIQUE (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	token_hash TEXT PRIMARY KEY,
+	account_id INTEGER NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	created_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL
+);
+
+-- events is a JSON array of the resources a webhook is subscribed to: 'task',
+-- 'chore' or 'user'.
+CREATE TABLE IF NOT EXISTS webhooks (
+	id INTEGER PRIMARY KEY,
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	url TEXT NOT NULL CONSTRAINT webhooks_url_check CHECK (url != ''),
+	secret TEXT NOT NULL CONSTRAINT webhooks_secret_check CHECK (secret != ''),
+	events TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+-- status is 'pending' until the delivery succeeded or ran out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id TEXT PRIMARY KEY,
+	webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+	event TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending' CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'succeeded', 'failed')),
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TEXT NOT NULL,
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	delivered_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
+
+CREATE TRIGGER IF NOT EXISTS tasks_user_id_fkey_insert BEFORE INSERT ON tasks
+WHEN NOT EXISTS (SELECT 1 FROM users WHERE household_id = NEW.household_id AND id = NEW.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: tasks_user_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_user_id_fkey_update BEFORE UPDATE OF household_id, user_id ON tasks
+WHEN NOT EXISTS (SELECT 1 FROM users WHERE household_id = NEW.household_id AND id = NEW.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: tasks_user_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_user_id_fkey_delete BEFORE DELETE ON users
+WHEN EXISTS (SELECT 1 FROM tasks WHERE household_id = OLD.household_id AND user_id = OLD.id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: tasks_user_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_chore_id_fkey_insert BEFORE INSERT ON tasks
+WHEN NOT EXISTS (SELECT 1 FROM chores WHERE household_id = NEW.household_id AND id = NEW.chore_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: tasks_chore_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_chore_id_fkey_update BEFORE UPDATE OF household_id, chore_id ON tasks
+WHEN NOT EXISTS (SELECT 1 FROM chores WHERE household_id = NEW.household_id AND id = NEW.chore_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: tasks_chore_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_chore_id_fkey_delete BEFORE DELETE ON chores
+WHEN EXISTS (SELECT 1 FROM tasks WHERE household_id = OLD.household_id AND chore_id = OLD.id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: tasks_chore_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS accounts_user_id_fkey_insert BEFORE INSERT ON accounts
+WHEN NEW.user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE household_id = NEW.household_id AND id = NEW.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: accounts_user_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS accounts_user_id_fkey_update BEFORE UPDATE OF household_id, user_id ON accounts
+WHEN NEW.user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE household_id = NEW.household_id AND id = NEW.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: accounts_user_id_fkey');
+END;
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN points;
+ALTER TABLE chores DROP COLUMN difficulty;
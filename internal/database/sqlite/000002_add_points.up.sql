@@ -0,0 +1,5 @@
+-- points is the duration of a task weighted by the difficulty of its chore when
+-- the task was saved. Existing tasks are worth their duration.
+ALTER TABLE chores ADD COLUMN difficulty INTEGER NOT NULL DEFAULT 1 CONSTRAINT chores_difficulty_check CHECK (difficulty BETWEEN 1 AND 10);
+ALTER TABLE tasks ADD COLUMN points INTEGER NOT NULL DEFAULT 0 CONSTRAINT tasks_points_check CHECK (points >= 0);
+UPDATE tasks SET points = duration_mn;
@@ -0,0 +1,3 @@
+-- share is the part of the chores a user is expected to do, relative to the
+-- shares of the other users of the household: equal shares split them evenly.
+ALTER TABLE users ADD COLUMN share INTEGER NOT NULL DEFAULT 1 CONSTRAINT users_share_check CHECK (share BETWEEN 1 AND 100);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS timers_chore_id_fkey_insert;
+DROP TRIGGER IF EXISTS timers_user_id_fkey_insert;
+DROP TABLE IF EXISTS timers;
@@ -0,0 +1,28 @@
+-- A timer measures a chore being done by a user, at most one per user. While it
+-- runs, resumed_at is when it was last started or resumed, and elapsed_seconds
+-- the time measured before. A paused timer has no resumed_at.
+CREATE TABLE IF NOT EXISTS timers (
+	id INTEGER PRIMARY KEY,
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	chore_id INTEGER NOT NULL,
+	started_at TEXT NOT NULL,
+	resumed_at TEXT,
+	elapsed_seconds INTEGER NOT NULL DEFAULT 0 CONSTRAINT timers_elapsed_seconds_check CHECK (elapsed_seconds >= 0),
+	CONSTRAINT timers_user_id_key UNIQUE (user_id),
+	CONSTRAINT timers_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE CASCADE,
+	CONSTRAINT timers_chore_id_fkey FOREIGN KEY (household_id, chore_id) REFERENCES chores (household_id, id) ON DELETE CASCADE
+);
+
+-- The user and the chore of a timer never change, only its creation checks them.
+CREATE TRIGGER IF NOT EXISTS timers_user_id_fkey_insert BEFORE INSERT ON timers
+WHEN NOT EXISTS (SELECT 1 FROM users WHERE household_id = NEW.household_id AND id = NEW.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: timers_user_id_fkey');
+END;
+
+CREATE TRIGGER IF NOT EXISTS timers_chore_id_fkey_insert BEFORE INSERT ON timers
+WHEN NOT EXISTS (SELECT 1 FROM chores WHERE household_id = NEW.household_id AND id = NEW.chore_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: timers_chore_id_fkey');
+END;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS calendar_feeds_user_id_fkey_insert;
+DROP TABLE IF EXISTS calendar_feeds;
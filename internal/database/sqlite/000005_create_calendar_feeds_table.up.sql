@@ -0,0 +1,19 @@
+-- A calendar feed lists the tasks of a user, or of the whole household when
+-- user_id is NULL, and the upcoming chores. It is reached with a token of its
+-- own, of which only a hash is kept.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+	id INTEGER PRIMARY KEY,
+	household_id INTEGER NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+	user_id INTEGER,
+	token_hash TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	CONSTRAINT calendar_feeds_token_hash_key UNIQUE (token_hash),
+	CONSTRAINT calendar_feeds_user_id_fkey FOREIGN KEY (household_id, user_id) REFERENCES users (household_id, id) ON DELETE CASCADE
+);
+
+-- The user of a feed never changes, only its creation checks it.
+CREATE TRIGGER IF NOT EXISTS calendar_feeds_user_id_fkey_insert BEFORE INSERT ON calendar_feeds
+WHEN NEW.user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users WHERE household_id = NEW.household_id AND id = NEW.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed: calendar_feeds_user_id_fkey');
+END;
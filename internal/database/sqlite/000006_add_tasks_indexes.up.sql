@@ -0,0 +1,3 @@
+-- tasks_household_id_started_at_idx was created with the tables.
+CREATE INDEX IF NOT EXISTS tasks_household_id_user_id_idx ON tasks (household_id, user_id, started_at);
+CREATE INDEX IF NOT EXISTS tasks_household_id_chore_id_idx ON tasks (household_id, chore_id, started_at);
@@ -0,0 +1,4 @@
+-- Archived chores and users are hidden from the lists and the forms but keep
+-- their tasks, so that deleting them isn't the only way to retire them.
+ALTER TABLE chores ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
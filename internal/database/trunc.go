@@ -0,0 +1,24 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateTrunc truncates the time to the start of its day, week or month in the
+// location, like the date_trunc function of PostgreSQL does for the stores
+// that don't have it. Weeks start on Monday.
+func DateTrunc(unit string, t time.Time, location *time.Location) (time.Time, error) {
+	t = t.In(location)
+	year, month, day := t.Date()
+	switch unit {
+	case "day":
+	case "week":
+		day -= (int(t.Weekday()) + 6) % 7
+	case "month":
+		day = 1
+	default:
+		return time.Time{}, fmt.Errorf("unit %q not supported", unit)
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, location), nil
+}
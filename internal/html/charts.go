@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/go-echarts/go-echarts/v2/charts"
@@ -111,6 +112,19 @@ func generateBarItems(chore string, report repository.Report) []opts.BarData {
 	return items
 }
 
+// MetricLabel returns the name shown for the values of a report metric.
+func MetricLabel(metric repository.ReportMetric) string {
+	switch metric {
+	case repository.MetricTasks:
+		return "Tasks"
+	case repository.MetricPoints:
+		return "Points"
+	default:
+		return "Minutes"
+	}
+}
+
+// CreateBarChart stacks the metric of the report by user, one series per chore.
 func CreateBarChart(report repository.Report) *charts.Bar {
 	bar := charts.NewBar()
 	bar.Renderer = NewSnippetRenderer(bar, bar.Validate)
@@ -121,6 +135,9 @@ func CreateBarChart(report repository.Report) *charts.Bar {
 		charts.WithLegendOpts(
 			opts.Legend{Type: "scroll", Show: opts.Bool(true), Bottom: "bottom"},
 		),
+		charts.WithYAxisOpts(
+			opts.YAxis{Name: MetricLabel(report.Metric)},
+		),
 	)
 	bar.SetXAxis(report.Users)
 	for _, chore := range report.Chores {
@@ -132,6 +149,153 @@ func CreateBarChart(report repository.Report) *charts.Bar {
 	return bar
 }
 
+// periodLabel names a period of a timeline on the axis of its chart.
+func periodLabel(period time.Time, bucket repository.TimelineBucket) string {
+	if bucket == repository.BucketMonth {
+		return period.Format("01/2006")
+	}
+	return period.Format("02/01/2006")
+}
+
+// BucketLabel returns the name shown for the periods of a timeline.
+func BucketLabel(bucket repository.TimelineBucket) string {
+	switch bucket {
+	case repository.BucketDay:
+		return "Day"
+	case repository.BucketMonth:
+		return "Month"
+	default:
+		return "Week"
+	}
+}
+
+// CreateLineChart stacks the metric of the timeline by period, one area per
+// user, to show its trend over time.
+func CreateLineChart(timeline repository.Timeline) *charts.Line {
+	line := charts.NewLine()
+	line.Renderer = NewSnippetRenderer(line, line.Validate)
+	line.SetGlobalOptions(
+		charts.WithInitializationOpts(
+			opts.Initialization{AssetsHost: "/static/"},
+		),
+		charts.WithLegendOpts(
+			opts.Legend{Type: "scroll", Show: opts.Bool(true), Bottom: "bottom"},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"},
+		),
+		charts.WithYAxisOpts(
+			opts.YAxis{Name: MetricLabel(timeline.Metric)},
+		),
+	)
+	periods := make([]string, len(timeline.Periods))
+	for index, period := range timeline.Periods {
+		periods[index] = periodLabel(period, timeline.Bucket)
+	}
+	line.SetXAxis(periods)
+	for _, user := range timeline.Users {
+		items := make([]opts.LineData, 0, len(timeline.Periods))
+		for _, value := range timeline.Series[user] {
+			items = append(items, opts.LineData{Value: value})
+		}
+		line.AddSeries(user, items)
+	}
+	line.SetSeriesOptions(
+		charts.WithLineChartOpts(opts.LineChart{Stack: "stackA"}),
+		charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: 0.4}),
+	)
+	return line
+}
+
+// CreatePeriodBarChart stacks the metric of the timeline by period, one series
+// per user.
+func CreatePeriodBarChart(timeline repository.Timeline) *charts.Bar {
+	bar := charts.NewBar()
+	bar.Renderer = NewSnippetRenderer(bar, bar.Validate)
+	bar.SetGlobalOptions(
+		charts.WithInitializationOpts(
+			opts.Initialization{AssetsHost: "/static/"},
+		),
+		charts.WithLegendOpts(
+			opts.Legend{Type: "scroll", Show: opts.Bool(true), Bottom: "bottom"},
+		),
+		charts.WithYAxisOpts(
+			opts.YAxis{Name: MetricLabel(timeline.Metric)},
+		),
+	)
+	periods := make([]string, len(timeline.Periods))
+	for index, period := range timeline.Periods {
+		periods[index] = periodLabel(period, timeline.Bucket)
+	}
+	bar.SetXAxis(periods)
+	for _, user := range timeline.Users {
+		items := make([]opts.BarData, 0, len(timeline.Periods))
+		for _, value := range timeline.Series[user] {
+			items = append(items, opts.BarData{Value: value})
+		}
+		bar.AddSeries(user, items)
+	}
+	bar.SetSeriesOptions(charts.WithBarChartOpts(opts.BarChart{
+		Stack: "stackA",
+	}))
+	return bar
+}
+
+// CreatePieChart shows the share of each chore in a donut.
+func CreatePieChart(report []repository.SingleChoreReport) *charts.Pie {
+	pie := charts.NewPie()
+	pie.Renderer = NewSnippetRenderer(pie, pie.Validate)
+	pie.SetGlobalOptions(
+		charts.WithInitializationOpts(
+			opts.Initialization{AssetsHost: "/static/"},
+		),
+		charts.WithLegendOpts(
+			opts.Legend{Type: "scroll", Show: opts.Bool(true), Bottom: "bottom"},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{Show: opts.Bool(true), Formatter: "{b}: {c} mn ({d}%)"},
+		),
+	)
+	items := make([]opts.PieData, 0, len(report))
+	for _, chore := range report {
+		items = append(items, opts.PieData{Name: chore.Chore, Value: chore.Sum})
+	}
+	pie.AddSeries("Minutes", items, charts.WithPieChartOpts(opts.PieChart{Radius: []string{"40%", "70%"}}))
+	return pie
+}
+
+// CreateActivityCalendar shows the minutes spent each day of the year up to now,
+// as returned by repository.DailyActivity in the time zone of now.
+func CreateActivityCalendar(activity map[string]int64, now time.Time) *charts.HeatMap {
+	heatmap := charts.NewHeatMap()
+	heatmap.Renderer = NewSnippetRenderer(heatmap, heatmap.Validate)
+	first := now.AddDate(-1, 0, 1)
+	var highest int64
+	items := make([]opts.HeatMapData, 0, len(activity))
+	for day := first; !day.After(now); day = day.AddDate(0, 0, 1) {
+		minutes, ok := activity[day.Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		highest = max(highest, minutes)
+		items = append(items, opts.HeatMapData{Value: []any{day.Format(time.DateOnly), minutes}})
+	}
+	heatmap.SetGlobalOptions(
+		charts.WithInitializationOpts(
+			opts.Initialization{AssetsHost: "/static/"},
+		),
+		charts.WithTooltipOpts(
+			opts.Tooltip{Show: opts.Bool(true)},
+		),
+		charts.WithVisualMapOpts(
+			opts.VisualMap{Min: 0, Max: float32(max(highest, 1)), Orient: "horizontal", Left: "center", Bottom: "bottom", Calculable: opts.Bool(false)},
+		),
+	)
+	heatmap.AddCalendar(&opts.Calendar{Range: []string{first.Format(time.DateOnly), now.Format(time.DateOnly)}, Top: "30", Left: "40", Right: "20"})
+	heatmap.AddSeries("Minutes", items, charts.WithCoordinateSystem("calendar"))
+	return heatmap
+}
+
 // The charts all have a `Render(w io.Writer) error` method on them.
 // That method is very similar to templ's Render method.
 type Renderable interface {
@@ -0,0 +1,129 @@
+// Package ical writes iCalendar feeds (RFC 5545), with the part of the format
+// calendar applications need to show events.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// lineLength is the length in octets above which lines are folded.
+	lineLength = 75
+	dateFormat = "20060102"
+	timeFormat = "20060102T150405Z"
+)
+
+// Event is a VEVENT of a calendar.
+type Event struct {
+	// UID identifies the event across the updates of the calendar.
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	// End is excluded: the day after the last one for an all-day event.
+	End time.Time
+	// AllDay events are dated by the day of Start and End in their location,
+	// without time.
+	AllDay bool
+}
+
+// Calendar is a VCALENDAR published for subscription.
+type Calendar struct {
+	// Name is the name suggested to the applications subscribing.
+	Name string
+	// TimeZone is the IANA name of the time zone the calendar is meant to be
+	// shown in. The times of the events are written in UTC.
+	TimeZone string
+	// Refresh is how often the applications should fetch the calendar again.
+	Refresh time.Duration
+	// Stamp is when the calendar was generated.
+	Stamp  time.Time
+	Events []Event
+}
+
+// escape escapes a TEXT value.
+var escape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "")
+
+// writer writes content lines, folded and ended with CRLF. The buffered
+// writer keeps the first error, returned when flushing.
+type writer struct {
+	w *bufio.Writer
+}
+
+func (w writer) line(name string, value string) {
+	line := name + ":" + value
+	for len(line) > lineLength {
+		cut := lineLength
+		// Folding must not split a UTF-8 sequence.
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	w.w.WriteString(line + "\r\n")
+}
+
+func (w writer) text(name string, value string) {
+	w.line(name, escape.Replace(value))
+}
+
+func (w writer) time(name string, t time.Time, allDay bool) {
+	if allDay {
+		w.line(name+";VALUE=DATE", t.Format(dateFormat))
+		return
+	}
+	w.line(name, t.UTC().Format(timeFormat))
+}
+
+// duration formats d as a DURATION value, to the minute.
+func duration(d time.Duration) string {
+	minutes := int64(d.Round(time.Minute) / time.Minute)
+	value := "PT"
+	if minutes >= 60 {
+		value += strconv.FormatInt(minutes/60, 10) + "H"
+	}
+	if minutes%60 != 0 || minutes < 60 {
+		value += strconv.FormatInt(minutes%60, 10) + "M"
+	}
+	return value
+}
+
+// Encode writes the calendar to out.
+func (c Calendar) Encode(out io.Writer) error {
+	w := writer{w: bufio.NewWriter(out)}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//whodidthechores//whodidthechores//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", c.Name)
+	if c.TimeZone != "" {
+		w.text("X-WR-TIMEZONE", c.TimeZone)
+	}
+	if c.Refresh > 0 {
+		refresh := duration(c.Refresh)
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", refresh)
+		w.line("X-PUBLISHED-TTL", refresh)
+	}
+	for _, event := range c.Events {
+		w.line("BEGIN", "VEVENT")
+		w.text("UID", event.UID)
+		w.time("DTSTAMP", c.Stamp, false)
+		w.time("DTSTART", event.Start, event.AllDay)
+		w.time("DTEND", event.End, event.AllDay)
+		w.text("SUMMARY", event.Summary)
+		if event.Description != "" {
+			w.text("DESCRIPTION", event.Description)
+		}
+		w.line("TRANSP", "TRANSPARENT")
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.w.Flush()
+}
@@ -0,0 +1,63 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	calendar := Calendar{
+		Name:     "Home chores",
+		TimeZone: "Europe/Paris",
+		Refresh:  90 * time.Minute,
+		Stamp:    time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{
+				UID:         "task-1@whodidthechores",
+				Summary:     "Dishes, glasses; pans",
+				Description: "First line\nsecond line with a \\",
+				Start:       time.Date(2024, 5, 10, 9, 30, 0, 0, paris),
+				End:         time.Date(2024, 5, 10, 10, 0, 0, 0, paris),
+			},
+			{
+				UID:     "chore-2-2024-05-11@whodidthechores",
+				Summary: "Due: " + strings.Repeat("é", 40),
+				Start:   time.Date(2024, 5, 11, 0, 0, 0, 0, paris),
+				End:     time.Date(2024, 5, 12, 0, 0, 0, 0, paris),
+				AllDay:  true,
+			},
+		},
+	}
+	var out strings.Builder
+	require.NoError(t, calendar.Encode(&out))
+	content := out.String()
+
+	assert.True(t, strings.HasPrefix(content, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(content, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, content, "X-WR-TIMEZONE:Europe/Paris\r\n")
+	assert.Contains(t, content, "REFRESH-INTERVAL;VALUE=DURATION:PT1H30M\r\n")
+	assert.Contains(t, content, "DTSTAMP:20240510T120000Z\r\n")
+	assert.Contains(t, content, "DTSTART:20240510T073000Z\r\nDTEND:20240510T080000Z\r\n")
+	assert.Contains(t, content, `SUMMARY:Dishes\, glasses\; pans`+"\r\n")
+	assert.Contains(t, content, `DESCRIPTION:First line\nsecond line with a \\`+"\r\n")
+	assert.Contains(t, content, "DTSTART;VALUE=DATE:20240511\r\nDTEND;VALUE=DATE:20240512\r\n")
+
+	var summary string
+	for _, line := range strings.Split(content, "\r\n") {
+		assert.LessOrEqual(t, len(line), lineLength)
+		if strings.HasPrefix(line, "SUMMARY:Due") {
+			summary = line
+		} else if summary != "" && strings.HasPrefix(line, " ") {
+			summary += line[1:]
+		} else if summary != "" {
+			break
+		}
+	}
+	assert.Equal(t, "SUMMARY:Due: "+strings.Repeat("é", 40), summary, "folding keeps the UTF-8 sequences whole")
+}
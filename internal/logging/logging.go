@@ -0,0 +1,59 @@
+// Package logging configures the application logger and carries the ID of
+// the request being served in its context, added to every log written with
+// it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/mqufflc/whodidthechores/internal/config"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = 0
+
+// WithRequestID returns a context carrying the ID of a request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the request carried by the context, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok
+}
+
+// New returns a logger writing to w with the configured level and format.
+func New(w io.Writer, conf config.LogConfig) *slog.Logger {
+	options := &slog.HandlerOptions{Level: conf.SlogLevel()}
+	var handler slog.Handler
+	if conf.Format == "json" {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// contextHandler adds the request ID of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID, ok := RequestID(ctx); ok {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,221 @@
+// Package metrics exposes the Prometheus metrics of the server: HTTP
+// requests, database pool statistics and the chores done in each household.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "whodidthechores"
+
+// collectTimeout bounds the database queries of a scrape.
+const collectTimeout = 10 * time.Second
+
+// NewRegistry returns a registry with the Go runtime and process metrics.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler serves the metrics of the registry.
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// HTTPMetrics counts and times the requests of each route.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewHTTPMetrics(registerer prometheus.Registerer) *HTTPMetrics {
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by route pattern and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+	}
+	registerer.MustRegister(m.requests, m.duration)
+	return m
+}
+
+// Instrument records the requests handled by the handler of a route pattern.
+// A nil HTTPMetrics returns the handler as is.
+func (m *HTTPMetrics) Instrument(pattern string, handler http.Handler) http.Handler {
+	if m == nil {
+		return handler
+	}
+	labels := prometheus.Labels{"route": pattern}
+	return promhttp.InstrumentHandlerDuration(m.duration.MustCurryWith(labels),
+		promhttp.InstrumentHandlerCounter(m.requests.MustCurryWith(labels), handler))
+}
+
+// poolCollector reports the statistics of a database connection pool.
+type poolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquiredConns   *prometheus.Desc
+	idleConns       *prometheus.Desc
+	totalConns      *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+// NewPoolCollector reports the statistics returned by stat, usually the Stat
+// method of a pgxpool.Pool.
+func NewPoolCollector(stat func() *pgxpool.Stat) prometheus.Collector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		stat:            stat,
+		acquiredConns:   desc("acquired_connections", "Number of connections currently in use."),
+		idleConns:       desc("idle_connections", "Number of idle connections."),
+		totalConns:      desc("connections", "Number of open connections."),
+		maxConns:        desc("max_connections", "Maximum number of connections."),
+		acquireCount:    desc("acquires_total", "Number of successful connection acquisitions."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquire:    desc("empty_acquires_total", "Number of acquisitions that had to wait for a connection."),
+		canceledAcquire: desc("canceled_acquires_total", "Number of acquisitions canceled by their context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+// Store gives the data of the households reported as metrics.
+type Store interface {
+	ListHouseholds(ctx context.Context) ([]postgres.Household, error)
+	GetChoreReport(ctx context.Context, start time.Time, end time.Time, metric repository.ReportMetric) (repository.Report, error)
+	ListChoresUrgency(ctx context.Context, now time.Time, location *time.Location) ([]repository.ChoreUrgency, error)
+}
+
+// reportWindows are the periods over which the minutes spent are reported.
+var reportWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// householdCollector reports the minutes spent on chores and when each chore
+// was last done, queried at each scrape.
+type householdCollector struct {
+	store    Store
+	location *time.Location
+
+	userMinutes  *prometheus.Desc
+	choreMinutes *prometheus.Desc
+	lastDone     *prometheus.Desc
+}
+
+func NewHouseholdCollector(store Store, location *time.Location) prometheus.Collector {
+	return &householdCollector{
+		store:    store,
+		location: location,
+		userMinutes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "user_chores_minutes"),
+			"Minutes of chores done by a user over the window.", []string{"household", "user", "window"}, nil),
+		choreMinutes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "chore_minutes"),
+			"Minutes spent on a chore over the window.", []string{"household", "chore", "window"}, nil),
+		lastDone: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "chore_last_done_timestamp_seconds"),
+			"Start time of the last task of a chore, absent when it was never done.", []string{"household", "chore"}, nil),
+	}
+}
+
+func (c *householdCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.userMinutes
+	ch <- c.choreMinutes
+	ch <- c.lastDone
+}
+
+func (c *householdCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	households, err := c.store.ListHouseholds(ctx)
+	if err != nil {
+		slog.Error(fmt.Sprintf("unable to list households for metrics: %v", err))
+		return
+	}
+	now := time.Now()
+	for _, household := range households {
+		householdCtx := repository.WithHousehold(ctx, household.ID)
+		householdID := strconv.FormatInt(int64(household.ID), 10)
+		for _, window := range reportWindows {
+			report, err := c.store.GetChoreReport(householdCtx, now.Add(-window.duration), now, repository.MetricMinutes)
+			if err != nil {
+				slog.Error(fmt.Sprintf("unable to get report of household %d for metrics: %v", household.ID, err))
+				continue
+			}
+			c.collectReport(ch, householdID, window.label, report)
+		}
+		urgencies, err := c.store.ListChoresUrgency(householdCtx, now, c.location)
+		if err != nil {
+			slog.Error(fmt.Sprintf("unable to get chores of household %d for metrics: %v", household.ID, err))
+			continue
+		}
+		for _, urgency := range urgencies {
+			if urgency.LastDone == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.lastDone, prometheus.GaugeValue,
+				float64(urgency.LastDone.Unix()), householdID, urgency.Chore.Name)
+		}
+	}
+}
+
+func (c *householdCollector) collectReport(ch chan<- prometheus.Metric, householdID string, window string, report repository.Report) {
+	userMinutes := make(map[string]int64, len(report.Users))
+	for _, chore := range report.Chores {
+		var choreMinutes int64
+		for user, minutes := range report.Report[chore] {
+			choreMinutes += minutes
+			userMinutes[user] += minutes
+		}
+		ch <- prometheus.MustNewConstMetric(c.choreMinutes, prometheus.GaugeValue, float64(choreMinutes), householdID, chore, window)
+	}
+	for _, user := range report.Users {
+		ch <- prometheus.MustNewConstMetric(c.userMinutes, prometheus.GaugeValue, float64(userMinutes[user]), householdID, user, window)
+	}
+}
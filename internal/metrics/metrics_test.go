@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	lastDone time.Time
+}
+
+func (s memoryStore) ListHouseholds(ctx context.Context) ([]postgres.Household, error) {
+	return []postgres.Household{{ID: 1, Name: "Home"}}, nil
+}
+
+func (s memoryStore) GetChoreReport(ctx context.Context, start time.Time, end time.Time, metric repository.ReportMetric) (repository.Report, error) {
+	if end.Sub(start) < 10*24*time.Hour {
+		return repository.Report{
+			Report: map[string]map[string]int64{"Dishes": {"Alice": 20}},
+			Users:  []string{"Alice"},
+			Chores: []string{"Dishes"},
+		}, nil
+	}
+	return repository.Report{
+		Report: map[string]map[string]int64{"Dishes": {"Alice": 20, "Bob": 15}, "Laundry": {"Bob": 30}},
+		Users:  []string{"Alice", "Bob"},
+		Chores: []string{"Dishes", "Laundry"},
+	}, nil
+}
+
+func (s memoryStore) ListChoresUrgency(ctx context.Context, now time.Time, location *time.Location) ([]repository.ChoreUrgency, error) {
+	return []repository.ChoreUrgency{
+		{Chore: postgres.Chore{Name: "Dishes"}, LastDone: &s.lastDone},
+		{Chore: postgres.Chore{Name: "Windows"}},
+	}, nil
+}
+
+func TestHouseholdCollector(t *testing.T) {
+	collector := NewHouseholdCollector(memoryStore{lastDone: time.Unix(1700000000, 0)}, time.UTC)
+	expected := `
+# HELP whodidthechores_chore_last_done_timestamp_seconds Start time of the last task of a chore, absent when it was never done.
+# TYPE whodidthechores_chore_last_done_timestamp_seconds gauge
+whodidthechores_chore_last_done_timestamp_seconds{chore="Dishes",household="1"} 1.7e+09
+# HELP whodidthechores_chore_minutes Minutes spent on a chore over the window.
+# TYPE whodidthechores_chore_minutes gauge
+whodidthechores_chore_minutes{chore="Dishes",household="1",window="30d"} 35
+whodidthechores_chore_minutes{chore="Dishes",household="1",window="7d"} 20
+whodidthechores_chore_minutes{chore="Laundry",household="1",window="30d"} 30
+# HELP whodidthechores_user_chores_minutes Minutes of chores done by a user over the window.
+# TYPE whodidthechores_user_chores_minutes gauge
+whodidthechores_user_chores_minutes{household="1",user="Alice",window="30d"} 20
+whodidthechores_user_chores_minutes{household="1",user="Alice",window="7d"} 20
+whodidthechores_user_chores_minutes{household="1",user="Bob",window="30d"} 45
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// sessionsCleanupInterval is how often the expired sessions are deleted.
+	sessionsCleanupInterval = time.Hour
+)
+
+// dummyPasswordHash is compared against when a username is unknown so that
+// unknown usernames take as long to reject as wrong passwords.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("whodidthechores"), bcrypt.DefaultCost)
+
+func accountPgError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case "accounts_username_key":
+		return fmt.Errorf("%w: account already exists", ErrDuplicateName)
+	case "accounts_username_check":
+		return fmt.Errorf("%w: invalid account username", ErrInvalidName)
+	case "accounts_user_id_key":
+		return fmt.Errorf("%w: user already linked to another account", ErrAlreadyLinked)
+	case "accounts_user_id_fkey":
+		return fmt.Errorf("%w: user not found", ErrNotFound)
+	}
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught account pg error: %v", pgErr))
+	return fmt.Errorf("%w: %w", ErrSQL, err)
+}
+
+type AccountParams struct {
+	ID              int32
+	Username        string
+	Password        string
+	PasswordConfirm string
+	UserID          string
+	Errors          AccountParamsError
+}
+
+type AccountParamsError struct {
+	Username string
+	Password string
+	UserID   string
+}
+
+type ValidatedAccount struct {
+	Username string
+	Password string
+	UserID   pgtype.Int4
+}
+
+// ValidateAccount checks the account form. An empty password is accepted when
+// editing an existing account and means the password is left unchanged.
+func (r *Repository) ValidateAccount(ctx context.Context, accountParams *AccountParams) (ValidatedAccount, error) {
+	isErr := false
+	if err := r.ValidateAccountUsername(ctx, accountParams.Username, accountParams.ID); err != nil {
+		isErr = true
+		switch {
+		case errors.Is(err, ErrInvalidName):
+			accountParams.Errors.Username = "Username can't be empty"
+		case errors.Is(err, ErrDuplicateName):
+			accountParams.Errors.Username = "Username already taken, please chose another one"
+		default:
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a username: %v", err))
+			accountParams.Errors.Username = "Unable to validate this username, please try again"
+		}
+	}
+	if accountParams.ID < 0 || accountParams.Password != "" {
+		if err := r.ValidateAccountPassword(accountParams.Password); err != nil {
+			isErr = true
+			accountParams.Errors.Password = fmt.Sprintf("Password must be at least %d characters long", minPasswordLength)
+		} else if accountParams.Password != accountParams.PasswordConfirm {
+			isErr = true
+			accountParams.Errors.Password = "Passwords don't match"
+		}
+	}
+	userID := pgtype.Int4{}
+	if accountParams.UserID != "" {
+		id, err := strconv.Atoi(accountParams.UserID)
+		if err != nil {
+			isErr = true
+			accountParams.Errors.UserID = "Please select an existing user"
+		} else if err = r.ValidateAccountUserId(ctx, id, accountParams.ID); err != nil {
+			isErr = true
+			switch {
+			case errors.Is(err, ErrNotFound):
+				accountParams.Errors.UserID = "User not found"
+			case errors.Is(err, ErrAlreadyLinked):
+				accountParams.Errors.UserID = "User already linked to another account"
+			default:
+				slog.ErrorContext(ctx, fmt.Sprintf("unable to validate an account user id: %v", err))
+				accountParams.Errors.UserID = "Unable to validate this user, please try again"
+			}
+		} else {
+			userID = pgtype.Int4{Int32: int32(id), Valid: true}
+		}
+	}
+	if isErr {
+		return ValidatedAccount{}, ErrValidation
+	}
+	return ValidatedAccount{Username: accountParams.Username, Password: accountParams.Password, UserID: userID}, nil
+}
+
+func (r *Repository) ValidateAccountUsername(ctx context.Context, username string, id int32) error {
+	if username == "" {
+		return ErrInvalidName
+	}
+	account, err := r.q.GetAccountByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("unable to get existing account: %w", err)
+	}
+	if account.ID != id {
+		return ErrDuplicateName
+	}
+	return nil
+}
+
+func (r *Repository) ValidateAccountPassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrTooSmall
+	}
+	return nil
+}
+
+func (r *Repository) ValidateAccountUserId(ctx context.Context, userId int, accountId int32) error {
+	if userId < 0 || userId > 2147483647 {
+		return ErrNotFound
+	}
+	if _, err := r.GetUser(ctx, int32(userId)); err != nil {
+		return fmt.Errorf("unable to get existing user: %w", err)
+	}
+	accounts, err := r.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get existing accounts: %w", err)
+	}
+	for _, account := range accounts {
+		if account.UserID.Valid && account.UserID.Int32 == int32(userId) && account.ID != accountId {
+			return ErrAlreadyLinked
+		}
+	}
+	return nil
+}
+
+func (r *Repository) CountAccounts(ctx context.Context) (int64, error) {
+	count, err := r.q.CountAccounts(ctx)
+	if err != nil {
+		if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+			return 0, sqlErr
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func (r *Repository) CreateAccount(ctx context.Context, account ValidatedAccount) (postgres.Account, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Account{}, err
+	}
+	hash, err := hashPassword(account.Password)
+	if err != nil {
+		return postgres.Account{}, err
+	}
+	newAccount, err := r.q.CreateAccount(ctx, postgres.CreateAccountParams{
+		HouseholdID:  householdID,
+		Username:     account.Username,
+		PasswordHash: hash,
+		UserID:       account.UserID,
+	})
+	if err != nil {
+		if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+			return postgres.Account{}, sqlErr
+		}
+		return postgres.Account{}, err
+	}
+	return newAccount, nil
+}
+
+func (r *Repository) ListAccounts(ctx context.Context) ([]postgres.Account, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := r.q.ListAccounts(ctx, householdID)
+	if err != nil {
+		if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *Repository) GetAccount(ctx context.Context, id int32) (postgres.Account, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Account{}, err
+	}
+	account, err := r.q.GetAccount(ctx, postgres.GetAccountParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Account{}, ErrNotFound
+		}
+		if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+			return postgres.Account{}, sqlErr
+		}
+		return postgres.Account{}, err
+	}
+	return account, nil
+}
+
+// UpdateAccount updates the username and linked user of an account, and its
+// password when a new one was provided. Changing the password revokes every
+// session of the account, in the same transaction.
+func (r *Repository) UpdateAccount(ctx context.Context, id int32, account ValidatedAccount) (postgres.Account, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Account{}, err
+	}
+	var hash string
+	if account.Password != "" {
+		if hash, err = hashPassword(account.Password); err != nil {
+			return postgres.Account{}, err
+		}
+	}
+	var updatedAccount postgres.Account
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		updatedAccount, err = q.UpdateAccount(ctx, postgres.UpdateAccountParams{
+			HouseholdID: householdID,
+			ID:          id,
+			Username:    account.Username,
+			UserID:      account.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		if hash == "" {
+			return nil
+		}
+		if err = q.UpdateAccountPassword(ctx, postgres.UpdateAccountPasswordParams{ID: id, PasswordHash: hash}); err != nil {
+			return err
+		}
+		return q.DeleteAccountSessions(ctx, id)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Account{}, ErrNotFound
+		}
+		if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+			return postgres.Account{}, sqlErr
+		}
+		return postgres.Account{}, err
+	}
+	return updatedAccount, nil
+}
+
+func (r *Repository) DeleteAccount(ctx context.Context, id int32) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	err = r.q.DeleteAccount(ctx, postgres.DeleteAccountParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	return nil
+}
+
+// Authenticate returns the account matching the given credentials or
+// ErrInvalidCredentials.
+func (r *Repository) Authenticate(ctx context.Context, username string, password string) (postgres.Account, error) {
+	account, err := r.q.GetAccountByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+			return postgres.Account{}, ErrInvalidCredentials
+		}
+		return postgres.Account{}, err
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)); err != nil {
+		return postgres.Account{}, ErrInvalidCredentials
+	}
+	return account, nil
+}
+
+// newToken returns a random token to be given to a client, of which only the
+// hash is persisted.
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession stores a new session for the account and returns its token.
+// Only a hash of the token is persisted.
+func (r *Repository) CreateSession(ctx context.Context, accountID int32, duration time.Duration) (string, time.Time, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to generate session token: %w", err)
+	}
+	expiresAt := time.Now().Add(duration)
+	if _, err := r.q.CreateSession(ctx, postgres.CreateSessionParams{
+		TokenHash: hashToken(token),
+		AccountID: accountID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// GetSessionAccount returns the account owning a non expired session.
+func (r *Repository) GetSessionAccount(ctx context.Context, token string) (postgres.Account, error) {
+	account, err := r.q.GetSessionAccount(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Account{}, ErrNotFound
+		}
+		return postgres.Account{}, err
+	}
+	return account, nil
+}
+
+func (r *Repository) DeleteSession(ctx context.Context, token string) error {
+	return r.q.DeleteSession(ctx, hashToken(token))
+}
+
+func (r *Repository) DeleteExpiredSessions(ctx context.Context) error {
+	return r.q.DeleteExpiredSessions(ctx)
+}
+
+// CleanSessions deletes the expired sessions now and then every
+// sessionsCleanupInterval, until ctx is canceled.
+func (r *Repository) CleanSessions(ctx context.Context) {
+	ticker := time.NewTicker(sessionsCleanupInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.DeleteExpiredSessions(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, fmt.Sprintf("unable to delete expired sessions: %v", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
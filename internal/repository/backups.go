@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+const (
+	// BackupFormat identifies the backup archives of the application.
+	BackupFormat = "whodidthechores-backup"
+	// BackupVersion is the version of the archives written. It is increased
+	// whenever the content of an archive changes. Version 2 added the difficulty
+	// of the chores and the points of the tasks, version 3 the share of the users
+	// and version 4 the archived state of the chores and users.
+	BackupVersion = 4
+)
+
+// Backup is an archive of every household with its chores, users and tasks.
+// Accounts, sessions, webhooks, timers and calendar feeds are not part of it.
+type Backup struct {
+	Format     string            `json:"format"`
+	Version    int               `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Households []HouseholdBackup `json:"households"`
+}
+
+type HouseholdBackup struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Chores    []Chore   `json:"chores"`
+	Users     []User    `json:"users"`
+	Tasks     []Task    `json:"tasks"`
+}
+
+type RestoreResult struct {
+	Households int
+	Chores     int
+	Users      int
+	Tasks      int
+}
+
+// CreateBackup returns a consistent snapshot of every household.
+func (r *Repository) CreateBackup(ctx context.Context) (Backup, error) {
+	backup := Backup{
+		Format:    BackupFormat,
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	err := r.db.InSnapshot(ctx, func(q postgres.Querier) error {
+		households, err := q.ListHouseholds(ctx)
+		if err != nil {
+			return err
+		}
+		backup.Households = make([]HouseholdBackup, 0, len(households))
+		for _, household := range households {
+			householdBackup := HouseholdBackup{
+				ID:        household.ID,
+				Name:      household.Name,
+				CreatedAt: household.CreatedAt,
+			}
+			chores, err := q.ListChores(ctx, household.ID)
+			if err != nil {
+				return err
+			}
+			for _, chore := range chores {
+				householdBackup.Chores = append(householdBackup.Chores, Chore(chore))
+			}
+			users, err := q.ListUsers(ctx, household.ID)
+			if err != nil {
+				return err
+			}
+			for _, user := range users {
+				householdBackup.Users = append(householdBackup.Users, User(user))
+			}
+			tasks, err := q.ListTasks(ctx, household.ID)
+			if err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				householdBackup.Tasks = append(householdBackup.Tasks, Task(task))
+			}
+			backup.Households = append(backup.Households, householdBackup)
+		}
+		return nil
+	})
+	if err != nil {
+		return Backup{}, err
+	}
+	return backup, nil
+}
+
+// ValidateBackup checks that an archive can be restored: its format and
+// version are known and its tasks only reference chores and users of their
+// own household.
+func ValidateBackup(backup Backup) error {
+	if backup.Format != BackupFormat {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidBackup, backup.Format)
+	}
+	if backup.Version < 1 || backup.Version > BackupVersion {
+		return fmt.Errorf("%w: unsupported version %d, only versions up to %d are supported", ErrInvalidBackup, backup.Version, BackupVersion)
+	}
+	for _, household := range backup.Households {
+		chores := make(map[int32]bool, len(household.Chores))
+		for _, chore := range household.Chores {
+			chores[chore.ID] = true
+		}
+		users := make(map[int32]bool, len(household.Users))
+		for _, user := range household.Users {
+			users[user.ID] = true
+		}
+		for _, task := range household.Tasks {
+			if !chores[task.ChoreID] {
+				return fmt.Errorf("%w: task %v of household %d references unknown chore %d", ErrInvalidBackup, task.ID, household.ID, task.ChoreID)
+			}
+			if !users[task.UserID] {
+				return fmt.Errorf("%w: task %v of household %d references unknown user %d", ErrInvalidBackup, task.ID, household.ID, task.UserID)
+			}
+		}
+	}
+	return nil
+}
+
+// upgradeBackup fills the fields an archive of an older version lacks with the
+// values the migrations adding them gave: chores of the lowest difficulty, tasks
+// worth their duration and users of the lowest share.
+func upgradeBackup(backup *Backup) {
+	for _, household := range backup.Households {
+		if backup.Version < 2 {
+			for index := range household.Chores {
+				household.Chores[index].Difficulty = MinDifficulty
+			}
+			for index := range household.Tasks {
+				household.Tasks[index].Points = int64(household.Tasks[index].DurationMn)
+			}
+		}
+		if backup.Version < 3 {
+			for index := range household.Users {
+				household.Users[index].Share = MinShare
+			}
+		}
+	}
+}
+
+// RestoreBackup restores an archive in a single transaction. By default the
+// IDs of the archive are kept, which requires a database without households.
+// With remap, every household of the archive is added as a new household and
+// its chores, users and tasks get new IDs, leaving the existing data as is.
+func (r *Repository) RestoreBackup(ctx context.Context, backup Backup, remap bool) (RestoreResult, error) {
+	if err := ValidateBackup(backup); err != nil {
+		return RestoreResult{}, err
+	}
+	upgradeBackup(&backup)
+	result := RestoreResult{}
+	err := r.withTx(ctx, func(q postgres.Querier) error {
+		if !remap {
+			count, err := q.CountHouseholds(ctx)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: %d households already exist", ErrNotEmpty, count)
+			}
+		}
+		for _, household := range backup.Households {
+			var err error
+			if remap {
+				err = restoreRemapped(ctx, q, household)
+			} else {
+				err = restoreHousehold(ctx, q, household)
+			}
+			if err != nil {
+				return fmt.Errorf("unable to restore household %d: %w", household.ID, err)
+			}
+			result.Households++
+			result.Chores += len(household.Chores)
+			result.Users += len(household.Users)
+			result.Tasks += len(household.Tasks)
+		}
+		if remap {
+			return nil
+		}
+		if err := q.ResetHouseholdsSequence(ctx); err != nil {
+			return err
+		}
+		if err := q.ResetChoresSequence(ctx); err != nil {
+			return err
+		}
+		return q.ResetUsersSequence(ctx)
+	})
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	return result, nil
+}
+
+func restoreHousehold(ctx context.Context, q postgres.Querier, household HouseholdBackup) error {
+	err := q.RestoreHousehold(ctx, postgres.RestoreHouseholdParams{ID: household.ID, Name: household.Name, CreatedAt: household.CreatedAt})
+	if err != nil {
+		if sqlErr := householdPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	for _, chore := range household.Chores {
+		err = q.RestoreChore(ctx, postgres.RestoreChoreParams{
+			ID:                   chore.ID,
+			HouseholdID:          household.ID,
+			Name:                 chore.Name,
+			Description:          chore.Description,
+			DefaultDurationMn:    chore.DefaultDurationMn,
+			ScheduleKind:         chore.ScheduleKind,
+			ScheduleIntervalDays: chore.ScheduleIntervalDays,
+			ScheduleWeekdays:     chore.ScheduleWeekdays,
+			ScheduleMonthDay:     chore.ScheduleMonthDay,
+			Difficulty:           chore.Difficulty,
+			Archived:             chore.Archived,
+		})
+		if err != nil {
+			if sqlErr := chorePgError(ctx, err); sqlErr != nil {
+				return fmt.Errorf("chore %d: %w", chore.ID, sqlErr)
+			}
+			return err
+		}
+	}
+	for _, user := range household.Users {
+		err = q.RestoreUser(ctx, postgres.RestoreUserParams{ID: user.ID, HouseholdID: household.ID, Name: user.Name, Share: user.Share, Archived: user.Archived})
+		if err != nil {
+			if sqlErr := userPgError(ctx, err); sqlErr != nil {
+				return fmt.Errorf("user %d: %w", user.ID, sqlErr)
+			}
+			return err
+		}
+	}
+	for _, task := range household.Tasks {
+		id := task.ID
+		if id == uuid.Nil {
+			id = uuid.New()
+		}
+		err = q.RestoreTask(ctx, postgres.RestoreTaskParams{
+			ID:          id,
+			HouseholdID: household.ID,
+			UserID:      task.UserID,
+			ChoreID:     task.ChoreID,
+			StartedAt:   task.StartedAt,
+			DurationMn:  task.DurationMn,
+			Description: task.Description,
+			Points:      task.Points,
+		})
+		if err != nil {
+			if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+				return fmt.Errorf("task %v: %w", task.ID, sqlErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreRemapped adds the household as a new one, translating the IDs of the
+// archive to the newly created ones.
+func restoreRemapped(ctx context.Context, q postgres.Querier, household HouseholdBackup) error {
+	newHousehold, err := q.CreateHousehold(ctx, household.Name)
+	if err != nil {
+		if sqlErr := householdPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	choreIDs := make(map[int32]int32, len(household.Chores))
+	for _, chore := range household.Chores {
+		newChore, err := q.CreateChore(ctx, postgres.CreateChoreParams{
+			HouseholdID:          newHousehold.ID,
+			Name:                 chore.Name,
+			Description:          chore.Description,
+			DefaultDurationMn:    chore.DefaultDurationMn,
+			ScheduleKind:         chore.ScheduleKind,
+			ScheduleIntervalDays: chore.ScheduleIntervalDays,
+			ScheduleWeekdays:     chore.ScheduleWeekdays,
+			ScheduleMonthDay:     chore.ScheduleMonthDay,
+			Difficulty:           chore.Difficulty,
+		})
+		if err != nil {
+			if sqlErr := chorePgError(ctx, err); sqlErr != nil {
+				return fmt.Errorf("chore %d: %w", chore.ID, sqlErr)
+			}
+			return err
+		}
+		if chore.Archived {
+			_, err = q.SetChoreArchived(ctx, postgres.SetChoreArchivedParams{HouseholdID: newHousehold.ID, ID: newChore.ID, Archived: true})
+			if err != nil {
+				return err
+			}
+		}
+		choreIDs[chore.ID] = newChore.ID
+	}
+	userIDs := make(map[int32]int32, len(household.Users))
+	for _, user := range household.Users {
+		newUser, err := q.CreateUser(ctx, postgres.CreateUserParams{HouseholdID: newHousehold.ID, Name: user.Name, Share: user.Share})
+		if err != nil {
+			if sqlErr := userPgError(ctx, err); sqlErr != nil {
+				return fmt.Errorf("user %d: %w", user.ID, sqlErr)
+			}
+			return err
+		}
+		if user.Archived {
+			_, err = q.SetUserArchived(ctx, postgres.SetUserArchivedParams{HouseholdID: newHousehold.ID, ID: newUser.ID, Archived: true})
+			if err != nil {
+				return err
+			}
+		}
+		userIDs[user.ID] = newUser.ID
+	}
+	for _, task := range household.Tasks {
+		_, err := q.CreateTask(ctx, postgres.CreateTaskParams{
+			HouseholdID: newHousehold.ID,
+			UserID:      userIDs[task.UserID],
+			ChoreID:     choreIDs[task.ChoreID],
+			StartedAt:   task.StartedAt,
+			DurationMn:  task.DurationMn,
+			Description: task.Description,
+			Points:      task.Points,
+		})
+		if err != nil {
+			if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+				return fmt.Errorf("task %v: %w", task.ID, sqlErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
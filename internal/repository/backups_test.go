@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBackup(t *testing.T) {
+	archive := `{
+		"format": "whodidthechores-backup",
+		"version": 1,
+		"created_at": "2024-03-10T11:00:00Z",
+		"households": [{
+			"id": 1,
+			"name": "Home",
+			"created_at": "2023-01-01T00:00:00Z",
+			"chores": [{"id": 3, "name": "Dishes", "description": "", "default_duration_mn": 15, "schedule_kind": "none"}],
+			"users": [{"id": 4, "name": "Alice"}],
+			"tasks": [{"id": "0f8fad5b-d9cb-469f-a165-70867728950e", "user_id": 4, "chore_id": 3, "started_at": "2024-03-09T08:00:00Z", "duration_mn": 15, "description": ""}]
+		}]
+	}`
+	var backup Backup
+	assert.NoError(t, json.Unmarshal([]byte(archive), &backup))
+	assert.NoError(t, ValidateBackup(backup))
+	assert.Equal(t, "Dishes", backup.Households[0].Chores[0].Name)
+
+	// Version 1 archives have neither difficulties, points nor shares.
+	upgradeBackup(&backup)
+	assert.Equal(t, int32(1), backup.Households[0].Chores[0].Difficulty)
+	assert.Equal(t, int64(15), backup.Households[0].Tasks[0].Points)
+	assert.Equal(t, int32(1), backup.Households[0].Users[0].Share)
+
+	unknownVersion := backup
+	unknownVersion.Version = BackupVersion + 1
+	assert.ErrorIs(t, ValidateBackup(unknownVersion), ErrInvalidBackup)
+
+	unknownFormat := backup
+	unknownFormat.Format = "something-else"
+	assert.ErrorIs(t, ValidateBackup(unknownFormat), ErrInvalidBackup)
+
+	backup.Households[0].Tasks = append(backup.Households[0].Tasks, Task{ID: uuid.New(), UserID: 4, ChoreID: 42})
+	assert.ErrorIs(t, ValidateBackup(backup), ErrInvalidBackup)
+}
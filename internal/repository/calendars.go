@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/ical"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+const (
+	// CalendarHistory is how far back the tasks of a calendar feed go.
+	CalendarHistory = 365 * 24 * time.Hour
+	// CalendarRefresh is how often calendar applications are asked to fetch
+	// the feeds again.
+	CalendarRefresh = time.Hour
+)
+
+func calendarFeedPgError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case "calendar_feeds_user_id_fkey":
+		return fmt.Errorf("%w: calendar feed user doesn't exist", ErrNotFound)
+	}
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught calendar feed pg error: %v", pgErr.Code))
+	return fmt.Errorf("%w: %w", ErrSQL, err)
+}
+
+func (r *Repository) ListCalendarFeeds(ctx context.Context) ([]postgres.CalendarFeed, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	feeds, err := r.q.ListCalendarFeeds(ctx, householdID)
+	if err != nil {
+		if sqlErr := calendarFeedPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// CreateCalendarFeed creates a feed of the tasks of the user, or of the whole
+// household when userID is null, and returns its token. Only a hash of the
+// token is persisted: it can't be shown again.
+func (r *Repository) CreateCalendarFeed(ctx context.Context, userID pgtype.Int4) (postgres.CalendarFeed, string, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.CalendarFeed{}, "", err
+	}
+	token, err := newToken()
+	if err != nil {
+		return postgres.CalendarFeed{}, "", fmt.Errorf("unable to generate calendar feed token: %w", err)
+	}
+	feed, err := r.q.CreateCalendarFeed(ctx, postgres.CreateCalendarFeedParams{
+		HouseholdID: householdID,
+		UserID:      userID,
+		TokenHash:   hashToken(token),
+	})
+	if err != nil {
+		if sqlErr := calendarFeedPgError(ctx, err); sqlErr != nil {
+			return postgres.CalendarFeed{}, "", sqlErr
+		}
+		return postgres.CalendarFeed{}, "", err
+	}
+	return feed, token, nil
+}
+
+// DeleteCalendarFeed revokes the feed: its URL no longer works.
+func (r *Repository) DeleteCalendarFeed(ctx context.Context, id int32) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	deleted, err := r.q.DeleteCalendarFeed(ctx, postgres.DeleteCalendarFeedParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if sqlErr := calendarFeedPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetCalendar returns the calendar of the feed of the token, or ErrNotFound.
+// It lists the tasks done since CalendarHistory, by the user of the feed if
+// any, and the day each scheduled chore is next due, overdue chores being
+// shown today. The days are those of location.
+func (r *Repository) GetCalendar(ctx context.Context, token string, now time.Time, location *time.Location) (ical.Calendar, error) {
+	feed, err := r.q.GetCalendarFeed(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ical.Calendar{}, ErrNotFound
+		}
+		return ical.Calendar{}, err
+	}
+	ctx = WithHousehold(ctx, feed.HouseholdID)
+	household, err := r.GetHousehold(ctx, feed.HouseholdID)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	calendar := ical.Calendar{
+		Name:     household.Name + " chores",
+		TimeZone: location.String(),
+		Refresh:  CalendarRefresh,
+		Stamp:    now,
+	}
+	if feed.UserID.Valid {
+		user, err := r.GetUser(ctx, feed.UserID.Int32)
+		if err != nil {
+			return ical.Calendar{}, err
+		}
+		calendar.Name = fmt.Sprintf("%s chores (%s)", user.Name, household.Name)
+	}
+
+	tasks, err := r.ListUsersTasksBetween(ctx, feed.UserID.Int32, now.Add(-CalendarHistory), now)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	for _, row := range tasks {
+		summary := row.Chore.Name
+		if !feed.UserID.Valid {
+			summary = fmt.Sprintf("%s (%s)", row.Chore.Name, row.User.Name)
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("task-%s@whodidthechores", row.Task.ID),
+			Summary:     summary,
+			Description: row.Task.Description,
+			Start:       row.Task.StartedAt,
+			End:         row.Task.StartedAt.Add(time.Duration(row.Task.DurationMn) * time.Minute),
+		})
+	}
+
+	chores, err := r.ListChores(ctx)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	dues, err := r.ListChoresDue(ctx, chores, now, location)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	today := startOfDay(now, location)
+	for _, chore := range chores {
+		due := dues[chore.ID]
+		if due.Status == NotScheduled {
+			continue
+		}
+		day, summary := due.Due, "Due: "+chore.Name
+		if due.Status == Overdue {
+			day, summary = today, fmt.Sprintf("Due: %s (overdue)", chore.Name)
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:         fmt.Sprintf("chore-%d-%s@whodidthechores", chore.ID, day.Format(time.DateOnly)),
+			Summary:     summary,
+			Description: chore.Description,
+			Start:       day,
+			End:         day.AddDate(0, 0, 1),
+			AllDay:      true,
+		})
+	}
+	return calendar, nil
+}
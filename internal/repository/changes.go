@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+const (
+	ResourceTimer = "timer"
+	// EventTimerUpdated is notified when a timer is started, paused, resumed,
+	// stopped or discarded. Timers aren't sent to webhooks.
+	EventTimerUpdated = "timer.updated"
+)
+
+const (
+	// changesBuffer is the number of changes a subscriber can be behind
+	// before missing some.
+	changesBuffer = 16
+	// listenRetryDelay is the delay before listening again when listening
+	// for the changes failed, e.g. the database restarted.
+	listenRetryDelay = 5 * time.Second
+)
+
+// Change is a mutation of the resources of a household, notified to every
+// instance of the application so that they refresh the pages showing them.
+type Change struct {
+	HouseholdID int32  `json:"household_id"`
+	Event       string `json:"event"`
+}
+
+// Resource returns the resource changed, e.g. "task" for "task.created".
+func (c Change) Resource() string {
+	resource, _, _ := strings.Cut(c.Event, ".")
+	return resource
+}
+
+// notifyChange notifies the change to the instances of the application. It is
+// given the queries of the transaction of the mutation so that the change is
+// only notified when the mutation is committed.
+func notifyChange(ctx context.Context, q postgres.Querier, householdID int32, event string) error {
+	payload, err := json.Marshal(Change{HouseholdID: householdID, Event: event})
+	if err != nil {
+		return fmt.Errorf("unable to encode change: %w", err)
+	}
+	if err = q.NotifyChange(ctx, string(payload)); err != nil {
+		return fmt.Errorf("unable to notify change: %w", err)
+	}
+	return nil
+}
+
+// subscribers are the channels the changes of each household are sent to.
+type subscribers struct {
+	mu       sync.Mutex
+	channels map[chan Change]int32
+	// closed is set once the changes are no longer listened to.
+	closed bool
+}
+
+// SubscribeChanges returns the changes of the household of the context,
+// listened to by ListenChanges. The channel is closed when ctx is canceled
+// or ListenChanges returns. A subscriber too slow to receive them misses
+// changes rather than holding the others.
+func (r *Repository) SubscribeChanges(ctx context.Context) (<-chan Change, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(chan Change, changesBuffer)
+	r.subscribers.mu.Lock()
+	defer r.subscribers.mu.Unlock()
+	if r.subscribers.closed {
+		close(changes)
+		return changes, nil
+	}
+	if r.subscribers.channels == nil {
+		r.subscribers.channels = map[chan Change]int32{}
+	}
+	r.subscribers.channels[changes] = householdID
+	go func() {
+		<-ctx.Done()
+		r.subscribers.mu.Lock()
+		defer r.subscribers.mu.Unlock()
+		if _, ok := r.subscribers.channels[changes]; ok {
+			delete(r.subscribers.channels, changes)
+			close(changes)
+		}
+	}()
+	return changes, nil
+}
+
+// ListenChanges sends the changes notified by every instance of the
+// application to the subscribers of this one, until ctx is canceled. It
+// listens again when listening fails.
+func (r *Repository) ListenChanges(ctx context.Context) {
+	for {
+		err := r.db.Listen(ctx, r.publishChange)
+		if ctx.Err() != nil {
+			break
+		}
+		slog.ErrorContext(ctx, fmt.Sprintf("unable to listen for changes, retrying in %v: %v", listenRetryDelay, err))
+		select {
+		case <-ctx.Done():
+		case <-time.After(listenRetryDelay):
+		}
+	}
+	r.subscribers.mu.Lock()
+	defer r.subscribers.mu.Unlock()
+	r.subscribers.closed = true
+	for changes := range r.subscribers.channels {
+		close(changes)
+	}
+	r.subscribers.channels = nil
+}
+
+// publishChange sends a change notified by the store to the subscribers of
+// its household.
+func (r *Repository) publishChange(payload string) {
+	var change Change
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		slog.Warn(fmt.Sprintf("unable to decode change %q: %v", payload, err))
+		return
+	}
+	r.subscribers.mu.Lock()
+	defer r.subscribers.mu.Unlock()
+	for changes, householdID := range r.subscribers.channels {
+		if householdID != change.HouseholdID {
+			continue
+		}
+		select {
+		case changes <- change:
+		default:
+		}
+	}
+}
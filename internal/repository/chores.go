@@ -5,41 +5,89 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
 )
 
-func chorePgError(err error) error {
+// The difficulty of a chore weights the duration of its tasks to compute their
+// points. A chore without difficulty is of the lowest one.
+const (
+	MinDifficulty = 1
+	MaxDifficulty = 10
+)
+
+func chorePgError(ctx context.Context, err error) error {
 	var pgErr *pgconn.PgError
 	if !errors.As(err, &pgErr) {
 		return nil
 	}
 	switch pgErr.ConstraintName {
-	case "chores_name_key":
+	case "chores_household_id_name_key":
 		return fmt.Errorf("%w: chore already exists", ErrDuplicateName)
 	case "chores_name_check":
 		return fmt.Errorf("%w: invalid chore name", ErrInvalidName)
+	case "chores_schedule_kind_check", "chores_schedule_interval_days_check", "chores_schedule_weekdays_check", "chores_schedule_month_day_check":
+		return fmt.Errorf("%w: %s", ErrInvalidSchedule, pgErr.ConstraintName)
+	case "chores_difficulty_check":
+		return fmt.Errorf("%w: must be between %d and %d", ErrInvalidDifficulty, MinDifficulty, MaxDifficulty)
 	case "tasks_chore_id_fkey":
 		return fmt.Errorf("%w: chore linked to existing task", ErrStillInUse)
 	}
-	slog.Error(fmt.Sprintf("uncaught chore pg error: %v", pgErr))
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught chore pg error: %v", pgErr))
 	return fmt.Errorf("%w: %w", ErrSQL, err)
 }
 
 type ChoreParams struct {
-	ID                int32
-	Name              string
-	Description       string
-	DefaultDurationMn string
-	Errors            ChoreParamsError
+	ID                   int32
+	Name                 string
+	Description          string
+	DefaultDurationMn    string
+	ScheduleKind         string
+	ScheduleIntervalDays string
+	ScheduleWeekdays     []string
+	ScheduleMonthDay     string
+	Difficulty           string
+	Archived             bool
+	Errors               ChoreParamsError
 }
 
 type ChoreParamsError struct {
 	Name              string
 	Description       string
 	DefaultDurationMn string
+	Schedule          string
+	Difficulty        string
+}
+
+// NewChoreParams returns the form parameters of an existing chore.
+func NewChoreParams(chore postgres.Chore) ChoreParams {
+	choreParams := ChoreParams{
+		ID:                chore.ID,
+		Name:              chore.Name,
+		Description:       chore.Description,
+		DefaultDurationMn: strconv.FormatInt(int64(chore.DefaultDurationMn), 10),
+		ScheduleKind:      chore.ScheduleKind,
+		Difficulty:        strconv.FormatInt(int64(chore.Difficulty), 10),
+		Archived:          chore.Archived,
+	}
+	schedule := ChoreSchedule(chore)
+	if schedule.IntervalDays > 0 {
+		choreParams.ScheduleIntervalDays = strconv.FormatInt(int64(schedule.IntervalDays), 10)
+	}
+	if schedule.MonthDay > 0 {
+		choreParams.ScheduleMonthDay = strconv.FormatInt(int64(schedule.MonthDay), 10)
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if schedule.HasWeekday(day) {
+			choreParams.ScheduleWeekdays = append(choreParams.ScheduleWeekdays, strconv.Itoa(int(day)))
+		}
+	}
+	return choreParams
 }
 
 func (r *Repository) ValidateChore(ctx context.Context, choreParams *ChoreParams) (postgres.CreateChoreParams, error) {
@@ -52,7 +100,7 @@ func (r *Repository) ValidateChore(ctx context.Context, choreParams *ChoreParams
 		case errors.Is(err, ErrDuplicateName):
 			choreParams.Errors.Name = "Name already taken, please chose another one"
 		default:
-			slog.Error(fmt.Sprintf("Unable to validate a name: %v", err))
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a name: %v", err))
 			choreParams.Errors.Name = "Unable to validate this name, please try again"
 		}
 	}
@@ -60,7 +108,7 @@ func (r *Repository) ValidateChore(ctx context.Context, choreParams *ChoreParams
 		isErr = true
 		switch {
 		default:
-			slog.Error(fmt.Sprintf("Unable to validate a description: %v", err))
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a description: %v", err))
 			choreParams.Errors.Description = "Unable to validate this description, please try again"
 		}
 	}
@@ -69,24 +117,97 @@ func (r *Repository) ValidateChore(ctx context.Context, choreParams *ChoreParams
 		isErr = true
 		choreParams.Errors.DefaultDurationMn = "Please enter a number"
 	} else if err = r.ValidateChoreDefaultDuration(default_duration); err != nil {
+		isErr = true
 		switch {
 		case errors.Is(err, ErrTooSmall):
 			choreParams.Errors.DefaultDurationMn = "Default duration can't be negative"
 		case errors.Is(err, ErrTooBig):
 			choreParams.Errors.DefaultDurationMn = "Default duration too big, please select a smaller number"
 		default:
-			slog.Error(fmt.Sprintf("Unable to validate a default duration: %v", err))
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a default duration: %v", err))
 			choreParams.Errors.DefaultDurationMn = "Unable to validate this duration, please try again"
 		}
 	}
+	schedule, err := r.ValidateChoreSchedule(choreParams.ScheduleKind, choreParams.ScheduleIntervalDays, choreParams.ScheduleWeekdays, choreParams.ScheduleMonthDay)
+	if err != nil {
+		isErr = true
+		switch {
+		case errors.Is(err, ErrInvalidSchedule):
+			choreParams.Errors.Schedule = "Please select a valid schedule"
+		case errors.Is(err, ErrInvalidInterval):
+			choreParams.Errors.Schedule = "Please enter a number of days greater than zero"
+		case errors.Is(err, ErrInvalidWeekdays):
+			choreParams.Errors.Schedule = "Please select at least one day of the week"
+		case errors.Is(err, ErrInvalidMonthDay):
+			choreParams.Errors.Schedule = "Please enter a day of the month between 1 and 31"
+		default:
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a schedule: %v", err))
+			choreParams.Errors.Schedule = "Unable to validate this schedule, please try again"
+		}
+	}
+	if choreParams.Difficulty == "" {
+		choreParams.Difficulty = strconv.Itoa(MinDifficulty)
+	}
+	difficulty, err := strconv.Atoi(choreParams.Difficulty)
+	if err != nil {
+		isErr = true
+		choreParams.Errors.Difficulty = "Please enter a number"
+	} else if err = r.ValidateChoreDifficulty(difficulty); err != nil {
+		isErr = true
+		choreParams.Errors.Difficulty = fmt.Sprintf("Please enter a difficulty between %d and %d", MinDifficulty, MaxDifficulty)
+	}
 	if isErr {
 		return postgres.CreateChoreParams{}, ErrValidation
 	}
-	return postgres.CreateChoreParams{Name: choreParams.Name, Description: choreParams.Description, DefaultDurationMn: int32(default_duration)}, nil
+	return postgres.CreateChoreParams{
+		Name:                 choreParams.Name,
+		Description:          choreParams.Description,
+		DefaultDurationMn:    int32(default_duration),
+		ScheduleKind:         schedule.Kind,
+		ScheduleIntervalDays: schedule.IntervalDays,
+		ScheduleWeekdays:     schedule.Weekdays,
+		ScheduleMonthDay:     schedule.MonthDay,
+		Difficulty:           int32(difficulty),
+	}, nil
+}
+
+// ValidateChoreSchedule parses a schedule form. Only the fields used by the
+// selected kind are validated, the others are reset.
+func (r *Repository) ValidateChoreSchedule(kind string, intervalDays string, weekdays []string, monthDay string) (Schedule, error) {
+	switch kind {
+	case "", ScheduleNone:
+		return Schedule{Kind: ScheduleNone}, nil
+	case ScheduleInterval:
+		days, err := strconv.Atoi(intervalDays)
+		if err != nil || days < 1 || days > 36500 {
+			return Schedule{}, ErrInvalidInterval
+		}
+		return Schedule{Kind: ScheduleInterval, IntervalDays: int32(days)}, nil
+	case ScheduleWeekly:
+		var mask int32
+		for _, weekday := range weekdays {
+			day, err := strconv.Atoi(weekday)
+			if err != nil || day < int(time.Sunday) || day > int(time.Saturday) {
+				return Schedule{}, ErrInvalidWeekdays
+			}
+			mask |= 1 << day
+		}
+		if mask == 0 {
+			return Schedule{}, ErrInvalidWeekdays
+		}
+		return Schedule{Kind: ScheduleWeekly, Weekdays: mask}, nil
+	case ScheduleMonthly:
+		day, err := strconv.Atoi(monthDay)
+		if err != nil || day < 1 || day > 31 {
+			return Schedule{}, ErrInvalidMonthDay
+		}
+		return Schedule{Kind: ScheduleMonthly, MonthDay: int32(day)}, nil
+	}
+	return Schedule{}, ErrInvalidSchedule
 }
 
 func (r *Repository) ValidateChoreName(ctx context.Context, name string, id int32) error {
-	existingChores, err := r.q.ListChores(ctx)
+	existingChores, err := r.ListAllChores(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get existing chores: %w", err)
 	}
@@ -118,10 +239,33 @@ func (r *Repository) ValidateChoreDefaultDuration(default_duration int) error {
 	return nil
 }
 
+func (r *Repository) ValidateChoreDifficulty(difficulty int) error {
+	if difficulty < MinDifficulty || difficulty > MaxDifficulty {
+		return ErrInvalidDifficulty
+	}
+	return nil
+}
+
 func (r *Repository) CreateChore(ctx context.Context, params postgres.CreateChoreParams) (postgres.Chore, error) {
-	newChore, err := r.q.CreateChore(ctx, params)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Chore{}, err
+	}
+	params.HouseholdID = householdID
+	if params.Difficulty == 0 {
+		params.Difficulty = MinDifficulty
+	}
+	var newChore postgres.Chore
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		newChore, err = q.CreateChore(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventChoreCreated, Chore(newChore))
+	})
 	if err != nil {
-		if sqlErr := chorePgError(err); sqlErr != nil {
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
 			return postgres.Chore{}, sqlErr
 		}
 		return postgres.Chore{}, err
@@ -130,10 +274,32 @@ func (r *Repository) CreateChore(ctx context.Context, params postgres.CreateChor
 	return newChore, nil
 }
 
+// ListChores returns the chores of the household that aren't archived.
 func (r *Repository) ListChores(ctx context.Context) ([]postgres.Chore, error) {
-	chores, err := r.q.ListChores(ctx)
+	chores, err := r.ListAllChores(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(chores, func(chore postgres.Chore) bool { return chore.Archived }), nil
+}
+
+func (r *Repository) ListArchivedChores(ctx context.Context) ([]postgres.Chore, error) {
+	chores, err := r.ListAllChores(ctx)
 	if err != nil {
-		if sqlErr := chorePgError(err); sqlErr != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(chores, func(chore postgres.Chore) bool { return !chore.Archived }), nil
+}
+
+// ListAllChores returns the chores of the household, archived ones included.
+func (r *Repository) ListAllChores(ctx context.Context) ([]postgres.Chore, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chores, err := r.q.ListChores(ctx, householdID)
+	if err != nil {
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
 			return nil, sqlErr
 		}
 		return nil, err
@@ -142,9 +308,16 @@ func (r *Repository) ListChores(ctx context.Context) ([]postgres.Chore, error) {
 }
 
 func (r *Repository) GetChore(ctx context.Context, id int32) (postgres.Chore, error) {
-	chore, err := r.q.GetChore(ctx, id)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Chore{}, err
+	}
+	chore, err := r.q.GetChore(ctx, postgres.GetChoreParams{HouseholdID: householdID, ID: id})
 	if err != nil {
-		if sqlErr := chorePgError(err); sqlErr != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Chore{}, ErrNotFound
+		}
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
 			return postgres.Chore{}, sqlErr
 		}
 		return postgres.Chore{}, err
@@ -153,15 +326,40 @@ func (r *Repository) GetChore(ctx context.Context, id int32) (postgres.Chore, er
 }
 
 func (r *Repository) UpdateChore(ctx context.Context, id int32, choreParams postgres.CreateChoreParams) (postgres.Chore, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Chore{}, err
+	}
+	if choreParams.Difficulty == 0 {
+		choreParams.Difficulty = MinDifficulty
+	}
 	params := postgres.UpdateChoreParams{
+		HouseholdID:       householdID,
 		ID:                id,
 		Name:              choreParams.Name,
 		Description:       choreParams.Description,
 		DefaultDurationMn: choreParams.DefaultDurationMn,
+
+		ScheduleKind:         choreParams.ScheduleKind,
+		ScheduleIntervalDays: choreParams.ScheduleIntervalDays,
+		ScheduleWeekdays:     choreParams.ScheduleWeekdays,
+		ScheduleMonthDay:     choreParams.ScheduleMonthDay,
+		Difficulty:           choreParams.Difficulty,
 	}
-	chore, err := r.q.UpdateChore(ctx, params)
+	var chore postgres.Chore
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		chore, err = q.UpdateChore(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventChoreUpdated, Chore(chore))
+	})
 	if err != nil {
-		if sqlErr := chorePgError(err); sqlErr != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Chore{}, ErrNotFound
+		}
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
 			return postgres.Chore{}, sqlErr
 		}
 		return postgres.Chore{}, err
@@ -170,9 +368,94 @@ func (r *Repository) UpdateChore(ctx context.Context, id int32, choreParams post
 }
 
 func (r *Repository) DeleteChore(ctx context.Context, id int32) error {
-	err := r.q.DeleteChore(ctx, id)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		deleted, err := q.DeleteChore(ctx, postgres.DeleteChoreParams{HouseholdID: householdID, ID: id})
+		if err != nil || deleted == 0 {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventChoreDeleted, deletedResource{ID: id})
+	})
+	if err != nil {
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	return nil
+}
+
+// SetChoreArchived archives the chore, or restores it. An archived chore is
+// hidden from the lists and the forms but keeps its tasks.
+func (r *Repository) SetChoreArchived(ctx context.Context, id int32, archived bool) (postgres.Chore, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Chore{}, err
+	}
+	var chore postgres.Chore
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		chore, err = q.SetChoreArchived(ctx, postgres.SetChoreArchivedParams{HouseholdID: householdID, ID: id, Archived: archived})
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventChoreUpdated, Chore(chore))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Chore{}, ErrNotFound
+		}
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
+			return postgres.Chore{}, sqlErr
+		}
+		return postgres.Chore{}, err
+	}
+	return chore, nil
+}
+
+// ReassignAndDeleteChore moves the tasks of the chore to the chore toID, their
+// points following its difficulty, then deletes the chore.
+func (r *Repository) ReassignAndDeleteChore(ctx context.Context, id int32, toID int32) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if id == toID {
+		return fmt.Errorf("%w: can't reassign the tasks of a chore to itself", ErrValidation)
+	}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		to, err := q.GetChore(ctx, postgres.GetChoreParams{HouseholdID: householdID, ID: toID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: chore to reassign the tasks to doesn't exist", ErrValidation)
+			}
+			return err
+		}
+		tasks, err := q.ReassignChoreTasks(ctx, postgres.ReassignChoreTasksParams{
+			ToChoreID:   to.ID,
+			Difficulty:  int64(to.Difficulty),
+			HouseholdID: householdID,
+			FromChoreID: id,
+		})
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err = publishEvent(ctx, q, householdID, EventTaskUpdated, Task(task)); err != nil {
+				return err
+			}
+		}
+		deleted, err := q.DeleteChore(ctx, postgres.DeleteChoreParams{HouseholdID: householdID, ID: id})
+		if err != nil || deleted == 0 {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventChoreDeleted, deletedResource{ID: id})
+	})
 	if err != nil {
-		if sqlErr := chorePgError(err); sqlErr != nil {
+		if sqlErr := chorePgError(ctx, err); sqlErr != nil {
 			return sqlErr
 		}
 		return err
@@ -76,6 +76,12 @@ func (suite *RepositoryTestSuite) SetupSuite() {
 	}
 
 	suite.repository = New(NewRepositoryParams{DB: suite.dbpool})
+
+	household, err := suite.repository.q.CreateHousehold(suite.ctx, "Test")
+	if err != nil {
+		log.Fatalf("unable to create a household: %s", err)
+	}
+	suite.ctx = WithHousehold(suite.ctx, household.ID)
 }
 
 func (suite *RepositoryTestSuite) TearDownSuite() {
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// ChoreUrgency tells how pressing a chore is, based on how long it has been
+// since it was last done compared to how often it is usually done.
+type ChoreUrgency struct {
+	Chore    postgres.Chore
+	LastDone *time.Time
+	Elapsed  time.Duration
+	// Interval is the typical time between two tasks of the chore, derived
+	// from its history or, without enough history, from its schedule. It is
+	// zero when unknown.
+	Interval time.Duration
+	// Urgency is Elapsed divided by Interval: above 1 the chore is late
+	// compared to its habits. It is zero when Interval is unknown.
+	Urgency float64
+}
+
+// typicalInterval returns the average time between two tasks of a chore.
+func typicalInterval(stats postgres.ChoresTasksStatsRow) time.Duration {
+	if stats.TasksCount < 2 {
+		return 0
+	}
+	return stats.LastStartedAt.Sub(stats.FirstStartedAt) / time.Duration(stats.TasksCount-1)
+}
+
+// ComputeUrgencies ranks chores from the most to the least urgent. Chores
+// without a known interval come last, sorted by name.
+func ComputeUrgencies(chores []postgres.Chore, stats []postgres.ChoresTasksStatsRow, now time.Time, location *time.Location) []ChoreUrgency {
+	statsByChore := make(map[int32]postgres.ChoresTasksStatsRow, len(stats))
+	for _, stat := range stats {
+		statsByChore[stat.ChoreID] = stat
+	}
+	urgencies := make([]ChoreUrgency, 0, len(chores))
+	for _, chore := range chores {
+		urgency := ChoreUrgency{Chore: chore}
+		stat, ok := statsByChore[chore.ID]
+		if ok {
+			lastDone := stat.LastStartedAt
+			urgency.LastDone = &lastDone
+			urgency.Elapsed = now.Sub(lastDone)
+			urgency.Interval = typicalInterval(stat)
+			if urgency.Interval == 0 {
+				if due, ok := ChoreSchedule(chore).NextDue(lastDone, location); ok {
+					urgency.Interval = due.Sub(startOfDay(lastDone, location))
+				}
+			}
+			if urgency.Interval > 0 {
+				urgency.Urgency = float64(urgency.Elapsed) / float64(urgency.Interval)
+			}
+		}
+		urgencies = append(urgencies, urgency)
+	}
+	slices.SortStableFunc(urgencies, func(a, b ChoreUrgency) int {
+		if a.Interval > 0 && b.Interval > 0 {
+			return cmp.Compare(b.Urgency, a.Urgency)
+		}
+		if a.Interval > 0 {
+			return -1
+		}
+		if b.Interval > 0 {
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.Chore.Name), strings.ToLower(b.Chore.Name))
+	})
+	return urgencies
+}
+
+func (r *Repository) ListChoresUrgency(ctx context.Context, now time.Time, location *time.Location) ([]ChoreUrgency, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chores, err := r.ListChores(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := r.q.ChoresTasksStats(ctx, householdID)
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return ComputeUrgencies(chores, stats, now, location), nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeUrgencies(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, location)
+	day := 24 * time.Hour
+
+	chores := []postgres.Chore{
+		{ID: 1, Name: "Vacuum"},
+		{ID: 2, Name: "Dishes"},
+		{ID: 3, Name: "Windows"},
+		{ID: 4, Name: "Bins", ScheduleKind: ScheduleInterval, ScheduleIntervalDays: 2},
+		{ID: 5, Name: "Attic"},
+	}
+	stats := []postgres.ChoresTasksStatsRow{
+		// Done every 7 days, last time 3 days ago.
+		{ChoreID: 1, TasksCount: 3, FirstStartedAt: now.Add(-17 * day), LastStartedAt: now.Add(-3 * day)},
+		// Done every day, last time 2 days ago.
+		{ChoreID: 2, TasksCount: 11, FirstStartedAt: now.Add(-12 * day), LastStartedAt: now.Add(-2 * day)},
+		// Done once, no schedule.
+		{ChoreID: 3, TasksCount: 1, FirstStartedAt: now.Add(-30 * day), LastStartedAt: now.Add(-30 * day)},
+		// Done once, falls back to its schedule.
+		{ChoreID: 4, TasksCount: 1, FirstStartedAt: now.Add(-day), LastStartedAt: now.Add(-day)},
+	}
+
+	urgencies := ComputeUrgencies(chores, stats, now, location)
+	names := []string{}
+	for _, urgency := range urgencies {
+		names = append(names, urgency.Chore.Name)
+	}
+	assert.Equal(t, []string{"Dishes", "Bins", "Vacuum", "Attic", "Windows"}, names)
+	assert.InDelta(t, 2.0, urgencies[0].Urgency, 0.01)
+	assert.Equal(t, 7*day, urgencies[2].Interval)
+	assert.Nil(t, urgencies[3].LastDone)
+	assert.Zero(t, urgencies[4].Urgency)
+}
@@ -12,4 +12,24 @@ var (
 	ErrTooSmall      = errors.New("number too small")
 	ErrTooBig        = errors.New("number too big")
 	ErrParseInt      = errors.New("string not containing a number")
+
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAlreadyLinked      = errors.New("already linked")
+	ErrNoHousehold        = errors.New("no household in context")
+
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrInvalidInterval = errors.New("invalid schedule interval")
+	ErrInvalidWeekdays = errors.New("invalid schedule weekdays")
+	ErrInvalidMonthDay = errors.New("invalid schedule day of month")
+
+	ErrInvalidDifficulty = errors.New("invalid difficulty")
+	ErrInvalidShare      = errors.New("invalid share")
+
+	ErrInvalidURL = errors.New("invalid url")
+
+	ErrTimerRunning = errors.New("timer already running")
+	ErrArchived     = errors.New("archived")
+
+	ErrInvalidBackup = errors.New("invalid backup")
+	ErrNotEmpty      = errors.New("database not empty")
 )
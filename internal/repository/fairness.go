@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// UserFairness compares what a user did with their share of what the household
+// did. Balance is positive when the user did more than their share.
+type UserFairness struct {
+	User    string `json:"user"`
+	Share   int32  `json:"share"`
+	Done    int64  `json:"done"`
+	Target  int64  `json:"target"`
+	Balance int64  `json:"balance"`
+}
+
+// FairnessDebt is what a user behind their share owes to a user ahead of it.
+type FairnessDebt struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int64  `json:"amount"`
+}
+
+// FairnessReport is the split of a metric between the users of a household and
+// the debts that would even it out.
+type FairnessReport struct {
+	Metric ReportMetric   `json:"metric"`
+	Total  int64          `json:"total"`
+	Users  []UserFairness `json:"users"`
+	Debts  []FairnessDebt `json:"debts"`
+}
+
+// GenerateFairness splits the metric of the tasks between the users according
+// to their shares, and settles the differences with as few debts as possible
+// by paying the largest surpluses with the largest deficits first. Users
+// without tasks are part of the split, unless they are archived.
+func GenerateFairness(users []User, tasks []TaskReport, metric ReportMetric) FairnessReport {
+	done := make(map[int32]int64, len(users))
+	worked := make(map[int32]bool, len(users))
+	report := FairnessReport{Metric: metric, Users: []UserFairness{}, Debts: []FairnessDebt{}}
+	for _, task := range tasks {
+		done[task.User.ID] += task.Sum(metric)
+		worked[task.User.ID] = true
+		report.Total += task.Sum(metric)
+	}
+	users = slices.DeleteFunc(slices.Clone(users), func(user User) bool {
+		return user.Archived && !worked[user.ID]
+	})
+	slices.SortFunc(users, func(a, b User) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	var shares int64
+	for _, user := range users {
+		shares += int64(user.Share)
+	}
+	if shares == 0 {
+		return report
+	}
+
+	// Targets are rounded down, the rest goes to the largest remainders so
+	// that they add up to the total.
+	remainders := make([]int64, len(users))
+	var assigned int64
+	for index, user := range users {
+		target := report.Total * int64(user.Share) / shares
+		remainders[index] = report.Total * int64(user.Share) % shares
+		assigned += target
+		report.Users = append(report.Users, UserFairness{User: user.Name, Share: user.Share, Done: done[user.ID], Target: target})
+	}
+	order := make([]int, len(users))
+	for index := range order {
+		order[index] = index
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(remainders[b], remainders[a])
+	})
+	for _, index := range order[:report.Total-assigned] {
+		report.Users[index].Target++
+	}
+
+	var debtors, creditors []UserFairness
+	for index := range report.Users {
+		user := &report.Users[index]
+		user.Balance = user.Done - user.Target
+		switch {
+		case user.Balance < 0:
+			debtors = append(debtors, *user)
+		case user.Balance > 0:
+			creditors = append(creditors, *user)
+		}
+	}
+	slices.SortStableFunc(debtors, func(a, b UserFairness) int { return cmp.Compare(a.Balance, b.Balance) })
+	slices.SortStableFunc(creditors, func(a, b UserFairness) int { return cmp.Compare(b.Balance, a.Balance) })
+	for len(debtors) > 0 && len(creditors) > 0 {
+		amount := min(-debtors[0].Balance, creditors[0].Balance)
+		report.Debts = append(report.Debts, FairnessDebt{From: debtors[0].User, To: creditors[0].User, Amount: amount})
+		debtors[0].Balance += amount
+		creditors[0].Balance -= amount
+		if debtors[0].Balance == 0 {
+			debtors = debtors[1:]
+		}
+		if creditors[0].Balance == 0 {
+			creditors = creditors[1:]
+		}
+	}
+	return report
+}
+
+// GetFairnessReport compares what each user did between start and end with
+// their share.
+func (r *Repository) GetFairnessReport(ctx context.Context, start time.Time, end time.Time, metric ReportMetric) (FairnessReport, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return FairnessReport{}, err
+	}
+	users, err := r.q.ListUsers(ctx, householdID)
+	if err != nil {
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
+			return FairnessReport{}, sqlErr
+		}
+		return FairnessReport{}, err
+	}
+	tasks, err := r.taskReports(ctx, householdID, start, end)
+	if err != nil {
+		return FairnessReport{}, err
+	}
+	fairnessUsers := make([]User, len(users))
+	for index, user := range users {
+		fairnessUsers[index] = User(user)
+	}
+	return GenerateFairness(fairnessUsers, tasks, metric), nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateFairness(t *testing.T) {
+	alice := User{ID: 1, Name: "Alice", Share: 1}
+	bob := User{ID: 2, Name: "Bob", Share: 1}
+	carol := User{ID: 3, Name: "carol", Share: 1}
+	dishes := Chore{ID: 1, Name: "Dishes"}
+	laundry := Chore{ID: 2, Name: "Laundry"}
+	tasks := []TaskReport{
+		{User: alice, Chore: dishes, Minutes: 60, Tasks: 3, Points: 60},
+		{User: alice, Chore: laundry, Minutes: 40, Tasks: 1, Points: 120},
+		{User: bob, Chore: dishes, Minutes: 20, Tasks: 1, Points: 20},
+	}
+
+	// Carol did nothing and still owes their share.
+	report := GenerateFairness([]User{carol, bob, alice}, tasks, MetricMinutes)
+	assert.Equal(t, int64(120), report.Total)
+	assert.Equal(t, []UserFairness{
+		{User: "Alice", Share: 1, Done: 100, Target: 40, Balance: 60},
+		{User: "Bob", Share: 1, Done: 20, Target: 40, Balance: -20},
+		{User: "carol", Share: 1, Done: 0, Target: 40, Balance: -40},
+	}, report.Users)
+	assert.Equal(t, []FairnessDebt{
+		{From: "carol", To: "Alice", Amount: 40},
+		{From: "Bob", To: "Alice", Amount: 20},
+	}, report.Debts)
+
+	// A 60/40 split of the points, the targets are rounded to add up to the total.
+	alice.Share = 3
+	bob.Share = 2
+	report = GenerateFairness([]User{alice, bob}, tasks, MetricPoints)
+	assert.Equal(t, MetricPoints, report.Metric)
+	assert.Equal(t, []UserFairness{
+		{User: "Alice", Share: 3, Done: 180, Target: 120, Balance: 60},
+		{User: "Bob", Share: 2, Done: 20, Target: 80, Balance: -60},
+	}, report.Users)
+	report = GenerateFairness([]User{alice, bob}, tasks[1:], MetricTasks)
+	assert.Equal(t, int64(2), report.Users[0].Target+report.Users[1].Target)
+	assert.Equal(t, []FairnessDebt{}, report.Debts)
+
+	// Archived users are only part of the split of a period they did something in.
+	bob.Archived = true
+	carol.Archived = true
+	report = GenerateFairness([]User{alice, bob, carol}, tasks, MetricMinutes)
+	assert.Equal(t, []UserFairness{
+		{User: "Alice", Share: 3, Done: 100, Target: 72, Balance: 28},
+		{User: "Bob", Share: 2, Done: 20, Target: 48, Balance: -28},
+	}, report.Users)
+
+	report = GenerateFairness(nil, nil, MetricMinutes)
+	assert.Empty(t, report.Users)
+	assert.Empty(t, report.Debts)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+)
+
+// Ping checks that the database can be reached.
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
+// SchemaVersion returns the version of the last migration applied to the
+// database, 0 when none was, and whether it failed.
+func (r *Repository) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return r.db.SchemaVersion(ctx)
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+type householdContextKey struct{}
+
+// WithHousehold returns a context scoping every repository call made with it
+// to the given household.
+func WithHousehold(ctx context.Context, householdID int32) context.Context {
+	return context.WithValue(ctx, householdContextKey{}, householdID)
+}
+
+// HouseholdFromContext returns the household set by WithHousehold. Repository
+// calls made without a household fail with ErrNoHousehold so that data can't
+// leak between households.
+func HouseholdFromContext(ctx context.Context) (int32, error) {
+	householdID, ok := ctx.Value(householdContextKey{}).(int32)
+	if !ok {
+		return 0, ErrNoHousehold
+	}
+	return householdID, nil
+}
+
+func householdPgError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case "households_name_check":
+		return fmt.Errorf("%w: invalid household name", ErrInvalidName)
+	}
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught household pg error: %v", pgErr))
+	return fmt.Errorf("%w: %w", ErrSQL, err)
+}
+
+type HouseholdParams struct {
+	Name   string
+	Errors HouseholdParamsError
+}
+
+type HouseholdParamsError struct {
+	Name string
+}
+
+func (r *Repository) ValidateHousehold(householdParams *HouseholdParams) (string, error) {
+	if householdParams.Name == "" {
+		householdParams.Errors.Name = "Name can't be empty"
+		return "", ErrValidation
+	}
+	return householdParams.Name, nil
+}
+
+func (r *Repository) ListHouseholds(ctx context.Context) ([]postgres.Household, error) {
+	households, err := r.q.ListHouseholds(ctx)
+	if err != nil {
+		if sqlErr := householdPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return households, nil
+}
+
+func (r *Repository) GetHousehold(ctx context.Context, id int32) (postgres.Household, error) {
+	household, err := r.q.GetHousehold(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Household{}, ErrNotFound
+		}
+		if sqlErr := householdPgError(ctx, err); sqlErr != nil {
+			return postgres.Household{}, sqlErr
+		}
+		return postgres.Household{}, err
+	}
+	return household, nil
+}
+
+// CreateHousehold creates a household together with its first account.
+func (r *Repository) CreateHousehold(ctx context.Context, name string, account ValidatedAccount) (postgres.Household, postgres.Account, error) {
+	hash, err := hashPassword(account.Password)
+	if err != nil {
+		return postgres.Household{}, postgres.Account{}, err
+	}
+	var household postgres.Household
+	var newAccount postgres.Account
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		household, err = q.CreateHousehold(ctx, name)
+		if err != nil {
+			if sqlErr := householdPgError(ctx, err); sqlErr != nil {
+				return sqlErr
+			}
+			return err
+		}
+		newAccount, err = q.CreateAccount(ctx, postgres.CreateAccountParams{
+			HouseholdID:  household.ID,
+			Username:     account.Username,
+			PasswordHash: hash,
+		})
+		if err != nil {
+			if sqlErr := accountPgError(ctx, err); sqlErr != nil {
+				return sqlErr
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return postgres.Household{}, postgres.Account{}, err
+	}
+	return household, newAccount, nil
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// ImportColumns are the columns read from an imported CSV file, in any order.
+// Other columns, like the ones of an export, are ignored.
+var ImportColumns = []string{"chore", "user", "started_at", "duration_mn", "description"}
+
+// importTimeLayouts are the accepted formats of the start time of an imported
+// task. Times without an offset are read in the configured time zone.
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02/01/2006 15:04",
+}
+
+// ImportRow is a task read from a CSV file.
+type ImportRow struct {
+	// Line is the line of the row in the file, the header being line 1.
+	Line        int
+	Chore       string
+	User        string
+	StartedAt   string
+	DurationMn  string
+	Description string
+	// NewChore and NewUser tell that the chore or user doesn't exist and will
+	// be created.
+	NewChore bool
+	NewUser  bool
+	Errors   []string
+
+	task postgres.CreateTaskParams
+}
+
+type ImportOptions struct {
+	// CreateMissing creates the chores and users that don't exist yet instead
+	// of rejecting the rows referencing them.
+	CreateMissing bool
+	Location      *time.Location
+}
+
+type ImportResult struct {
+	Tasks  int
+	Chores int
+	Users  int
+}
+
+// ParseTasksCSV reads the rows of a CSV file with a header naming the
+// ImportColumns.
+func ParseTasksCSV(reader io.Reader) ([]ImportRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file", ErrValidation)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	columns := make(map[string]int, len(header))
+	for index, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = index
+	}
+	for _, name := range ImportColumns {
+		if _, ok := columns[name]; !ok && name != "description" {
+			return nil, fmt.Errorf("%w: missing column %q", ErrValidation, name)
+		}
+	}
+	field := func(record []string, name string) string {
+		index, ok := columns[name]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+	rows := []ImportRow{}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+		line, _ := csvReader.FieldPos(0)
+		rows = append(rows, ImportRow{
+			Line:        line,
+			Chore:       field(record, "chore"),
+			User:        field(record, "user"),
+			StartedAt:   field(record, "started_at"),
+			DurationMn:  field(record, "duration_mn"),
+			Description: field(record, "description"),
+		})
+	}
+	return rows, nil
+}
+
+func parseImportTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrValidation
+}
+
+// findByName returns the ID of the exact name, or of a name only differing
+// by its case.
+func findByName(ids map[string]int32, name string) (int32, bool) {
+	if id, ok := ids[name]; ok {
+		return id, true
+	}
+	for existing, id := range ids {
+		if strings.EqualFold(existing, name) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateImport maps the chore and user names of the rows to the existing
+// ones and checks every row, filling their Errors. It returns ErrValidation
+// when at least one row is invalid.
+func (r *Repository) ValidateImport(ctx context.Context, rows []ImportRow, options ImportOptions) error {
+	chores, err := r.ListAllChores(ctx)
+	if err != nil {
+		return err
+	}
+	users, err := r.ListAllUsers(ctx)
+	if err != nil {
+		return err
+	}
+	choreIDs := make(map[string]int32, len(chores))
+	for _, chore := range chores {
+		choreIDs[chore.Name] = chore.ID
+	}
+	userIDs := make(map[string]int32, len(users))
+	for _, user := range users {
+		userIDs[user.Name] = user.ID
+	}
+	isErr := false
+	for index := range rows {
+		row := &rows[index]
+		row.Errors = nil
+		row.NewChore, row.NewUser = false, false
+		if row.Chore == "" {
+			row.Errors = append(row.Errors, "Chore can't be empty")
+		} else if id, ok := findByName(choreIDs, row.Chore); ok {
+			row.task.ChoreID = id
+		} else if options.CreateMissing {
+			row.NewChore = true
+		} else {
+			row.Errors = append(row.Errors, fmt.Sprintf("Chore %q not found", row.Chore))
+		}
+		if row.User == "" {
+			row.Errors = append(row.Errors, "User can't be empty")
+		} else if id, ok := findByName(userIDs, row.User); ok {
+			row.task.UserID = id
+		} else if options.CreateMissing {
+			row.NewUser = true
+		} else {
+			row.Errors = append(row.Errors, fmt.Sprintf("User %q not found", row.User))
+		}
+		startedAt, err := parseImportTime(row.StartedAt, options.Location)
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Invalid start time %q", row.StartedAt))
+		}
+		duration, durationErr := r.parseTaskDuration(ctx, row.DurationMn)
+		if durationErr != "" {
+			row.Errors = append(row.Errors, durationErr)
+		}
+		row.task.StartedAt = startedAt
+		row.task.DurationMn = duration
+		row.task.Description = row.Description
+		if len(row.Errors) > 0 {
+			isErr = true
+		}
+	}
+	if isErr {
+		return ErrValidation
+	}
+	return nil
+}
+
+// ImportTasks validates the rows and creates their tasks, and the missing
+// chores and users if asked to, in a single transaction. Nothing is created
+// when a row is invalid. Imported tasks aren't sent to webhooks, a single
+// change is notified for each resource created.
+func (r *Repository) ImportTasks(ctx context.Context, rows []ImportRow, options ImportOptions) (ImportResult, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if err = r.ValidateImport(ctx, rows, options); err != nil {
+		return ImportResult{}, err
+	}
+	result := ImportResult{}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		newChores := map[string]int32{}
+		newUsers := map[string]int32{}
+		for index := range rows {
+			row := &rows[index]
+			if row.NewChore {
+				id, ok := findByName(newChores, row.Chore)
+				if !ok {
+					// New chores take the duration of their first task as default.
+					chore, err := q.CreateChore(ctx, postgres.CreateChoreParams{
+						HouseholdID:       householdID,
+						Name:              row.Chore,
+						DefaultDurationMn: row.task.DurationMn,
+						ScheduleKind:      ScheduleNone,
+						Difficulty:        MinDifficulty,
+					})
+					if err != nil {
+						if sqlErr := chorePgError(ctx, err); sqlErr != nil {
+							return sqlErr
+						}
+						return err
+					}
+					id = chore.ID
+					newChores[chore.Name] = id
+					result.Chores++
+				}
+				row.task.ChoreID = id
+			}
+			if row.NewUser {
+				id, ok := findByName(newUsers, row.User)
+				if !ok {
+					user, err := q.CreateUser(ctx, postgres.CreateUserParams{HouseholdID: householdID, Name: row.User, Share: MinShare})
+					if err != nil {
+						if sqlErr := userPgError(ctx, err); sqlErr != nil {
+							return sqlErr
+						}
+						return err
+					}
+					id = user.ID
+					newUsers[user.Name] = id
+					result.Users++
+				}
+				row.task.UserID = id
+			}
+			row.task.HouseholdID = householdID
+			points, err := taskPoints(ctx, q, householdID, row.task.ChoreID, row.task.DurationMn)
+			if err != nil {
+				return err
+			}
+			row.task.Points = points
+			if _, err := q.CreateTask(ctx, row.task); err != nil {
+				if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+					return fmt.Errorf("line %d: %w", row.Line, sqlErr)
+				}
+				return fmt.Errorf("line %d: %w", row.Line, err)
+			}
+			result.Tasks++
+		}
+		if result.Chores > 0 {
+			if err := notifyChange(ctx, q, householdID, EventChoreCreated); err != nil {
+				return err
+			}
+		}
+		if result.Users > 0 {
+			if err := notifyChange(ctx, q, householdID, EventUserCreated); err != nil {
+				return err
+			}
+		}
+		return notifyChange(ctx, q, householdID, EventTaskCreated)
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
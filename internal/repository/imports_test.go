@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTasksCSV(t *testing.T) {
+	file := "started_at,Chore,user,duration_mn,description\n" +
+		"2023-01-02 08:30,Dishes,Alice,15,\"after the party, finally\"\n" +
+		"2023-01-03T09:00:00+01:00, Vacuum ,Bob,30\n"
+	rows, err := ParseTasksCSV(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportRow{
+		{Line: 2, Chore: "Dishes", User: "Alice", StartedAt: "2023-01-02 08:30", DurationMn: "15", Description: "after the party, finally"},
+		{Line: 3, Chore: "Vacuum", User: "Bob", StartedAt: "2023-01-03T09:00:00+01:00", DurationMn: "30"},
+	}, rows)
+
+	_, err = ParseTasksCSV(strings.NewReader("chore,user,duration_mn\nDishes,Alice,15\n"))
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = ParseTasksCSV(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestParseImportTime(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	want := time.Date(2023, time.January, 2, 8, 30, 0, 0, location)
+	for _, value := range []string{"2023-01-02T08:30", "2023-01-02 08:30", "02/01/2023 08:30", "2023-01-02T07:30:00Z"} {
+		got, err := parseImportTime(value, location)
+		assert.NoError(t, err, value)
+		assert.True(t, want.Equal(got), "%s: want %v, got %v", value, want, got)
+	}
+	_, err = parseImportTime("yesterday", location)
+	assert.Error(t, err)
+}
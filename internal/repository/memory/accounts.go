@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
+	defer q.lock()()
+	return int64(len(q.d.accounts)), nil
+}
+
+func (q *Queries) ListAccounts(ctx context.Context, householdID int32) ([]postgres.Account, error) {
+	defer q.lock()()
+	return rows(q.d.accounts, func(account postgres.Account) bool {
+		return account.HouseholdID == householdID
+	}, func(a, b postgres.Account) int {
+		return cmp.Or(cmp.Compare(a.Username, b.Username), cmp.Compare(a.ID, b.ID))
+	}), nil
+}
+
+func (q *Queries) GetAccount(ctx context.Context, arg postgres.GetAccountParams) (postgres.Account, error) {
+	defer q.lock()()
+	account, ok := q.d.accounts[arg.ID]
+	if !ok || account.HouseholdID != arg.HouseholdID {
+		return postgres.Account{}, pgx.ErrNoRows
+	}
+	return account, nil
+}
+
+func (q *Queries) GetAccountByUsername(ctx context.Context, username string) (postgres.Account, error) {
+	defer q.lock()()
+	for _, account := range q.d.accounts {
+		if account.Username == username {
+			return account, nil
+		}
+	}
+	return postgres.Account{}, pgx.ErrNoRows
+}
+
+func (q *Queries) CreateAccount(ctx context.Context, arg postgres.CreateAccountParams) (postgres.Account, error) {
+	defer q.lock()()
+	q.d.accountsSequence++
+	account := postgres.Account{
+		ID:           q.d.accountsSequence,
+		Username:     arg.Username,
+		PasswordHash: arg.PasswordHash,
+		UserID:       arg.UserID,
+		CreatedAt:    timestamp(time.Now()),
+		HouseholdID:  arg.HouseholdID,
+	}
+	if err := q.d.checkAccount(account); err != nil {
+		return postgres.Account{}, err
+	}
+	q.d.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *Queries) UpdateAccount(ctx context.Context, arg postgres.UpdateAccountParams) (postgres.Account, error) {
+	defer q.lock()()
+	account, ok := q.d.accounts[arg.ID]
+	if !ok || account.HouseholdID != arg.HouseholdID {
+		return postgres.Account{}, pgx.ErrNoRows
+	}
+	account.Username = arg.Username
+	account.UserID = arg.UserID
+	if err := q.d.checkAccount(account); err != nil {
+		return postgres.Account{}, err
+	}
+	q.d.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *Queries) UpdateAccountPassword(ctx context.Context, arg postgres.UpdateAccountPasswordParams) error {
+	defer q.lock()()
+	if account, ok := q.d.accounts[arg.ID]; ok {
+		account.PasswordHash = arg.PasswordHash
+		q.d.accounts[account.ID] = account
+	}
+	return nil
+}
+
+// DeleteAccount deletes the sessions of the account too, like the ON DELETE
+// CASCADE of their foreign key.
+func (q *Queries) DeleteAccount(ctx context.Context, arg postgres.DeleteAccountParams) error {
+	defer q.lock()()
+	account, ok := q.d.accounts[arg.ID]
+	if !ok || account.HouseholdID != arg.HouseholdID {
+		return nil
+	}
+	q.d.deleteSessions(func(session postgres.Session) bool { return session.AccountID == account.ID })
+	delete(q.d.accounts, account.ID)
+	return nil
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg postgres.CreateSessionParams) (postgres.Session, error) {
+	defer q.lock()()
+	if _, ok := q.d.sessions[arg.TokenHash]; ok {
+		return postgres.Session{}, uniqueViolation("sessions", "sessions_pkey")
+	}
+	if _, ok := q.d.accounts[arg.AccountID]; !ok {
+		return postgres.Session{}, foreignKeyViolation("sessions", "sessions_account_id_fkey")
+	}
+	session := postgres.Session{
+		TokenHash: arg.TokenHash,
+		AccountID: arg.AccountID,
+		CreatedAt: timestamp(time.Now()),
+		ExpiresAt: timestamp(arg.ExpiresAt),
+	}
+	q.d.sessions[session.TokenHash] = session
+	return session, nil
+}
+
+func (q *Queries) GetSessionAccount(ctx context.Context, tokenHash string) (postgres.Account, error) {
+	defer q.lock()()
+	session, ok := q.d.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return postgres.Account{}, pgx.ErrNoRows
+	}
+	return q.d.accounts[session.AccountID], nil
+}
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	defer q.lock()()
+	delete(q.d.sessions, tokenHash)
+	return nil
+}
+
+func (q *Queries) DeleteAccountSessions(ctx context.Context, accountID int32) error {
+	defer q.lock()()
+	q.d.deleteSessions(func(session postgres.Session) bool { return session.AccountID == accountID })
+	return nil
+}
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context) error {
+	defer q.lock()()
+	now := time.Now()
+	q.d.deleteSessions(func(session postgres.Session) bool { return !session.ExpiresAt.After(now) })
+	return nil
+}
+
+func (d *data) deleteSessions(matches func(postgres.Session) bool) {
+	for tokenHash, session := range d.sessions {
+		if matches(session) {
+			delete(d.sessions, tokenHash)
+		}
+	}
+}
+
+// checkAccount checks the constraints of the accounts table. The user of an
+// account must be of its household.
+func (d *data) checkAccount(account postgres.Account) error {
+	if account.Username == "" {
+		return checkViolation("accounts", "accounts_username_check")
+	}
+	for _, other := range d.accounts {
+		if other.ID == account.ID {
+			continue
+		}
+		if other.Username == account.Username {
+			return uniqueViolation("accounts", "accounts_username_key")
+		}
+		if account.UserID.Valid && other.UserID == account.UserID {
+			return uniqueViolation("accounts", "accounts_user_id_key")
+		}
+	}
+	if _, ok := d.households[account.HouseholdID]; !ok {
+		return foreignKeyViolation("accounts", "accounts_household_id_fkey")
+	}
+	if account.UserID.Valid {
+		if user, ok := d.users[account.UserID.Int32]; !ok || user.HouseholdID != account.HouseholdID {
+			return foreignKeyViolation("accounts", "accounts_user_id_fkey")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListCalendarFeeds(ctx context.Context, householdID int32) ([]postgres.CalendarFeed, error) {
+	defer q.lock()()
+	return rows(q.d.calendarFeeds, func(feed postgres.CalendarFeed) bool {
+		return feed.HouseholdID == householdID
+	}, func(a, b postgres.CalendarFeed) int {
+		return cmp.Compare(a.ID, b.ID)
+	}), nil
+}
+
+func (q *Queries) GetCalendarFeed(ctx context.Context, tokenHash string) (postgres.CalendarFeed, error) {
+	defer q.lock()()
+	for _, feed := range q.d.calendarFeeds {
+		if feed.TokenHash == tokenHash {
+			return feed, nil
+		}
+	}
+	return postgres.CalendarFeed{}, pgx.ErrNoRows
+}
+
+func (q *Queries) CreateCalendarFeed(ctx context.Context, arg postgres.CreateCalendarFeedParams) (postgres.CalendarFeed, error) {
+	defer q.lock()()
+	q.d.calendarFeedsSequence++
+	feed := postgres.CalendarFeed{
+		ID:          q.d.calendarFeedsSequence,
+		HouseholdID: arg.HouseholdID,
+		UserID:      arg.UserID,
+		TokenHash:   arg.TokenHash,
+		CreatedAt:   timestamp(time.Now()),
+	}
+	for _, other := range q.d.calendarFeeds {
+		if other.TokenHash == feed.TokenHash {
+			return postgres.CalendarFeed{}, uniqueViolation("calendar_feeds", "calendar_feeds_token_hash_key")
+		}
+	}
+	if feed.UserID.Valid {
+		if user, ok := q.d.users[feed.UserID.Int32]; !ok || user.HouseholdID != feed.HouseholdID {
+			return postgres.CalendarFeed{}, foreignKeyViolation("calendar_feeds", "calendar_feeds_user_id_fkey")
+		}
+	}
+	q.d.calendarFeeds[feed.ID] = feed
+	return feed, nil
+}
+
+func (q *Queries) DeleteCalendarFeed(ctx context.Context, arg postgres.DeleteCalendarFeedParams) (int64, error) {
+	defer q.lock()()
+	feed, ok := q.d.calendarFeeds[arg.ID]
+	if !ok || feed.HouseholdID != arg.HouseholdID {
+		return 0, nil
+	}
+	delete(q.d.calendarFeeds, feed.ID)
+	return 1, nil
+}
@@ -0,0 +1,8 @@
+package memory
+
+import "context"
+
+func (q *Queries) NotifyChange(ctx context.Context, payload string) error {
+	q.notify(payload)
+	return nil
+}
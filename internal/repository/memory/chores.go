@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListChores(ctx context.Context, householdID int32) ([]postgres.Chore, error) {
+	defer q.lock()()
+	return rows(q.d.chores, func(chore postgres.Chore) bool {
+		return chore.HouseholdID == householdID
+	}, compareChores), nil
+}
+
+func (q *Queries) GetChore(ctx context.Context, arg postgres.GetChoreParams) (postgres.Chore, error) {
+	defer q.lock()()
+	chore, ok := q.d.chores[arg.ID]
+	if !ok || chore.HouseholdID != arg.HouseholdID {
+		return postgres.Chore{}, pgx.ErrNoRows
+	}
+	return chore, nil
+}
+
+func (q *Queries) CreateChore(ctx context.Context, arg postgres.CreateChoreParams) (postgres.Chore, error) {
+	defer q.lock()()
+	q.d.choresSequence++
+	chore := postgres.Chore{
+		ID:                   q.d.choresSequence,
+		Name:                 arg.Name,
+		Description:          arg.Description,
+		DefaultDurationMn:    arg.DefaultDurationMn,
+		HouseholdID:          arg.HouseholdID,
+		ScheduleKind:         arg.ScheduleKind,
+		ScheduleIntervalDays: arg.ScheduleIntervalDays,
+		ScheduleWeekdays:     arg.ScheduleWeekdays,
+		ScheduleMonthDay:     arg.ScheduleMonthDay,
+		Difficulty:           arg.Difficulty,
+	}
+	if err := q.d.insertChore(chore); err != nil {
+		return postgres.Chore{}, err
+	}
+	return chore, nil
+}
+
+func (q *Queries) UpdateChore(ctx context.Context, arg postgres.UpdateChoreParams) (postgres.Chore, error) {
+	defer q.lock()()
+	chore, ok := q.d.chores[arg.ID]
+	if !ok || chore.HouseholdID != arg.HouseholdID {
+		return postgres.Chore{}, pgx.ErrNoRows
+	}
+	chore.Name = arg.Name
+	chore.Description = arg.Description
+	chore.DefaultDurationMn = arg.DefaultDurationMn
+	chore.ScheduleKind = arg.ScheduleKind
+	chore.ScheduleIntervalDays = arg.ScheduleIntervalDays
+	chore.ScheduleWeekdays = arg.ScheduleWeekdays
+	chore.ScheduleMonthDay = arg.ScheduleMonthDay
+	chore.Difficulty = arg.Difficulty
+	if err := q.d.checkChore(chore); err != nil {
+		return postgres.Chore{}, err
+	}
+	q.d.chores[chore.ID] = chore
+	return chore, nil
+}
+
+func (q *Queries) SetChoreArchived(ctx context.Context, arg postgres.SetChoreArchivedParams) (postgres.Chore, error) {
+	defer q.lock()()
+	chore, ok := q.d.chores[arg.ID]
+	if !ok || chore.HouseholdID != arg.HouseholdID {
+		return postgres.Chore{}, pgx.ErrNoRows
+	}
+	chore.Archived = arg.Archived
+	q.d.chores[chore.ID] = chore
+	return chore, nil
+}
+
+func (q *Queries) ReassignChoreTasks(ctx context.Context, arg postgres.ReassignChoreTasksParams) ([]postgres.Task, error) {
+	defer q.lock()()
+	tasks := rows(q.d.tasks, func(task postgres.Task) bool {
+		return task.HouseholdID == arg.HouseholdID && task.ChoreID == arg.FromChoreID
+	}, compareTasks)
+	for i := range tasks {
+		tasks[i].ChoreID = arg.ToChoreID
+		tasks[i].Points = int64(tasks[i].DurationMn) * arg.Difficulty
+		if err := q.d.checkTask(tasks[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range tasks {
+		q.d.tasks[task.ID] = task
+	}
+	return tasks, nil
+}
+
+// DeleteChore deletes the timers of the chore too, like the ON DELETE CASCADE
+// of their foreign key.
+func (q *Queries) DeleteChore(ctx context.Context, arg postgres.DeleteChoreParams) (int64, error) {
+	defer q.lock()()
+	chore, ok := q.d.chores[arg.ID]
+	if !ok || chore.HouseholdID != arg.HouseholdID {
+		return 0, nil
+	}
+	for _, task := range q.d.tasks {
+		if task.ChoreID == chore.ID {
+			return 0, foreignKeyViolation("tasks", "tasks_chore_id_fkey")
+		}
+	}
+	for id, timer := range q.d.timers {
+		if timer.ChoreID == chore.ID {
+			delete(q.d.timers, id)
+		}
+	}
+	delete(q.d.chores, chore.ID)
+	return 1, nil
+}
+
+func (q *Queries) ChoresTasksStats(ctx context.Context, householdID int32) ([]postgres.ChoresTasksStatsRow, error) {
+	defer q.lock()()
+	stats := map[int32]postgres.ChoresTasksStatsRow{}
+	for _, task := range q.d.tasks {
+		if task.HouseholdID != householdID {
+			continue
+		}
+		stat, ok := stats[task.ChoreID]
+		if !ok {
+			stat = postgres.ChoresTasksStatsRow{ChoreID: task.ChoreID, FirstStartedAt: task.StartedAt, LastStartedAt: task.StartedAt}
+		}
+		stat.TasksCount++
+		if task.StartedAt.Before(stat.FirstStartedAt) {
+			stat.FirstStartedAt = task.StartedAt
+		}
+		if task.StartedAt.After(stat.LastStartedAt) {
+			stat.LastStartedAt = task.StartedAt
+		}
+		stats[task.ChoreID] = stat
+	}
+	return rows(stats, func(postgres.ChoresTasksStatsRow) bool { return true }, func(a, b postgres.ChoresTasksStatsRow) int {
+		return cmp.Compare(a.ChoreID, b.ChoreID)
+	}), nil
+}
+
+func (q *Queries) RestoreChore(ctx context.Context, arg postgres.RestoreChoreParams) error {
+	defer q.lock()()
+	return q.d.insertChore(postgres.Chore{
+		ID:                   arg.ID,
+		Name:                 arg.Name,
+		Description:          arg.Description,
+		DefaultDurationMn:    arg.DefaultDurationMn,
+		HouseholdID:          arg.HouseholdID,
+		ScheduleKind:         arg.ScheduleKind,
+		ScheduleIntervalDays: arg.ScheduleIntervalDays,
+		ScheduleWeekdays:     arg.ScheduleWeekdays,
+		ScheduleMonthDay:     arg.ScheduleMonthDay,
+		Difficulty:           arg.Difficulty,
+		Archived:             arg.Archived,
+	})
+}
+
+func (q *Queries) ResetChoresSequence(ctx context.Context) error {
+	defer q.lock()()
+	q.d.choresSequence = maxID(q.d.chores)
+	return nil
+}
+
+func compareChores(a, b postgres.Chore) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+}
+
+// checkChore checks the constraints of the chores table, in the order
+// PostgreSQL does: check constraints, unique constraints then foreign keys.
+func (d *data) checkChore(chore postgres.Chore) error {
+	switch {
+	case chore.Name == "":
+		return checkViolation("chores", "chores_name_check")
+	case !slices.Contains([]string{"none", "interval", "weekly", "monthly"}, chore.ScheduleKind):
+		return checkViolation("chores", "chores_schedule_kind_check")
+	case chore.ScheduleIntervalDays < 0:
+		return checkViolation("chores", "chores_schedule_interval_days_check")
+	case chore.ScheduleWeekdays < 0 || chore.ScheduleWeekdays > 127:
+		return checkViolation("chores", "chores_schedule_weekdays_check")
+	case chore.ScheduleMonthDay < 0 || chore.ScheduleMonthDay > 31:
+		return checkViolation("chores", "chores_schedule_month_day_check")
+	case chore.Difficulty < 1 || chore.Difficulty > 10:
+		return checkViolation("chores", "chores_difficulty_check")
+	}
+	for _, other := range d.chores {
+		if other.ID != chore.ID && other.HouseholdID == chore.HouseholdID && other.Name == chore.Name {
+			return uniqueViolation("chores", "chores_household_id_name_key")
+		}
+	}
+	if _, ok := d.households[chore.HouseholdID]; !ok {
+		return foreignKeyViolation("chores", "chores_household_id_fkey")
+	}
+	return nil
+}
+
+func (d *data) insertChore(chore postgres.Chore) error {
+	if _, ok := d.chores[chore.ID]; ok {
+		return uniqueViolation("chores", "chores_pkey")
+	}
+	if err := d.checkChore(chore); err != nil {
+		return err
+	}
+	d.chores[chore.ID] = chore
+	return nil
+}
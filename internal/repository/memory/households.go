@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListHouseholds(ctx context.Context) ([]postgres.Household, error) {
+	defer q.lock()()
+	return rows(q.d.households, func(postgres.Household) bool { return true }, func(a, b postgres.Household) int {
+		return cmp.Compare(a.ID, b.ID)
+	}), nil
+}
+
+func (q *Queries) GetHousehold(ctx context.Context, id int32) (postgres.Household, error) {
+	defer q.lock()()
+	household, ok := q.d.households[id]
+	if !ok {
+		return postgres.Household{}, pgx.ErrNoRows
+	}
+	return household, nil
+}
+
+func (q *Queries) CreateHousehold(ctx context.Context, name string) (postgres.Household, error) {
+	defer q.lock()()
+	q.d.householdsSequence++
+	household := postgres.Household{ID: q.d.householdsSequence, Name: name, CreatedAt: timestamp(time.Now())}
+	return household, q.d.insertHousehold(household)
+}
+
+func (q *Queries) UpdateHousehold(ctx context.Context, arg postgres.UpdateHouseholdParams) (postgres.Household, error) {
+	defer q.lock()()
+	household, ok := q.d.households[arg.ID]
+	if !ok {
+		return postgres.Household{}, pgx.ErrNoRows
+	}
+	household.Name = arg.Name
+	if err := checkHousehold(household); err != nil {
+		return postgres.Household{}, err
+	}
+	q.d.households[household.ID] = household
+	return household, nil
+}
+
+func (q *Queries) CountHouseholds(ctx context.Context) (int64, error) {
+	defer q.lock()()
+	return int64(len(q.d.households)), nil
+}
+
+func (q *Queries) RestoreHousehold(ctx context.Context, arg postgres.RestoreHouseholdParams) error {
+	defer q.lock()()
+	return q.d.insertHousehold(postgres.Household{ID: arg.ID, Name: arg.Name, CreatedAt: timestamp(arg.CreatedAt)})
+}
+
+func (q *Queries) ResetHouseholdsSequence(ctx context.Context) error {
+	defer q.lock()()
+	q.d.householdsSequence = maxID(q.d.households)
+	return nil
+}
+
+func checkHousehold(household postgres.Household) error {
+	if household.Name == "" {
+		return checkViolation("households", "households_name_check")
+	}
+	return nil
+}
+
+func (d *data) insertHousehold(household postgres.Household) error {
+	if err := checkHousehold(household); err != nil {
+		return err
+	}
+	if _, ok := d.households[household.ID]; ok {
+		return uniqueViolation("households", "households_pkey")
+	}
+	d.households[household.ID] = household
+	return nil
+}
@@ -0,0 +1,229 @@
+// Package memory keeps the data of the repository in memory, for the tests
+// that don't need a real database.
+//
+// Its queries behave like the ones sqlc generates for PostgreSQL in the
+// postgres package, constraints included: they fail with pgx.ErrNoRows when no
+// row is found and with a *pgconn.PgError named after the PostgreSQL
+// constraint that was violated.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// data is the content of the tables, and the last ID given by their
+// sequences.
+type data struct {
+	households map[int32]postgres.Household
+	chores     map[int32]postgres.Chore
+	users      map[int32]postgres.User
+	tasks      map[uuid.UUID]postgres.Task
+	accounts   map[int32]postgres.Account
+	sessions   map[string]postgres.Session
+	webhooks   map[int32]postgres.Webhook
+	deliveries map[uuid.UUID]postgres.WebhookDelivery
+	timers     map[int32]postgres.Timer
+	// calendarFeeds are keyed by ID, their token hashes are unique.
+	calendarFeeds map[int32]postgres.CalendarFeed
+
+	householdsSequence    int32
+	choresSequence        int32
+	usersSequence         int32
+	accountsSequence      int32
+	webhooksSequence      int32
+	timersSequence        int32
+	calendarFeedsSequence int32
+}
+
+func newData() *data {
+	return &data{
+		households: map[int32]postgres.Household{},
+		chores:     map[int32]postgres.Chore{},
+		users:      map[int32]postgres.User{},
+		tasks:      map[uuid.UUID]postgres.Task{},
+		accounts:   map[int32]postgres.Account{},
+		sessions:   map[string]postgres.Session{},
+		webhooks:   map[int32]postgres.Webhook{},
+		deliveries: map[uuid.UUID]postgres.WebhookDelivery{},
+		timers:     map[int32]postgres.Timer{},
+
+		calendarFeeds: map[int32]postgres.CalendarFeed{},
+	}
+}
+
+// clone copies the tables. The rows are never modified in place, they can be
+// shared.
+func (d *data) clone() *data {
+	c := *d
+	c.households = maps.Clone(d.households)
+	c.chores = maps.Clone(d.chores)
+	c.users = maps.Clone(d.users)
+	c.tasks = maps.Clone(d.tasks)
+	c.accounts = maps.Clone(d.accounts)
+	c.sessions = maps.Clone(d.sessions)
+	c.webhooks = maps.Clone(d.webhooks)
+	c.deliveries = maps.Clone(d.deliveries)
+	c.timers = maps.Clone(d.timers)
+	c.calendarFeeds = maps.Clone(d.calendarFeeds)
+	return &c
+}
+
+// Queries runs the queries on the data of a store or of a transaction.
+type Queries struct {
+	d *data
+	// mu is the lock of the store, nil in a transaction which already holds
+	// it.
+	mu *sync.Mutex
+	// notify sends a change to the listeners, once committed in a
+	// transaction.
+	notify func(payload string)
+}
+
+func (q *Queries) lock() func() {
+	if q.mu == nil {
+		return func() {}
+	}
+	q.mu.Lock()
+	return q.mu.Unlock
+}
+
+// Store is an empty database kept in memory. Transactions run one at a time,
+// on a copy of the data that replaces it when they are committed.
+type Store struct {
+	*Queries
+	mu        sync.Mutex
+	listeners database.Listeners
+}
+
+func New() *Store {
+	s := &Store{}
+	s.Queries = &Queries{d: newData(), mu: &s.mu, notify: s.listeners.Notify}
+	return s
+}
+
+// InTx runs fn with queries bound to a transaction, committed when fn
+// succeeds. The changes notified are sent once committed.
+func (s *Store) InTx(ctx context.Context, fn func(q postgres.Querier) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.d.clone()
+	var notified []string
+	notify := func(payload string) { notified = append(notified, payload) }
+	if err := fn(&Queries{d: d, notify: notify}); err != nil {
+		return err
+	}
+	*s.d = *d
+	for _, payload := range notified {
+		s.listeners.Notify(payload)
+	}
+	return nil
+}
+
+// InSnapshot runs fn on a copy of the data, whose changes are discarded.
+func (s *Store) InSnapshot(ctx context.Context, fn func(q postgres.Querier) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(&Queries{d: s.d.clone(), notify: func(string) {}})
+}
+
+// Listen calls notify with the changes notified to the store until ctx is
+// canceled.
+func (s *Store) Listen(ctx context.Context, notify func(payload string)) error {
+	return s.listeners.Listen(ctx, notify)
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+// SchemaVersion returns the version of the last PostgreSQL migration: the
+// store has the schema of an up to date database.
+func (s *Store) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	version, err := database.LatestVersion("postgres")
+	return version, false, err
+}
+
+// timestamp gives a time the precision and location of the times read from
+// PostgreSQL.
+func timestamp(t time.Time) time.Time {
+	return t.Truncate(time.Microsecond).Local()
+}
+
+func uniqueViolation(table string, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func checkViolation(table string, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23514",
+		Message:        fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func foreignKeyViolation(table string, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        fmt.Sprintf("insert, update or delete on table %q violates foreign key constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+// rows returns the rows of a table kept by keep, sorted by compare.
+func rows[K comparable, T any](table map[K]T, keep func(T) bool, compare func(a, b T) int) []T {
+	var items []T
+	for _, item := range table {
+		if keep(item) {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, compare)
+	return items
+}
+
+// limit returns at most n items.
+func limit[T any](items []T, n int32) []T {
+	if n >= 0 && len(items) > int(n) {
+		return items[:n]
+	}
+	return items
+}
+
+// maxID returns the largest key of a table, 0 when empty, where its sequence
+// is reset to after a restore.
+func maxID[T any](table map[int32]T) int32 {
+	var id int32
+	for key := range table {
+		id = max(id, key)
+	}
+	return id
+}
+
+func compareTime(a, b time.Time) int {
+	return a.Compare(b)
+}
+
+func compareUUID(a, b uuid.UUID) int {
+	return cmp.Compare(a.String(), b.String())
+}
@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.Store { return New() })
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListTasks(ctx context.Context, householdID int32) ([]postgres.Task, error) {
+	defer q.lock()()
+	return rows(q.d.tasks, func(task postgres.Task) bool {
+		return task.HouseholdID == householdID
+	}, compareTasks), nil
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg postgres.GetTaskParams) (postgres.Task, error) {
+	defer q.lock()()
+	task, ok := q.d.tasks[arg.ID]
+	if !ok || task.HouseholdID != arg.HouseholdID {
+		return postgres.Task{}, pgx.ErrNoRows
+	}
+	return task, nil
+}
+
+func (q *Queries) CreateTask(ctx context.Context, arg postgres.CreateTaskParams) (postgres.Task, error) {
+	defer q.lock()()
+	task := postgres.Task{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		ChoreID:     arg.ChoreID,
+		StartedAt:   timestamp(arg.StartedAt),
+		DurationMn:  arg.DurationMn,
+		Description: arg.Description,
+		HouseholdID: arg.HouseholdID,
+		Points:      arg.Points,
+	}
+	if err := q.d.insertTask(task); err != nil {
+		return postgres.Task{}, err
+	}
+	return task, nil
+}
+
+func (q *Queries) UpdateTask(ctx context.Context, arg postgres.UpdateTaskParams) (postgres.Task, error) {
+	defer q.lock()()
+	task, ok := q.d.tasks[arg.ID]
+	if !ok || task.HouseholdID != arg.HouseholdID {
+		return postgres.Task{}, pgx.ErrNoRows
+	}
+	task.UserID = arg.UserID
+	task.ChoreID = arg.ChoreID
+	task.StartedAt = timestamp(arg.StartedAt)
+	task.DurationMn = arg.DurationMn
+	task.Description = arg.Description
+	task.Points = arg.Points
+	if err := q.d.checkTask(task); err != nil {
+		return postgres.Task{}, err
+	}
+	q.d.tasks[task.ID] = task
+	return task, nil
+}
+
+func (q *Queries) DeleteTask(ctx context.Context, arg postgres.DeleteTaskParams) (int64, error) {
+	defer q.lock()()
+	task, ok := q.d.tasks[arg.ID]
+	if !ok || task.HouseholdID != arg.HouseholdID {
+		return 0, nil
+	}
+	delete(q.d.tasks, task.ID)
+	return 1, nil
+}
+
+func (q *Queries) GetUserTasks(ctx context.Context, arg postgres.GetUserTasksParams) ([]postgres.GetUserTasksRow, error) {
+	defer q.lock()()
+	var items []postgres.GetUserTasksRow
+	for _, task := range q.d.householdTasks(arg.HouseholdID) {
+		if task.UserID == arg.ID {
+			items = append(items, postgres.GetUserTasksRow{Task: task, Chore: q.d.chores[task.ChoreID]})
+		}
+	}
+	return items, nil
+}
+
+func (q *Queries) GetChoreTasks(ctx context.Context, arg postgres.GetChoreTasksParams) ([]postgres.GetChoreTasksRow, error) {
+	defer q.lock()()
+	var items []postgres.GetChoreTasksRow
+	for _, task := range q.d.householdTasks(arg.HouseholdID) {
+		if task.ChoreID == arg.ChoreID {
+			items = append(items, postgres.GetChoreTasksRow{Task: task, User: q.d.users[task.UserID]})
+		}
+	}
+	return items, nil
+}
+
+func (q *Queries) ListUsersTasks(ctx context.Context, householdID int32) ([]postgres.ListUsersTasksRow, error) {
+	defer q.lock()()
+	var items []postgres.ListUsersTasksRow
+	for _, task := range q.d.householdTasks(householdID) {
+		items = append(items, postgres.ListUsersTasksRow{Task: task, Chore: q.d.chores[task.ChoreID], User: q.d.users[task.UserID]})
+	}
+	return items, nil
+}
+
+func (q *Queries) ListUsersTasksBetween(ctx context.Context, arg postgres.ListUsersTasksBetweenParams) ([]postgres.ListUsersTasksBetweenRow, error) {
+	defer q.lock()()
+	var items []postgres.ListUsersTasksBetweenRow
+	for _, task := range q.d.householdTasks(arg.HouseholdID) {
+		if (arg.UserID != 0 && task.UserID != arg.UserID) || task.StartedAt.Before(arg.NotBefore) || task.StartedAt.After(arg.NotAfter) {
+			continue
+		}
+		items = append(items, postgres.ListUsersTasksBetweenRow{Task: task, Chore: q.d.chores[task.ChoreID], User: q.d.users[task.UserID]})
+	}
+	return items, nil
+}
+
+// SearchTasks sorts the tasks on the key of arg.Sort, then on their start
+// time and ID, like the PostgreSQL query.
+func (q *Queries) SearchTasks(ctx context.Context, arg postgres.SearchTasksParams) ([]postgres.SearchTasksRow, error) {
+	defer q.lock()()
+	search := strings.ToLower(arg.Search)
+	compare := func(a, b postgres.Task) int {
+		return cmp.Or(cmp.Compare(taskSortKey(a, arg.Sort), taskSortKey(b, arg.Sort)), compareTasks(a, b))
+	}
+	after := postgres.Task{ID: arg.AfterID, StartedAt: arg.AfterStartedAt}
+	tasks := rows(q.d.tasks, func(task postgres.Task) bool {
+		if task.HouseholdID != arg.HouseholdID ||
+			(arg.UserID != 0 && task.UserID != arg.UserID) ||
+			(arg.ChoreID != 0 && task.ChoreID != arg.ChoreID) ||
+			task.StartedAt.Before(arg.NotBefore) || !task.StartedAt.Before(arg.NotAfter) ||
+			!strings.Contains(strings.ToLower(task.Description), search) {
+			return false
+		}
+		if !arg.After {
+			return true
+		}
+		order := cmp.Or(cmp.Compare(taskSortKey(task, arg.Sort), arg.AfterKey), compareTasks(task, after))
+		if arg.Descending {
+			return order < 0
+		}
+		return order > 0
+	}, func(a, b postgres.Task) int {
+		if arg.Descending {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	var items []postgres.SearchTasksRow
+	for _, task := range limit(tasks, arg.MaxTasks) {
+		items = append(items, postgres.SearchTasksRow{Task: task, Chore: q.d.chores[task.ChoreID], User: q.d.users[task.UserID]})
+	}
+	return items, nil
+}
+
+// taskSortKey returns the value a task is sorted on before its start time.
+func taskSortKey(task postgres.Task, sort string) int64 {
+	switch sort {
+	case "duration":
+		return int64(task.DurationMn)
+	case "points":
+		return task.Points
+	}
+	return 0
+}
+
+func (q *Queries) TasksReport(ctx context.Context, arg postgres.TasksReportParams) ([]postgres.TasksReportRow, error) {
+	defer q.lock()()
+	type key struct{ choreID, userID int32 }
+	sums := map[key]postgres.TasksReportRow{}
+	for _, task := range q.d.tasks {
+		if task.HouseholdID != arg.HouseholdID || !task.StartedAt.After(arg.NotBefore) || !task.StartedAt.Before(arg.NotAfter) {
+			continue
+		}
+		k := key{task.ChoreID, task.UserID}
+		row, ok := sums[k]
+		if !ok {
+			row = postgres.TasksReportRow{User: q.d.users[task.UserID], Chore: q.d.chores[task.ChoreID]}
+		}
+		row.Minutes += int64(task.DurationMn)
+		row.TasksCount++
+		row.Points += task.Points
+		sums[k] = row
+	}
+	return rows(sums, func(postgres.TasksReportRow) bool { return true }, func(a, b postgres.TasksReportRow) int {
+		return cmp.Or(cmp.Compare(a.Chore.ID, b.Chore.ID), cmp.Compare(a.User.ID, b.User.ID))
+	}), nil
+}
+
+func (q *Queries) TasksTimeline(ctx context.Context, arg postgres.TasksTimelineParams) ([]postgres.TasksTimelineRow, error) {
+	defer q.lock()()
+	location, err := time.LoadLocation(arg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", arg.TimeZone, err)
+	}
+	type key struct {
+		userID int32
+		period time.Time
+	}
+	sums := map[key]postgres.TasksTimelineRow{}
+	for _, task := range q.d.tasks {
+		if task.HouseholdID != arg.HouseholdID || !task.StartedAt.After(arg.NotBefore) || !task.StartedAt.Before(arg.NotAfter) {
+			continue
+		}
+		period, err := database.DateTrunc(arg.Unit, task.StartedAt, location)
+		if err != nil {
+			return nil, err
+		}
+		k := key{task.UserID, period}
+		row, ok := sums[k]
+		if !ok {
+			row = postgres.TasksTimelineRow{User: q.d.users[task.UserID], Period: period}
+		}
+		row.Minutes += int64(task.DurationMn)
+		row.TasksCount++
+		row.Points += task.Points
+		sums[k] = row
+	}
+	return rows(sums, func(postgres.TasksTimelineRow) bool { return true }, func(a, b postgres.TasksTimelineRow) int {
+		return cmp.Or(a.Period.Compare(b.Period), cmp.Compare(a.User.ID, b.User.ID))
+	}), nil
+}
+
+func (q *Queries) RestoreTask(ctx context.Context, arg postgres.RestoreTaskParams) error {
+	defer q.lock()()
+	return q.d.insertTask(postgres.Task{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		ChoreID:     arg.ChoreID,
+		StartedAt:   timestamp(arg.StartedAt),
+		DurationMn:  arg.DurationMn,
+		Description: arg.Description,
+		HouseholdID: arg.HouseholdID,
+		Points:      arg.Points,
+	})
+}
+
+func compareTasks(a, b postgres.Task) int {
+	return cmp.Or(compareTime(a.StartedAt, b.StartedAt), compareUUID(a.ID, b.ID))
+}
+
+// householdTasks returns the tasks of a household, the last started first.
+func (d *data) householdTasks(householdID int32) []postgres.Task {
+	return rows(d.tasks, func(task postgres.Task) bool {
+		return task.HouseholdID == householdID
+	}, func(a, b postgres.Task) int {
+		return compareTasks(b, a)
+	})
+}
+
+// checkTask checks the constraints of the tasks table: its points can't be
+// negative and its chore and user are of its household, like the foreign keys.
+func (d *data) checkTask(task postgres.Task) error {
+	if task.Points < 0 {
+		return checkViolation("tasks", "tasks_points_check")
+	}
+	if _, ok := d.households[task.HouseholdID]; !ok {
+		return foreignKeyViolation("tasks", "tasks_household_id_fkey")
+	}
+	if user, ok := d.users[task.UserID]; !ok || user.HouseholdID != task.HouseholdID {
+		return foreignKeyViolation("tasks", "tasks_user_id_fkey")
+	}
+	if chore, ok := d.chores[task.ChoreID]; !ok || chore.HouseholdID != task.HouseholdID {
+		return foreignKeyViolation("tasks", "tasks_chore_id_fkey")
+	}
+	return nil
+}
+
+func (d *data) insertTask(task postgres.Task) error {
+	if _, ok := d.tasks[task.ID]; ok {
+		return uniqueViolation("tasks", "tasks_pkey")
+	}
+	if err := d.checkTask(task); err != nil {
+		return err
+	}
+	d.tasks[task.ID] = task
+	return nil
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListTimers(ctx context.Context, householdID int32) ([]postgres.ListTimersRow, error) {
+	defer q.lock()()
+	var items []postgres.ListTimersRow
+	for _, timer := range rows(q.d.timers, func(timer postgres.Timer) bool {
+		return timer.HouseholdID == householdID
+	}, func(a, b postgres.Timer) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ID, b.ID))
+	}) {
+		items = append(items, postgres.ListTimersRow{Timer: timer, Chore: q.d.chores[timer.ChoreID], User: q.d.users[timer.UserID]})
+	}
+	return items, nil
+}
+
+func (q *Queries) GetTimer(ctx context.Context, arg postgres.GetTimerParams) (postgres.Timer, error) {
+	defer q.lock()()
+	timer, ok := q.d.timers[arg.ID]
+	if !ok || timer.HouseholdID != arg.HouseholdID {
+		return postgres.Timer{}, pgx.ErrNoRows
+	}
+	return timer, nil
+}
+
+// GetTimerForUpdate needs no lock: the transactions of the store already run
+// one at a time.
+func (q *Queries) GetTimerForUpdate(ctx context.Context, arg postgres.GetTimerForUpdateParams) (postgres.Timer, error) {
+	return q.GetTimer(ctx, postgres.GetTimerParams(arg))
+}
+
+func (q *Queries) CreateTimer(ctx context.Context, arg postgres.CreateTimerParams) (postgres.Timer, error) {
+	defer q.lock()()
+	q.d.timersSequence++
+	timer := postgres.Timer{
+		ID:          q.d.timersSequence,
+		HouseholdID: arg.HouseholdID,
+		UserID:      arg.UserID,
+		ChoreID:     arg.ChoreID,
+		StartedAt:   timestamp(arg.StartedAt),
+		ResumedAt:   pgtype.Timestamptz{Time: timestamp(arg.StartedAt), Valid: true},
+	}
+	if err := q.d.checkTimer(timer); err != nil {
+		return postgres.Timer{}, err
+	}
+	q.d.timers[timer.ID] = timer
+	return timer, nil
+}
+
+func (q *Queries) UpdateTimer(ctx context.Context, arg postgres.UpdateTimerParams) (postgres.Timer, error) {
+	defer q.lock()()
+	timer, ok := q.d.timers[arg.ID]
+	if !ok || timer.HouseholdID != arg.HouseholdID {
+		return postgres.Timer{}, pgx.ErrNoRows
+	}
+	timer.ResumedAt = arg.ResumedAt
+	if timer.ResumedAt.Valid {
+		timer.ResumedAt.Time = timestamp(timer.ResumedAt.Time)
+	}
+	timer.ElapsedSeconds = arg.ElapsedSeconds
+	if err := q.d.checkTimer(timer); err != nil {
+		return postgres.Timer{}, err
+	}
+	q.d.timers[timer.ID] = timer
+	return timer, nil
+}
+
+func (q *Queries) DeleteTimer(ctx context.Context, arg postgres.DeleteTimerParams) (int64, error) {
+	defer q.lock()()
+	timer, ok := q.d.timers[arg.ID]
+	if !ok || timer.HouseholdID != arg.HouseholdID {
+		return 0, nil
+	}
+	delete(q.d.timers, timer.ID)
+	return 1, nil
+}
+
+// checkTimer checks the constraints of the timers table: a single timer per
+// user, and its chore and user are of its household, like the foreign keys.
+func (d *data) checkTimer(timer postgres.Timer) error {
+	if timer.ElapsedSeconds < 0 {
+		return checkViolation("timers", "timers_elapsed_seconds_check")
+	}
+	for _, other := range d.timers {
+		if other.ID != timer.ID && other.UserID == timer.UserID {
+			return uniqueViolation("timers", "timers_user_id_key")
+		}
+	}
+	if user, ok := d.users[timer.UserID]; !ok || user.HouseholdID != timer.HouseholdID {
+		return foreignKeyViolation("timers", "timers_user_id_fkey")
+	}
+	if chore, ok := d.chores[timer.ChoreID]; !ok || chore.HouseholdID != timer.HouseholdID {
+		return foreignKeyViolation("timers", "timers_chore_id_fkey")
+	}
+	return nil
+}
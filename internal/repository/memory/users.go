@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListUsers(ctx context.Context, householdID int32) ([]postgres.User, error) {
+	defer q.lock()()
+	return rows(q.d.users, func(user postgres.User) bool {
+		return user.HouseholdID == householdID
+	}, compareUsers), nil
+}
+
+func (q *Queries) GetUser(ctx context.Context, arg postgres.GetUserParams) (postgres.User, error) {
+	defer q.lock()()
+	user, ok := q.d.users[arg.ID]
+	if !ok || user.HouseholdID != arg.HouseholdID {
+		return postgres.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg postgres.CreateUserParams) (postgres.User, error) {
+	defer q.lock()()
+	q.d.usersSequence++
+	user := postgres.User{ID: q.d.usersSequence, Name: arg.Name, HouseholdID: arg.HouseholdID, Share: arg.Share}
+	if err := q.d.insertUser(user); err != nil {
+		return postgres.User{}, err
+	}
+	return user, nil
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg postgres.UpdateUserParams) (postgres.User, error) {
+	defer q.lock()()
+	user, ok := q.d.users[arg.ID]
+	if !ok || user.HouseholdID != arg.HouseholdID {
+		return postgres.User{}, pgx.ErrNoRows
+	}
+	user.Name = arg.Name
+	user.Share = arg.Share
+	if err := q.d.checkUser(user); err != nil {
+		return postgres.User{}, err
+	}
+	q.d.users[user.ID] = user
+	return user, nil
+}
+
+func (q *Queries) SetUserArchived(ctx context.Context, arg postgres.SetUserArchivedParams) (postgres.User, error) {
+	defer q.lock()()
+	user, ok := q.d.users[arg.ID]
+	if !ok || user.HouseholdID != arg.HouseholdID {
+		return postgres.User{}, pgx.ErrNoRows
+	}
+	user.Archived = arg.Archived
+	q.d.users[user.ID] = user
+	return user, nil
+}
+
+func (q *Queries) ReassignUserTasks(ctx context.Context, arg postgres.ReassignUserTasksParams) ([]postgres.Task, error) {
+	defer q.lock()()
+	tasks := rows(q.d.tasks, func(task postgres.Task) bool {
+		return task.HouseholdID == arg.HouseholdID && task.UserID == arg.FromUserID
+	}, compareTasks)
+	for i := range tasks {
+		tasks[i].UserID = arg.ToUserID
+		if err := q.d.checkTask(tasks[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, task := range tasks {
+		q.d.tasks[task.ID] = task
+	}
+	return tasks, nil
+}
+
+// DeleteUser unlinks the accounts of the user and deletes their timers and
+// calendar feeds, like the ON DELETE SET NULL and ON DELETE CASCADE of their
+// foreign keys.
+func (q *Queries) DeleteUser(ctx context.Context, arg postgres.DeleteUserParams) (int64, error) {
+	defer q.lock()()
+	user, ok := q.d.users[arg.ID]
+	if !ok || user.HouseholdID != arg.HouseholdID {
+		return 0, nil
+	}
+	for _, task := range q.d.tasks {
+		if task.UserID == user.ID {
+			return 0, foreignKeyViolation("tasks", "tasks_user_id_fkey")
+		}
+	}
+	for _, account := range q.d.accounts {
+		if account.UserID.Valid && account.UserID.Int32 == user.ID {
+			account.UserID = pgtype.Int4{}
+			q.d.accounts[account.ID] = account
+		}
+	}
+	for id, timer := range q.d.timers {
+		if timer.UserID == user.ID {
+			delete(q.d.timers, id)
+		}
+	}
+	for id, feed := range q.d.calendarFeeds {
+		if feed.UserID.Valid && feed.UserID.Int32 == user.ID {
+			delete(q.d.calendarFeeds, id)
+		}
+	}
+	delete(q.d.users, user.ID)
+	return 1, nil
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg postgres.RestoreUserParams) error {
+	defer q.lock()()
+	return q.d.insertUser(postgres.User{ID: arg.ID, Name: arg.Name, HouseholdID: arg.HouseholdID, Share: arg.Share, Archived: arg.Archived})
+}
+
+func (q *Queries) ResetUsersSequence(ctx context.Context) error {
+	defer q.lock()()
+	q.d.usersSequence = maxID(q.d.users)
+	return nil
+}
+
+func compareUsers(a, b postgres.User) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+}
+
+func (d *data) checkUser(user postgres.User) error {
+	switch {
+	case user.Name == "":
+		return checkViolation("users", "users_name_check")
+	case user.Share < 1 || user.Share > 100:
+		return checkViolation("users", "users_share_check")
+	}
+	for _, other := range d.users {
+		if other.ID != user.ID && other.HouseholdID == user.HouseholdID && other.Name == user.Name {
+			return uniqueViolation("users", "users_household_id_name_key")
+		}
+	}
+	if _, ok := d.households[user.HouseholdID]; !ok {
+		return foreignKeyViolation("users", "users_household_id_fkey")
+	}
+	return nil
+}
+
+func (d *data) insertUser(user postgres.User) error {
+	if _, ok := d.users[user.ID]; ok {
+		return uniqueViolation("users", "users_pkey")
+	}
+	if err := d.checkUser(user); err != nil {
+		return err
+	}
+	d.users[user.ID] = user
+	return nil
+}
@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListWebhooks(ctx context.Context, householdID int32) ([]postgres.Webhook, error) {
+	defer q.lock()()
+	return rows(q.d.webhooks, func(webhook postgres.Webhook) bool {
+		return webhook.HouseholdID == householdID
+	}, func(a, b postgres.Webhook) int {
+		return cmp.Compare(a.ID, b.ID)
+	}), nil
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg postgres.GetWebhookParams) (postgres.Webhook, error) {
+	defer q.lock()()
+	webhook, ok := q.d.webhooks[arg.ID]
+	if !ok || webhook.HouseholdID != arg.HouseholdID {
+		return postgres.Webhook{}, pgx.ErrNoRows
+	}
+	return webhook, nil
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg postgres.CreateWebhookParams) (postgres.Webhook, error) {
+	defer q.lock()()
+	q.d.webhooksSequence++
+	webhook := postgres.Webhook{
+		ID:          q.d.webhooksSequence,
+		HouseholdID: arg.HouseholdID,
+		Url:         arg.Url,
+		Secret:      arg.Secret,
+		Events:      slices.Clone(arg.Events),
+		CreatedAt:   timestamp(time.Now()),
+	}
+	if err := q.d.checkWebhook(webhook); err != nil {
+		return postgres.Webhook{}, err
+	}
+	q.d.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg postgres.UpdateWebhookParams) (postgres.Webhook, error) {
+	defer q.lock()()
+	webhook, ok := q.d.webhooks[arg.ID]
+	if !ok || webhook.HouseholdID != arg.HouseholdID {
+		return postgres.Webhook{}, pgx.ErrNoRows
+	}
+	webhook.Url = arg.Url
+	webhook.Secret = arg.Secret
+	webhook.Events = slices.Clone(arg.Events)
+	if err := q.d.checkWebhook(webhook); err != nil {
+		return postgres.Webhook{}, err
+	}
+	q.d.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+// DeleteWebhook deletes the deliveries of the webhook too, like the ON DELETE
+// CASCADE of their foreign key.
+func (q *Queries) DeleteWebhook(ctx context.Context, arg postgres.DeleteWebhookParams) error {
+	defer q.lock()()
+	webhook, ok := q.d.webhooks[arg.ID]
+	if !ok || webhook.HouseholdID != arg.HouseholdID {
+		return nil
+	}
+	for id, delivery := range q.d.deliveries {
+		if delivery.WebhookID == webhook.ID {
+			delete(q.d.deliveries, id)
+		}
+	}
+	delete(q.d.webhooks, webhook.ID)
+	return nil
+}
+
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg postgres.EnqueueWebhookDeliveriesParams) error {
+	defer q.lock()()
+	now := timestamp(time.Now())
+	for _, webhook := range q.d.webhooks {
+		if webhook.HouseholdID != arg.HouseholdID || !slices.Contains(webhook.Events, arg.Resource) {
+			continue
+		}
+		delivery := postgres.WebhookDelivery{
+			ID:            uuid.New(),
+			WebhookID:     webhook.ID,
+			Event:         arg.Event,
+			Payload:       slices.Clone(arg.Payload),
+			Status:        "pending",
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		q.d.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg postgres.ListWebhookDeliveriesParams) ([]postgres.WebhookDelivery, error) {
+	defer q.lock()()
+	return limit(rows(q.d.deliveries, func(delivery postgres.WebhookDelivery) bool {
+		return delivery.WebhookID == arg.WebhookID
+	}, func(a, b postgres.WebhookDelivery) int {
+		return cmp.Or(compareTime(b.CreatedAt, a.CreatedAt), compareUUID(a.ID, b.ID))
+	}), arg.Limit), nil
+}
+
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg postgres.ClaimWebhookDeliveriesParams) ([]postgres.ClaimWebhookDeliveriesRow, error) {
+	defer q.lock()()
+	due := limit(rows(q.d.deliveries, func(delivery postgres.WebhookDelivery) bool {
+		return delivery.Status == "pending" && !delivery.NextAttemptAt.After(arg.Now)
+	}, func(a, b postgres.WebhookDelivery) int {
+		return cmp.Or(compareTime(a.NextAttemptAt, b.NextAttemptAt), compareUUID(a.ID, b.ID))
+	}), arg.MaxDeliveries)
+	var items []postgres.ClaimWebhookDeliveriesRow
+	for _, delivery := range due {
+		delivery.NextAttemptAt = timestamp(arg.LeaseUntil)
+		q.d.deliveries[delivery.ID] = delivery
+		webhook := q.d.webhooks[delivery.WebhookID]
+		items = append(items, postgres.ClaimWebhookDeliveriesRow{
+			ID:       delivery.ID,
+			Event:    delivery.Event,
+			Payload:  delivery.Payload,
+			Attempts: delivery.Attempts,
+			Url:      webhook.Url,
+			Secret:   webhook.Secret,
+		})
+	}
+	return items, nil
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg postgres.UpdateWebhookDeliveryParams) error {
+	defer q.lock()()
+	delivery, ok := q.d.deliveries[arg.ID]
+	if !ok {
+		return nil
+	}
+	if !slices.Contains([]string{"pending", "succeeded", "failed"}, arg.Status) {
+		return checkViolation("webhook_deliveries", "webhook_deliveries_status_check")
+	}
+	delivery.Status = arg.Status
+	delivery.Attempts++
+	delivery.NextAttemptAt = timestamp(arg.NextAttemptAt)
+	delivery.LastStatusCode = arg.LastStatusCode
+	delivery.LastError = arg.LastError
+	delivery.DeliveredAt = arg.DeliveredAt
+	if delivery.DeliveredAt.Valid {
+		delivery.DeliveredAt.Time = timestamp(delivery.DeliveredAt.Time)
+	}
+	q.d.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg postgres.RetryWebhookDeliveryParams) (int64, error) {
+	defer q.lock()()
+	delivery, ok := q.d.deliveries[arg.ID]
+	if !ok || delivery.WebhookID != arg.WebhookID || q.d.webhooks[delivery.WebhookID].HouseholdID != arg.HouseholdID {
+		return 0, nil
+	}
+	delivery.Status = "pending"
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.NextAttemptAt = timestamp(time.Now())
+	q.d.deliveries[delivery.ID] = delivery
+	return 1, nil
+}
+
+func (d *data) checkWebhook(webhook postgres.Webhook) error {
+	if webhook.Url == "" {
+		return checkViolation("webhooks", "webhooks_url_check")
+	}
+	if webhook.Secret == "" {
+		return checkViolation("webhooks", "webhooks_secret_check")
+	}
+	if _, ok := d.households[webhook.HouseholdID]; !ok {
+		return foreignKeyViolation("webhooks", "webhooks_household_id_fkey")
+	}
+	return nil
+}
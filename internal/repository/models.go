@@ -11,6 +11,17 @@ type Chore struct {
 	Name              string `json:"name"`
 	Description       string `json:"description"`
 	DefaultDurationMn int32  `json:"default_duration_mn"`
+	HouseholdID       int32  `json:"-"`
+	// ScheduleKind is one of "none", "interval", "weekly" or "monthly".
+	ScheduleKind         string `json:"schedule_kind"`
+	ScheduleIntervalDays int32  `json:"schedule_interval_days"`
+	// ScheduleWeekdays is a bitmask of the days of the week, bit 0 being Sunday.
+	ScheduleWeekdays int32 `json:"schedule_weekdays"`
+	ScheduleMonthDay int32 `json:"schedule_month_day"`
+	// Difficulty weights the duration of the tasks of the chore, from 1 to 10.
+	Difficulty int32 `json:"difficulty"`
+	// Archived chores are hidden from the lists and forms but keep their tasks.
+	Archived bool `json:"archived"`
 }
 
 type Task struct {
@@ -20,9 +31,18 @@ type Task struct {
 	StartedAt   time.Time `json:"started_at"`
 	DurationMn  int32     `json:"duration_mn"`
 	Description string    `json:"description"`
+	HouseholdID int32     `json:"-"`
+	// Points is the duration weighted by the difficulty of the chore.
+	Points int64 `json:"points"`
 }
 
 type User struct {
-	ID   int32  `json:"id"`
-	Name string `json:"name"`
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	HouseholdID int32  `json:"-"`
+	// Share is the part of the chores expected from the user, relative to the
+	// shares of the other users of the household.
+	Share int32 `json:"share"`
+	// Archived users are hidden from the lists and forms but keep their tasks.
+	Archived bool `json:"archived"`
 }
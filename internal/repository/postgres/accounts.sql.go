@@ -0,0 +1,278 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: accounts.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countAccounts = `-- name: CountAccounts :one
+SELECT COUNT(*) FROM accounts
+`
+
+func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countAccounts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAccount = `-- name: CreateAccount :one
+INSERT INTO accounts (
+    household_id, username, password_hash, user_id
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, username, password_hash, user_id, created_at, household_id
+`
+
+type CreateAccountParams struct {
+	HouseholdID  int32
+	Username     string
+	PasswordHash string
+	UserID       pgtype.Int4
+}
+
+func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.HouseholdID,
+		arg.Username,
+		arg.PasswordHash,
+		arg.UserID,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.HouseholdID,
+	)
+	return i, err
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+    token_hash, account_id, expires_at
+) VALUES (
+    $1, $2, $3
+)
+RETURNING token_hash, account_id, created_at, expires_at
+`
+
+type CreateSessionParams struct {
+	TokenHash string
+	AccountID int32
+	ExpiresAt time.Time
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRow(ctx, createSession, arg.TokenHash, arg.AccountID, arg.ExpiresAt)
+	var i Session
+	err := row.Scan(
+		&i.TokenHash,
+		&i.AccountID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const deleteAccount = `-- name: DeleteAccount :exec
+DELETE FROM accounts
+WHERE household_id = $1 AND id = $2
+`
+
+type DeleteAccountParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) DeleteAccount(ctx context.Context, arg DeleteAccountParams) error {
+	_, err := q.db.Exec(ctx, deleteAccount, arg.HouseholdID, arg.ID)
+	return err
+}
+
+const deleteAccountSessions = `-- name: DeleteAccountSessions :exec
+DELETE FROM sessions
+WHERE account_id = $1
+`
+
+func (q *Queries) DeleteAccountSessions(ctx context.Context, accountID int32) error {
+	_, err := q.db.Exec(ctx, deleteAccountSessions, accountID)
+	return err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteExpiredSessions)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE token_hash = $1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := q.db.Exec(ctx, deleteSession, tokenHash)
+	return err
+}
+
+const getAccount = `-- name: GetAccount :one
+SELECT id, username, password_hash, user_id, created_at, household_id FROM accounts
+WHERE household_id = $1 AND id = $2
+`
+
+type GetAccountParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccount, arg.HouseholdID, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.HouseholdID,
+	)
+	return i, err
+}
+
+const getAccountByUsername = `-- name: GetAccountByUsername :one
+SELECT id, username, password_hash, user_id, created_at, household_id FROM accounts
+WHERE username = $1
+`
+
+func (q *Queries) GetAccountByUsername(ctx context.Context, username string) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByUsername, username)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.HouseholdID,
+	)
+	return i, err
+}
+
+const getSessionAccount = `-- name: GetSessionAccount :one
+SELECT accounts.id, accounts.username, accounts.password_hash, accounts.user_id, accounts.created_at, accounts.household_id
+FROM sessions
+JOIN accounts ON sessions.account_id = accounts.id
+WHERE sessions.token_hash = $1 AND sessions.expires_at > now()
+`
+
+func (q *Queries) GetSessionAccount(ctx context.Context, tokenHash string) (Account, error) {
+	row := q.db.QueryRow(ctx, getSessionAccount, tokenHash)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.HouseholdID,
+	)
+	return i, err
+}
+
+const listAccounts = `-- name: ListAccounts :many
+SELECT id, username, password_hash, user_id, created_at, household_id FROM accounts
+WHERE household_id = $1
+ORDER BY username
+`
+
+func (q *Queries) ListAccounts(ctx context.Context, householdID int32) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccounts, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.HouseholdID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateAccount = `-- name: UpdateAccount :one
+UPDATE accounts SET
+username = $3,
+user_id = $4
+WHERE household_id = $1 AND id = $2
+RETURNING id, username, password_hash, user_id, created_at, household_id
+`
+
+type UpdateAccountParams struct {
+	HouseholdID int32
+	ID          int32
+	Username    string
+	UserID      pgtype.Int4
+}
+
+func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccount,
+		arg.HouseholdID,
+		arg.ID,
+		arg.Username,
+		arg.UserID,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.HouseholdID,
+	)
+	return i, err
+}
+
+const updateAccountPassword = `-- name: UpdateAccountPassword :exec
+UPDATE accounts SET
+password_hash = $2
+WHERE id = $1
+`
+
+type UpdateAccountPasswordParams struct {
+	ID           int32
+	PasswordHash string
+}
+
+func (q *Queries) UpdateAccountPassword(ctx context.Context, arg UpdateAccountPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateAccountPassword, arg.ID, arg.PasswordHash)
+	return err
+}
@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: backups.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countHouseholds = `-- name: CountHouseholds :one
+SELECT COUNT(*) FROM households
+`
+
+func (q *Queries) CountHouseholds(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countHouseholds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const resetChoresSequence = `-- name: ResetChoresSequence :exec
+SELECT setval(pg_get_serial_sequence('chores', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM chores
+`
+
+func (q *Queries) ResetChoresSequence(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, resetChoresSequence)
+	return err
+}
+
+const resetHouseholdsSequence = `-- name: ResetHouseholdsSequence :exec
+SELECT setval(pg_get_serial_sequence('households', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM households
+`
+
+func (q *Queries) ResetHouseholdsSequence(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, resetHouseholdsSequence)
+	return err
+}
+
+const resetUsersSequence = `-- name: ResetUsersSequence :exec
+SELECT setval(pg_get_serial_sequence('users', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM users
+`
+
+func (q *Queries) ResetUsersSequence(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, resetUsersSequence)
+	return err
+}
+
+const restoreChore = `-- name: RestoreChore :exec
+INSERT INTO chores (
+    id, household_id, name, description, default_duration_mn, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+`
+
+type RestoreChoreParams struct {
+	ID                   int32
+	HouseholdID          int32
+	Name                 string
+	Description          string
+	DefaultDurationMn    int32
+	ScheduleKind         string
+	ScheduleIntervalDays int32
+	ScheduleWeekdays     int32
+	ScheduleMonthDay     int32
+	Difficulty           int32
+	Archived             bool
+}
+
+func (q *Queries) RestoreChore(ctx context.Context, arg RestoreChoreParams) error {
+	_, err := q.db.Exec(ctx, restoreChore,
+		arg.ID,
+		arg.HouseholdID,
+		arg.Name,
+		arg.Description,
+		arg.DefaultDurationMn,
+		arg.ScheduleKind,
+		arg.ScheduleIntervalDays,
+		arg.ScheduleWeekdays,
+		arg.ScheduleMonthDay,
+		arg.Difficulty,
+		arg.Archived,
+	)
+	return err
+}
+
+const restoreHousehold = `-- name: RestoreHousehold :exec
+INSERT INTO households (
+    id, name, created_at
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type RestoreHouseholdParams struct {
+	ID        int32
+	Name      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) RestoreHousehold(ctx context.Context, arg RestoreHouseholdParams) error {
+	_, err := q.db.Exec(ctx, restoreHousehold, arg.ID, arg.Name, arg.CreatedAt)
+	return err
+}
+
+const restoreTask = `-- name: RestoreTask :exec
+INSERT INTO tasks (
+    id, household_id, user_id, chore_id, started_at, duration_mn, description, points
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+`
+
+type RestoreTaskParams struct {
+	ID          uuid.UUID
+	HouseholdID int32
+	UserID      int32
+	ChoreID     int32
+	StartedAt   time.Time
+	DurationMn  int32
+	Description string
+	Points      int64
+}
+
+func (q *Queries) RestoreTask(ctx context.Context, arg RestoreTaskParams) error {
+	_, err := q.db.Exec(ctx, restoreTask,
+		arg.ID,
+		arg.HouseholdID,
+		arg.UserID,
+		arg.ChoreID,
+		arg.StartedAt,
+		arg.DurationMn,
+		arg.Description,
+		arg.Points,
+	)
+	return err
+}
+
+const restoreUser = `-- name: RestoreUser :exec
+INSERT INTO users (
+    id, household_id, name, share, archived
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+`
+
+type RestoreUserParams struct {
+	ID          int32
+	HouseholdID int32
+	Name        string
+	Share       int32
+	Archived    bool
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) error {
+	_, err := q.db.Exec(ctx, restoreUser,
+		arg.ID,
+		arg.HouseholdID,
+		arg.Name,
+		arg.Share,
+		arg.Archived,
+	)
+	return err
+}
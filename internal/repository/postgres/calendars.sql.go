@@ -0,0 +1,108 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: calendars.sql
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCalendarFeed = `-- name: CreateCalendarFeed :one
+INSERT INTO calendar_feeds (
+    household_id, user_id, token_hash
+) VALUES (
+    $1, $2, $3
+)
+RETURNING id, household_id, user_id, token_hash, created_at
+`
+
+type CreateCalendarFeedParams struct {
+	HouseholdID int32
+	UserID      pgtype.Int4
+	TokenHash   string
+}
+
+func (q *Queries) CreateCalendarFeed(ctx context.Context, arg CreateCalendarFeedParams) (CalendarFeed, error) {
+	row := q.db.QueryRow(ctx, createCalendarFeed, arg.HouseholdID, arg.UserID, arg.TokenHash)
+	var i CalendarFeed
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteCalendarFeed = `-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feeds
+WHERE household_id = $1 AND id = $2
+`
+
+type DeleteCalendarFeedParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) DeleteCalendarFeed(ctx context.Context, arg DeleteCalendarFeedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCalendarFeed, arg.HouseholdID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getCalendarFeed = `-- name: GetCalendarFeed :one
+SELECT id, household_id, user_id, token_hash, created_at FROM calendar_feeds
+WHERE token_hash = $1
+`
+
+func (q *Queries) GetCalendarFeed(ctx context.Context, tokenHash string) (CalendarFeed, error) {
+	row := q.db.QueryRow(ctx, getCalendarFeed, tokenHash)
+	var i CalendarFeed
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listCalendarFeeds = `-- name: ListCalendarFeeds :many
+SELECT id, household_id, user_id, token_hash, created_at FROM calendar_feeds
+WHERE household_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListCalendarFeeds(ctx context.Context, householdID int32) ([]CalendarFeed, error) {
+	rows, err := q.db.Query(ctx, listCalendarFeeds, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CalendarFeed
+	for rows.Next() {
+		var i CalendarFeed
+		if err := rows.Scan(
+			&i.ID,
+			&i.HouseholdID,
+			&i.UserID,
+			&i.TokenHash,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
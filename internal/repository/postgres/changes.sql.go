@@ -0,0 +1,19 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: changes.sql
+
+package postgres
+
+import (
+	"context"
+)
+
+const notifyChange = `-- name: NotifyChange :exec
+SELECT pg_notify('changes', $1::text)
+`
+
+func (q *Queries) NotifyChange(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, notifyChange, payload)
+	return err
+}
@@ -7,69 +7,153 @@ package postgres
 
 import (
 	"context"
+	"time"
 )
 
+const choresTasksStats = `-- name: ChoresTasksStats :many
+SELECT chore_id, COUNT(*) AS tasks_count, MIN(started_at)::timestamptz AS first_started_at, MAX(started_at)::timestamptz AS last_started_at
+FROM tasks
+WHERE household_id = $1
+GROUP BY chore_id
+`
+
+type ChoresTasksStatsRow struct {
+	ChoreID        int32
+	TasksCount     int64
+	FirstStartedAt time.Time
+	LastStartedAt  time.Time
+}
+
+func (q *Queries) ChoresTasksStats(ctx context.Context, householdID int32) ([]ChoresTasksStatsRow, error) {
+	rows, err := q.db.Query(ctx, choresTasksStats, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChoresTasksStatsRow
+	for rows.Next() {
+		var i ChoresTasksStatsRow
+		if err := rows.Scan(
+			&i.ChoreID,
+			&i.TasksCount,
+			&i.FirstStartedAt,
+			&i.LastStartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createChore = `-- name: CreateChore :one
 INSERT INTO chores (
-    name, description, default_duration_mn
+    household_id, name, description, default_duration_mn, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
-RETURNING id, name, description, default_duration_mn
+RETURNING id, name, description, default_duration_mn, household_id, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived
 `
 
 type CreateChoreParams struct {
-	Name              string
-	Description       string
-	DefaultDurationMn int32
+	HouseholdID          int32
+	Name                 string
+	Description          string
+	DefaultDurationMn    int32
+	ScheduleKind         string
+	ScheduleIntervalDays int32
+	ScheduleWeekdays     int32
+	ScheduleMonthDay     int32
+	Difficulty           int32
 }
 
 func (q *Queries) CreateChore(ctx context.Context, arg CreateChoreParams) (Chore, error) {
-	row := q.db.QueryRow(ctx, createChore, arg.Name, arg.Description, arg.DefaultDurationMn)
+	row := q.db.QueryRow(ctx, createChore,
+		arg.HouseholdID,
+		arg.Name,
+		arg.Description,
+		arg.DefaultDurationMn,
+		arg.ScheduleKind,
+		arg.ScheduleIntervalDays,
+		arg.ScheduleWeekdays,
+		arg.ScheduleMonthDay,
+		arg.Difficulty,
+	)
 	var i Chore
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Description,
 		&i.DefaultDurationMn,
+		&i.HouseholdID,
+		&i.ScheduleKind,
+		&i.ScheduleIntervalDays,
+		&i.ScheduleWeekdays,
+		&i.ScheduleMonthDay,
+		&i.Difficulty,
+		&i.Archived,
 	)
 	return i, err
 }
 
-const deleteChore = `-- name: DeleteChore :exec
+const deleteChore = `-- name: DeleteChore :execrows
 DELETE FROM chores
-WHERE id = $1
+WHERE household_id = $1 AND id = $2
 `
 
-func (q *Queries) DeleteChore(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteChore, id)
-	return err
+type DeleteChoreParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) DeleteChore(ctx context.Context, arg DeleteChoreParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteChore, arg.HouseholdID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getChore = `-- name: GetChore :one
-SELECT id, name, description, default_duration_mn FROM chores
-WHERE id = $1
+SELECT id, name, description, default_duration_mn, household_id, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived FROM chores
+WHERE household_id = $1 AND id = $2
 `
 
-func (q *Queries) GetChore(ctx context.Context, id int32) (Chore, error) {
-	row := q.db.QueryRow(ctx, getChore, id)
+type GetChoreParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) GetChore(ctx context.Context, arg GetChoreParams) (Chore, error) {
+	row := q.db.QueryRow(ctx, getChore, arg.HouseholdID, arg.ID)
 	var i Chore
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Description,
 		&i.DefaultDurationMn,
+		&i.HouseholdID,
+		&i.ScheduleKind,
+		&i.ScheduleIntervalDays,
+		&i.ScheduleWeekdays,
+		&i.ScheduleMonthDay,
+		&i.Difficulty,
+		&i.Archived,
 	)
 	return i, err
 }
 
 const listChores = `-- name: ListChores :many
-SELECT id, name, description, default_duration_mn FROM chores
+SELECT id, name, description, default_duration_mn, household_id, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived FROM chores
+WHERE household_id = $1
 ORDER BY name
 `
 
-func (q *Queries) ListChores(ctx context.Context) ([]Chore, error) {
-	rows, err := q.db.Query(ctx, listChores)
+func (q *Queries) ListChores(ctx context.Context, householdID int32) ([]Chore, error) {
+	rows, err := q.db.Query(ctx, listChores, householdID)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +166,13 @@ func (q *Queries) ListChores(ctx context.Context) ([]Chore, error) {
 			&i.Name,
 			&i.Description,
 			&i.DefaultDurationMn,
+			&i.HouseholdID,
+			&i.ScheduleKind,
+			&i.ScheduleIntervalDays,
+			&i.ScheduleWeekdays,
+			&i.ScheduleMonthDay,
+			&i.Difficulty,
+			&i.Archived,
 		); err != nil {
 			return nil, err
 		}
@@ -93,28 +184,126 @@ func (q *Queries) ListChores(ctx context.Context) ([]Chore, error) {
 	return items, nil
 }
 
+const reassignChoreTasks = `-- name: ReassignChoreTasks :many
+UPDATE tasks SET
+chore_id = $1,
+points = duration_mn * $2::bigint
+WHERE household_id = $3 AND chore_id = $4
+RETURNING id, user_id, chore_id, started_at, duration_mn, description, household_id, points
+`
+
+type ReassignChoreTasksParams struct {
+	ToChoreID   int32
+	Difficulty  int64
+	HouseholdID int32
+	FromChoreID int32
+}
+
+func (q *Queries) ReassignChoreTasks(ctx context.Context, arg ReassignChoreTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, reassignChoreTasks,
+		arg.ToChoreID,
+		arg.Difficulty,
+		arg.HouseholdID,
+		arg.FromChoreID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ChoreID,
+			&i.StartedAt,
+			&i.DurationMn,
+			&i.Description,
+			&i.HouseholdID,
+			&i.Points,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setChoreArchived = `-- name: SetChoreArchived :one
+UPDATE chores SET
+archived = $3
+WHERE household_id = $1 AND id = $2
+RETURNING id, name, description, default_duration_mn, household_id, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived
+`
+
+type SetChoreArchivedParams struct {
+	HouseholdID int32
+	ID          int32
+	Archived    bool
+}
+
+func (q *Queries) SetChoreArchived(ctx context.Context, arg SetChoreArchivedParams) (Chore, error) {
+	row := q.db.QueryRow(ctx, setChoreArchived, arg.HouseholdID, arg.ID, arg.Archived)
+	var i Chore
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.DefaultDurationMn,
+		&i.HouseholdID,
+		&i.ScheduleKind,
+		&i.ScheduleIntervalDays,
+		&i.ScheduleWeekdays,
+		&i.ScheduleMonthDay,
+		&i.Difficulty,
+		&i.Archived,
+	)
+	return i, err
+}
+
 const updateChore = `-- name: UpdateChore :one
 UPDATE chores SET 
-name = $2,
-description = $3,
-default_duration_mn = $4
-WHERE id = $1
-RETURNING id, name, description, default_duration_mn
+name = $3,
+description = $4,
+default_duration_mn = $5,
+schedule_kind = $6,
+schedule_interval_days = $7,
+schedule_weekdays = $8,
+schedule_month_day = $9,
+difficulty = $10
+WHERE household_id = $1 AND id = $2
+RETURNING id, name, description, default_duration_mn, household_id, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived
 `
 
 type UpdateChoreParams struct {
-	ID                int32
-	Name              string
-	Description       string
-	DefaultDurationMn int32
+	HouseholdID          int32
+	ID                   int32
+	Name                 string
+	Description          string
+	DefaultDurationMn    int32
+	ScheduleKind         string
+	ScheduleIntervalDays int32
+	ScheduleWeekdays     int32
+	ScheduleMonthDay     int32
+	Difficulty           int32
 }
 
 func (q *Queries) UpdateChore(ctx context.Context, arg UpdateChoreParams) (Chore, error) {
 	row := q.db.QueryRow(ctx, updateChore,
+		arg.HouseholdID,
 		arg.ID,
 		arg.Name,
 		arg.Description,
 		arg.DefaultDurationMn,
+		arg.ScheduleKind,
+		arg.ScheduleIntervalDays,
+		arg.ScheduleWeekdays,
+		arg.ScheduleMonthDay,
+		arg.Difficulty,
 	)
 	var i Chore
 	err := row.Scan(
@@ -122,6 +311,13 @@ func (q *Queries) UpdateChore(ctx context.Context, arg UpdateChoreParams) (Chore
 		&i.Name,
 		&i.Description,
 		&i.DefaultDurationMn,
+		&i.HouseholdID,
+		&i.ScheduleKind,
+		&i.ScheduleIntervalDays,
+		&i.ScheduleWeekdays,
+		&i.ScheduleMonthDay,
+		&i.Difficulty,
+		&i.Archived,
 	)
 	return i, err
 }
@@ -0,0 +1,82 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: households.sql
+
+package postgres
+
+import (
+	"context"
+)
+
+const createHousehold = `-- name: CreateHousehold :one
+INSERT INTO households (
+    name
+) VALUES (
+    $1
+)
+RETURNING id, name, created_at
+`
+
+func (q *Queries) CreateHousehold(ctx context.Context, name string) (Household, error) {
+	row := q.db.QueryRow(ctx, createHousehold, name)
+	var i Household
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
+const getHousehold = `-- name: GetHousehold :one
+SELECT id, name, created_at FROM households
+WHERE id = $1
+`
+
+func (q *Queries) GetHousehold(ctx context.Context, id int32) (Household, error) {
+	row := q.db.QueryRow(ctx, getHousehold, id)
+	var i Household
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
+const listHouseholds = `-- name: ListHouseholds :many
+SELECT id, name, created_at FROM households
+ORDER BY id
+`
+
+func (q *Queries) ListHouseholds(ctx context.Context) ([]Household, error) {
+	rows, err := q.db.Query(ctx, listHouseholds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Household
+	for rows.Next() {
+		var i Household
+		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateHousehold = `-- name: UpdateHousehold :one
+UPDATE households SET
+name = $2
+WHERE id = $1
+RETURNING id, name, created_at
+`
+
+type UpdateHouseholdParams struct {
+	ID   int32
+	Name string
+}
+
+func (q *Queries) UpdateHousehold(ctx context.Context, arg UpdateHouseholdParams) (Household, error) {
+	row := q.db.QueryRow(ctx, updateHousehold, arg.ID, arg.Name)
+	var i Household
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
@@ -8,13 +8,51 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Account struct {
+	ID           int32
+	Username     string
+	PasswordHash string
+	UserID       pgtype.Int4
+	CreatedAt    time.Time
+	HouseholdID  int32
+}
+
+type CalendarFeed struct {
+	ID          int32
+	HouseholdID int32
+	UserID      pgtype.Int4
+	TokenHash   string
+	CreatedAt   time.Time
+}
+
 type Chore struct {
-	ID                int32
-	Name              string
-	Description       string
-	DefaultDurationMn int32
+	ID                   int32
+	Name                 string
+	Description          string
+	DefaultDurationMn    int32
+	HouseholdID          int32
+	ScheduleKind         string
+	ScheduleIntervalDays int32
+	ScheduleWeekdays     int32
+	ScheduleMonthDay     int32
+	Difficulty           int32
+	Archived             bool
+}
+
+type Household struct {
+	ID        int32
+	Name      string
+	CreatedAt time.Time
+}
+
+type Session struct {
+	TokenHash string
+	AccountID int32
+	CreatedAt time.Time
+	ExpiresAt time.Time
 }
 
 type Task struct {
@@ -24,9 +62,47 @@ type Task struct {
 	StartedAt   time.Time
 	DurationMn  int32
 	Description string
+	HouseholdID int32
+	Points      int64
+}
+
+type Timer struct {
+	ID             int32
+	HouseholdID    int32
+	UserID         int32
+	ChoreID        int32
+	StartedAt      time.Time
+	ResumedAt      pgtype.Timestamptz
+	ElapsedSeconds int64
 }
 
 type User struct {
-	ID   int32
-	Name string
+	ID          int32
+	Name        string
+	HouseholdID int32
+	Share       int32
+	Archived    bool
+}
+
+type Webhook struct {
+	ID          int32
+	HouseholdID int32
+	Url         string
+	Secret      string
+	Events      []string
+	CreatedAt   time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      int32
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastStatusCode int32
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    pgtype.Timestamptz
 }
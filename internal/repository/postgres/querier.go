@@ -0,0 +1,90 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+
+package postgres
+
+import (
+	"context"
+)
+
+type Querier interface {
+	ChoresTasksStats(ctx context.Context, householdID int32) ([]ChoresTasksStatsRow, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CountAccounts(ctx context.Context) (int64, error)
+	CountHouseholds(ctx context.Context) (int64, error)
+	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateCalendarFeed(ctx context.Context, arg CreateCalendarFeedParams) (CalendarFeed, error)
+	CreateChore(ctx context.Context, arg CreateChoreParams) (Chore, error)
+	CreateHousehold(ctx context.Context, name string) (Household, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
+	CreateTimer(ctx context.Context, arg CreateTimerParams) (Timer, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	DeleteAccount(ctx context.Context, arg DeleteAccountParams) error
+	DeleteAccountSessions(ctx context.Context, accountID int32) error
+	DeleteCalendarFeed(ctx context.Context, arg DeleteCalendarFeedParams) (int64, error)
+	DeleteChore(ctx context.Context, arg DeleteChoreParams) (int64, error)
+	DeleteExpiredSessions(ctx context.Context) error
+	DeleteSession(ctx context.Context, tokenHash string) error
+	DeleteTask(ctx context.Context, arg DeleteTaskParams) (int64, error)
+	DeleteTimer(ctx context.Context, arg DeleteTimerParams) (int64, error)
+	DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error)
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) error
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) error
+	GetAccount(ctx context.Context, arg GetAccountParams) (Account, error)
+	GetAccountByUsername(ctx context.Context, username string) (Account, error)
+	GetCalendarFeed(ctx context.Context, tokenHash string) (CalendarFeed, error)
+	GetChore(ctx context.Context, arg GetChoreParams) (Chore, error)
+	GetChoreTasks(ctx context.Context, arg GetChoreTasksParams) ([]GetChoreTasksRow, error)
+	GetHousehold(ctx context.Context, id int32) (Household, error)
+	GetSessionAccount(ctx context.Context, tokenHash string) (Account, error)
+	GetTask(ctx context.Context, arg GetTaskParams) (Task, error)
+	GetTimer(ctx context.Context, arg GetTimerParams) (Timer, error)
+	GetTimerForUpdate(ctx context.Context, arg GetTimerForUpdateParams) (Timer, error)
+	GetUser(ctx context.Context, arg GetUserParams) (User, error)
+	GetUserTasks(ctx context.Context, arg GetUserTasksParams) ([]GetUserTasksRow, error)
+	GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error)
+	ListAccounts(ctx context.Context, householdID int32) ([]Account, error)
+	ListCalendarFeeds(ctx context.Context, householdID int32) ([]CalendarFeed, error)
+	ListChores(ctx context.Context, householdID int32) ([]Chore, error)
+	ListHouseholds(ctx context.Context) ([]Household, error)
+	ListTasks(ctx context.Context, householdID int32) ([]Task, error)
+	ListTimers(ctx context.Context, householdID int32) ([]ListTimersRow, error)
+	ListUsers(ctx context.Context, householdID int32) ([]User, error)
+	ListUsersTasks(ctx context.Context, householdID int32) ([]ListUsersTasksRow, error)
+	ListUsersTasksBetween(ctx context.Context, arg ListUsersTasksBetweenParams) ([]ListUsersTasksBetweenRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, householdID int32) ([]Webhook, error)
+	NotifyChange(ctx context.Context, payload string) error
+	ReassignChoreTasks(ctx context.Context, arg ReassignChoreTasksParams) ([]Task, error)
+	ReassignUserTasks(ctx context.Context, arg ReassignUserTasksParams) ([]Task, error)
+	ResetChoresSequence(ctx context.Context) error
+	ResetHouseholdsSequence(ctx context.Context) error
+	ResetUsersSequence(ctx context.Context) error
+	RestoreChore(ctx context.Context, arg RestoreChoreParams) error
+	RestoreHousehold(ctx context.Context, arg RestoreHouseholdParams) error
+	RestoreTask(ctx context.Context, arg RestoreTaskParams) error
+	RestoreUser(ctx context.Context, arg RestoreUserParams) error
+	RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (int64, error)
+	// Tasks are sorted on a key, the duration, the points or 0 to sort on the start
+	// time only, then on their start time and ID. The page after a task is
+	// selected with the values of its sort columns.
+	SearchTasks(ctx context.Context, arg SearchTasksParams) ([]SearchTasksRow, error)
+	SetChoreArchived(ctx context.Context, arg SetChoreArchivedParams) (Chore, error)
+	SetUserArchived(ctx context.Context, arg SetUserArchivedParams) (User, error)
+	TasksReport(ctx context.Context, arg TasksReportParams) ([]TasksReportRow, error)
+	TasksTimeline(ctx context.Context, arg TasksTimelineParams) ([]TasksTimelineRow, error)
+	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountPassword(ctx context.Context, arg UpdateAccountPasswordParams) error
+	UpdateChore(ctx context.Context, arg UpdateChoreParams) (Chore, error)
+	UpdateHousehold(ctx context.Context, arg UpdateHouseholdParams) (Household, error)
+	UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error)
+	UpdateTimer(ctx context.Context, arg UpdateTimerParams) (Timer, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error
+}
+
+var _ Querier = (*Queries)(nil)
@@ -14,28 +14,32 @@ import (
 
 const createTask = `-- name: CreateTask :one
 INSERT INTO tasks (
-    user_id, chore_id, started_at, duration_mn, description
+    household_id, user_id, chore_id, started_at, duration_mn, description, points
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, user_id, chore_id, started_at, duration_mn, description
+RETURNING id, user_id, chore_id, started_at, duration_mn, description, household_id, points
 `
 
 type CreateTaskParams struct {
+	HouseholdID int32
 	UserID      int32
 	ChoreID     int32
 	StartedAt   time.Time
 	DurationMn  int32
 	Description string
+	Points      int64
 }
 
 func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
 	row := q.db.QueryRow(ctx, createTask,
+		arg.HouseholdID,
 		arg.UserID,
 		arg.ChoreID,
 		arg.StartedAt,
 		arg.DurationMn,
 		arg.Description,
+		arg.Points,
 	)
 	var i Task
 	err := row.Scan(
@@ -45,35 +49,50 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		&i.StartedAt,
 		&i.DurationMn,
 		&i.Description,
+		&i.HouseholdID,
+		&i.Points,
 	)
 	return i, err
 }
 
-const deleteTask = `-- name: DeleteTask :exec
+const deleteTask = `-- name: DeleteTask :execrows
 DELETE FROM tasks
-WHERE id = $1
+WHERE household_id = $1 AND id = $2
 `
 
-func (q *Queries) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteTask, id)
-	return err
+type DeleteTaskParams struct {
+	HouseholdID int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) DeleteTask(ctx context.Context, arg DeleteTaskParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTask, arg.HouseholdID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getChoreTasks = `-- name: GetChoreTasks :many
-SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, users.id, users.name
+SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, tasks.household_id, tasks.points, users.id, users.name, users.household_id, users.share, users.archived
 FROM tasks
 JOIN users ON tasks.user_id = users.id
-WHERE tasks.chore_id = $1
+WHERE tasks.household_id = $1 AND tasks.chore_id = $2
 ORDER BY tasks.started_at DESC
 `
 
+type GetChoreTasksParams struct {
+	HouseholdID int32
+	ChoreID     int32
+}
+
 type GetChoreTasksRow struct {
 	Task Task
 	User User
 }
 
-func (q *Queries) GetChoreTasks(ctx context.Context, choreID int32) ([]GetChoreTasksRow, error) {
-	rows, err := q.db.Query(ctx, getChoreTasks, choreID)
+func (q *Queries) GetChoreTasks(ctx context.Context, arg GetChoreTasksParams) ([]GetChoreTasksRow, error) {
+	rows, err := q.db.Query(ctx, getChoreTasks, arg.HouseholdID, arg.ChoreID)
 	if err != nil {
 		return nil, err
 	}
@@ -88,8 +107,13 @@ func (q *Queries) GetChoreTasks(ctx context.Context, choreID int32) ([]GetChoreT
 			&i.Task.StartedAt,
 			&i.Task.DurationMn,
 			&i.Task.Description,
+			&i.Task.HouseholdID,
+			&i.Task.Points,
 			&i.User.ID,
 			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
 		); err != nil {
 			return nil, err
 		}
@@ -102,12 +126,17 @@ func (q *Queries) GetChoreTasks(ctx context.Context, choreID int32) ([]GetChoreT
 }
 
 const getTask = `-- name: GetTask :one
-SELECT id, user_id, chore_id, started_at, duration_mn, description FROM tasks
-WHERE id = $1
+SELECT id, user_id, chore_id, started_at, duration_mn, description, household_id, points FROM tasks
+WHERE household_id = $1 AND id = $2
 `
 
-func (q *Queries) GetTask(ctx context.Context, id uuid.UUID) (Task, error) {
-	row := q.db.QueryRow(ctx, getTask, id)
+type GetTaskParams struct {
+	HouseholdID int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg GetTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTask, arg.HouseholdID, arg.ID)
 	var i Task
 	err := row.Scan(
 		&i.ID,
@@ -116,26 +145,33 @@ func (q *Queries) GetTask(ctx context.Context, id uuid.UUID) (Task, error) {
 		&i.StartedAt,
 		&i.DurationMn,
 		&i.Description,
+		&i.HouseholdID,
+		&i.Points,
 	)
 	return i, err
 }
 
 const getUserTasks = `-- name: GetUserTasks :many
-SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, chores.id, chores.name, chores.description, chores.default_duration_mn
+SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, tasks.household_id, tasks.points, chores.id, chores.name, chores.description, chores.default_duration_mn, chores.household_id, chores.schedule_kind, chores.schedule_interval_days, chores.schedule_weekdays, chores.schedule_month_day, chores.difficulty, chores.archived
 FROM tasks
 JOIN chores ON tasks.chore_id = chores.id
 JOIN users ON tasks.user_id = users.id
-WHERE users.id = $1
+WHERE tasks.household_id = $1 AND users.id = $2
 ORDER BY tasks.started_at DESC
 `
 
+type GetUserTasksParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
 type GetUserTasksRow struct {
 	Task  Task
 	Chore Chore
 }
 
-func (q *Queries) GetUserTasks(ctx context.Context, id int32) ([]GetUserTasksRow, error) {
-	rows, err := q.db.Query(ctx, getUserTasks, id)
+func (q *Queries) GetUserTasks(ctx context.Context, arg GetUserTasksParams) ([]GetUserTasksRow, error) {
+	rows, err := q.db.Query(ctx, getUserTasks, arg.HouseholdID, arg.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -150,10 +186,19 @@ func (q *Queries) GetUserTasks(ctx context.Context, id int32) ([]GetUserTasksRow
 			&i.Task.StartedAt,
 			&i.Task.DurationMn,
 			&i.Task.Description,
+			&i.Task.HouseholdID,
+			&i.Task.Points,
 			&i.Chore.ID,
 			&i.Chore.Name,
 			&i.Chore.Description,
 			&i.Chore.DefaultDurationMn,
+			&i.Chore.HouseholdID,
+			&i.Chore.ScheduleKind,
+			&i.Chore.ScheduleIntervalDays,
+			&i.Chore.ScheduleWeekdays,
+			&i.Chore.ScheduleMonthDay,
+			&i.Chore.Difficulty,
+			&i.Chore.Archived,
 		); err != nil {
 			return nil, err
 		}
@@ -166,12 +211,13 @@ func (q *Queries) GetUserTasks(ctx context.Context, id int32) ([]GetUserTasksRow
 }
 
 const listTasks = `-- name: ListTasks :many
-SELECT id, user_id, chore_id, started_at, duration_mn, description FROM tasks
+SELECT id, user_id, chore_id, started_at, duration_mn, description, household_id, points FROM tasks
+WHERE household_id = $1
 ORDER BY started_at
 `
 
-func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
-	rows, err := q.db.Query(ctx, listTasks)
+func (q *Queries) ListTasks(ctx context.Context, householdID int32) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTasks, householdID)
 	if err != nil {
 		return nil, err
 	}
@@ -186,6 +232,8 @@ func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
 			&i.StartedAt,
 			&i.DurationMn,
 			&i.Description,
+			&i.HouseholdID,
+			&i.Points,
 		); err != nil {
 			return nil, err
 		}
@@ -198,10 +246,11 @@ func (q *Queries) ListTasks(ctx context.Context) ([]Task, error) {
 }
 
 const listUsersTasks = `-- name: ListUsersTasks :many
-SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, chores.id, chores.name, chores.description, chores.default_duration_mn, users.id, users.name
+SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, tasks.household_id, tasks.points, chores.id, chores.name, chores.description, chores.default_duration_mn, chores.household_id, chores.schedule_kind, chores.schedule_interval_days, chores.schedule_weekdays, chores.schedule_month_day, chores.difficulty, chores.archived, users.id, users.name, users.household_id, users.share, users.archived
 FROM tasks
 JOIN chores ON tasks.chore_id = chores.id
 JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = $1
 ORDER BY tasks.started_at DESC
 `
 
@@ -211,8 +260,8 @@ type ListUsersTasksRow struct {
 	User  User
 }
 
-func (q *Queries) ListUsersTasks(ctx context.Context) ([]ListUsersTasksRow, error) {
-	rows, err := q.db.Query(ctx, listUsersTasks)
+func (q *Queries) ListUsersTasks(ctx context.Context, householdID int32) ([]ListUsersTasksRow, error) {
+	rows, err := q.db.Query(ctx, listUsersTasks, householdID)
 	if err != nil {
 		return nil, err
 	}
@@ -227,12 +276,203 @@ func (q *Queries) ListUsersTasks(ctx context.Context) ([]ListUsersTasksRow, erro
 			&i.Task.StartedAt,
 			&i.Task.DurationMn,
 			&i.Task.Description,
+			&i.Task.HouseholdID,
+			&i.Task.Points,
+			&i.Chore.ID,
+			&i.Chore.Name,
+			&i.Chore.Description,
+			&i.Chore.DefaultDurationMn,
+			&i.Chore.HouseholdID,
+			&i.Chore.ScheduleKind,
+			&i.Chore.ScheduleIntervalDays,
+			&i.Chore.ScheduleWeekdays,
+			&i.Chore.ScheduleMonthDay,
+			&i.Chore.Difficulty,
+			&i.Chore.Archived,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersTasksBetween = `-- name: ListUsersTasksBetween :many
+SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, tasks.household_id, tasks.points, chores.id, chores.name, chores.description, chores.default_duration_mn, chores.household_id, chores.schedule_kind, chores.schedule_interval_days, chores.schedule_weekdays, chores.schedule_month_day, chores.difficulty, chores.archived, users.id, users.name, users.household_id, users.share, users.archived
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = $1
+    AND ($2::int = 0 OR tasks.user_id = $2)
+    AND tasks.started_at >= $3 AND tasks.started_at <= $4
+ORDER BY tasks.started_at DESC
+`
+
+type ListUsersTasksBetweenParams struct {
+	HouseholdID int32
+	UserID      int32
+	NotBefore   time.Time
+	NotAfter    time.Time
+}
+
+type ListUsersTasksBetweenRow struct {
+	Task  Task
+	Chore Chore
+	User  User
+}
+
+func (q *Queries) ListUsersTasksBetween(ctx context.Context, arg ListUsersTasksBetweenParams) ([]ListUsersTasksBetweenRow, error) {
+	rows, err := q.db.Query(ctx, listUsersTasksBetween,
+		arg.HouseholdID,
+		arg.UserID,
+		arg.NotBefore,
+		arg.NotAfter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersTasksBetweenRow
+	for rows.Next() {
+		var i ListUsersTasksBetweenRow
+		if err := rows.Scan(
+			&i.Task.ID,
+			&i.Task.UserID,
+			&i.Task.ChoreID,
+			&i.Task.StartedAt,
+			&i.Task.DurationMn,
+			&i.Task.Description,
+			&i.Task.HouseholdID,
+			&i.Task.Points,
+			&i.Chore.ID,
+			&i.Chore.Name,
+			&i.Chore.Description,
+			&i.Chore.DefaultDurationMn,
+			&i.Chore.HouseholdID,
+			&i.Chore.ScheduleKind,
+			&i.Chore.ScheduleIntervalDays,
+			&i.Chore.ScheduleWeekdays,
+			&i.Chore.ScheduleMonthDay,
+			&i.Chore.Difficulty,
+			&i.Chore.Archived,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTasks = `-- name: SearchTasks :many
+SELECT tasks.id, tasks.user_id, tasks.chore_id, tasks.started_at, tasks.duration_mn, tasks.description, tasks.household_id, tasks.points, chores.id, chores.name, chores.description, chores.default_duration_mn, chores.household_id, chores.schedule_kind, chores.schedule_interval_days, chores.schedule_weekdays, chores.schedule_month_day, chores.difficulty, chores.archived, users.id, users.name, users.household_id, users.share, users.archived
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = $1
+    AND ($2::int = 0 OR tasks.user_id = $2)
+    AND ($3::int = 0 OR tasks.chore_id = $3)
+    AND tasks.started_at >= $4 AND tasks.started_at < $5
+    AND strpos(lower(tasks.description), lower($6::text)) > 0
+    AND (NOT $7::boolean
+        OR ($8::boolean AND (CASE $9::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END, tasks.started_at, tasks.id) < ($10::bigint, $11::timestamptz, $12::uuid))
+        OR (NOT $8::boolean AND (CASE $9::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END, tasks.started_at, tasks.id) > ($10::bigint, $11::timestamptz, $12::uuid)))
+ORDER BY
+    CASE WHEN $8::boolean THEN CASE $9::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END END DESC,
+    CASE WHEN $8::boolean THEN tasks.started_at END DESC,
+    CASE WHEN $8::boolean THEN tasks.id END DESC,
+    CASE $9::text WHEN 'duration' THEN tasks.duration_mn::bigint WHEN 'points' THEN tasks.points ELSE 0 END, tasks.started_at, tasks.id
+LIMIT $13
+`
+
+type SearchTasksParams struct {
+	HouseholdID    int32
+	UserID         int32
+	ChoreID        int32
+	NotBefore      time.Time
+	NotAfter       time.Time
+	Search         string
+	After          bool
+	Descending     bool
+	Sort           string
+	AfterKey       int64
+	AfterStartedAt time.Time
+	AfterID        uuid.UUID
+	MaxTasks       int32
+}
+
+type SearchTasksRow struct {
+	Task  Task
+	Chore Chore
+	User  User
+}
+
+// Tasks are sorted on a key, the duration, the points or 0 to sort on the start
+// time only, then on their start time and ID. The page after a task is
+// selected with the values of its sort columns.
+func (q *Queries) SearchTasks(ctx context.Context, arg SearchTasksParams) ([]SearchTasksRow, error) {
+	rows, err := q.db.Query(ctx, searchTasks,
+		arg.HouseholdID,
+		arg.UserID,
+		arg.ChoreID,
+		arg.NotBefore,
+		arg.NotAfter,
+		arg.Search,
+		arg.After,
+		arg.Descending,
+		arg.Sort,
+		arg.AfterKey,
+		arg.AfterStartedAt,
+		arg.AfterID,
+		arg.MaxTasks,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchTasksRow
+	for rows.Next() {
+		var i SearchTasksRow
+		if err := rows.Scan(
+			&i.Task.ID,
+			&i.Task.UserID,
+			&i.Task.ChoreID,
+			&i.Task.StartedAt,
+			&i.Task.DurationMn,
+			&i.Task.Description,
+			&i.Task.HouseholdID,
+			&i.Task.Points,
 			&i.Chore.ID,
 			&i.Chore.Name,
 			&i.Chore.Description,
 			&i.Chore.DefaultDurationMn,
+			&i.Chore.HouseholdID,
+			&i.Chore.ScheduleKind,
+			&i.Chore.ScheduleIntervalDays,
+			&i.Chore.ScheduleWeekdays,
+			&i.Chore.ScheduleMonthDay,
+			&i.Chore.Difficulty,
+			&i.Chore.Archived,
 			&i.User.ID,
 			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
 		); err != nil {
 			return nil, err
 		}
@@ -245,27 +485,30 @@ func (q *Queries) ListUsersTasks(ctx context.Context) ([]ListUsersTasksRow, erro
 }
 
 const tasksReport = `-- name: TasksReport :many
-SELECT users.id, users.name, chores.id, chores.name, chores.description, chores.default_duration_mn, SUM(duration_mn)
+SELECT users.id, users.name, users.household_id, users.share, users.archived, chores.id, chores.name, chores.description, chores.default_duration_mn, chores.household_id, chores.schedule_kind, chores.schedule_interval_days, chores.schedule_weekdays, chores.schedule_month_day, chores.difficulty, chores.archived, SUM(tasks.duration_mn)::bigint AS minutes, COUNT(*) AS tasks_count, SUM(tasks.points)::bigint AS points
 FROM tasks
 JOIN chores ON tasks.chore_id = chores.id
 JOIN users ON tasks.user_id = users.id
-WHERE tasks.started_at > $1 AND tasks.started_at < $2
+WHERE tasks.household_id = $1 AND tasks.started_at > $2 AND tasks.started_at < $3
 GROUP BY chores.id, users.id
 `
 
 type TasksReportParams struct {
-	NotBefore time.Time
-	NotAfter  time.Time
+	HouseholdID int32
+	NotBefore   time.Time
+	NotAfter    time.Time
 }
 
 type TasksReportRow struct {
-	User  User
-	Chore Chore
-	Sum   int64
+	User       User
+	Chore      Chore
+	Minutes    int64
+	TasksCount int64
+	Points     int64
 }
 
 func (q *Queries) TasksReport(ctx context.Context, arg TasksReportParams) ([]TasksReportRow, error) {
-	rows, err := q.db.Query(ctx, tasksReport, arg.NotBefore, arg.NotAfter)
+	rows, err := q.db.Query(ctx, tasksReport, arg.HouseholdID, arg.NotBefore, arg.NotAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -276,11 +519,84 @@ func (q *Queries) TasksReport(ctx context.Context, arg TasksReportParams) ([]Tas
 		if err := rows.Scan(
 			&i.User.ID,
 			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
 			&i.Chore.ID,
 			&i.Chore.Name,
 			&i.Chore.Description,
 			&i.Chore.DefaultDurationMn,
-			&i.Sum,
+			&i.Chore.HouseholdID,
+			&i.Chore.ScheduleKind,
+			&i.Chore.ScheduleIntervalDays,
+			&i.Chore.ScheduleWeekdays,
+			&i.Chore.ScheduleMonthDay,
+			&i.Chore.Difficulty,
+			&i.Chore.Archived,
+			&i.Minutes,
+			&i.TasksCount,
+			&i.Points,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tasksTimeline = `-- name: TasksTimeline :many
+SELECT users.id, users.name, users.household_id, users.share, users.archived, date_trunc($1::text, tasks.started_at, $2::text)::timestamptz AS period, SUM(tasks.duration_mn)::bigint AS minutes, COUNT(*) AS tasks_count, SUM(tasks.points)::bigint AS points
+FROM tasks
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = $3 AND tasks.started_at > $4 AND tasks.started_at < $5
+GROUP BY users.id, period
+ORDER BY period, users.id
+`
+
+type TasksTimelineParams struct {
+	Unit        string
+	TimeZone    string
+	HouseholdID int32
+	NotBefore   time.Time
+	NotAfter    time.Time
+}
+
+type TasksTimelineRow struct {
+	User       User
+	Period     time.Time
+	Minutes    int64
+	TasksCount int64
+	Points     int64
+}
+
+func (q *Queries) TasksTimeline(ctx context.Context, arg TasksTimelineParams) ([]TasksTimelineRow, error) {
+	rows, err := q.db.Query(ctx, tasksTimeline,
+		arg.Unit,
+		arg.TimeZone,
+		arg.HouseholdID,
+		arg.NotBefore,
+		arg.NotAfter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TasksTimelineRow
+	for rows.Next() {
+		var i TasksTimelineRow
+		if err := rows.Scan(
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
+			&i.Period,
+			&i.Minutes,
+			&i.TasksCount,
+			&i.Points,
 		); err != nil {
 			return nil, err
 		}
@@ -294,32 +610,37 @@ func (q *Queries) TasksReport(ctx context.Context, arg TasksReportParams) ([]Tas
 
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks SET 
-user_id = $2,
-chore_id = $3,
-started_at = $4,
-duration_mn = $5,
-description = $6
-WHERE id = $1
-RETURNING id, user_id, chore_id, started_at, duration_mn, description
+user_id = $3,
+chore_id = $4,
+started_at = $5,
+duration_mn = $6,
+description = $7,
+points = $8
+WHERE household_id = $1 AND id = $2
+RETURNING id, user_id, chore_id, started_at, duration_mn, description, household_id, points
 `
 
 type UpdateTaskParams struct {
+	HouseholdID int32
 	ID          uuid.UUID
 	UserID      int32
 	ChoreID     int32
 	StartedAt   time.Time
 	DurationMn  int32
 	Description string
+	Points      int64
 }
 
 func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error) {
 	row := q.db.QueryRow(ctx, updateTask,
+		arg.HouseholdID,
 		arg.ID,
 		arg.UserID,
 		arg.ChoreID,
 		arg.StartedAt,
 		arg.DurationMn,
 		arg.Description,
+		arg.Points,
 	)
 	var i Task
 	err := row.Scan(
@@ -329,6 +650,8 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		&i.StartedAt,
 		&i.DurationMn,
 		&i.Description,
+		&i.HouseholdID,
+		&i.Points,
 	)
 	return i, err
 }
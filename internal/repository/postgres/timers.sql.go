@@ -0,0 +1,212 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: timers.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createTimer = `-- name: CreateTimer :one
+INSERT INTO timers (
+    household_id, user_id, chore_id, started_at, resumed_at
+) VALUES (
+    $1, $2, $3, $4, $4
+)
+RETURNING id, household_id, user_id, chore_id, started_at, resumed_at, elapsed_seconds
+`
+
+type CreateTimerParams struct {
+	HouseholdID int32
+	UserID      int32
+	ChoreID     int32
+	StartedAt   time.Time
+}
+
+func (q *Queries) CreateTimer(ctx context.Context, arg CreateTimerParams) (Timer, error) {
+	row := q.db.QueryRow(ctx, createTimer,
+		arg.HouseholdID,
+		arg.UserID,
+		arg.ChoreID,
+		arg.StartedAt,
+	)
+	var i Timer
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.ChoreID,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.ElapsedSeconds,
+	)
+	return i, err
+}
+
+const deleteTimer = `-- name: DeleteTimer :execrows
+DELETE FROM timers
+WHERE household_id = $1 AND id = $2
+`
+
+type DeleteTimerParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) DeleteTimer(ctx context.Context, arg DeleteTimerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTimer, arg.HouseholdID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getTimer = `-- name: GetTimer :one
+SELECT id, household_id, user_id, chore_id, started_at, resumed_at, elapsed_seconds FROM timers
+WHERE household_id = $1 AND id = $2
+`
+
+type GetTimerParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) GetTimer(ctx context.Context, arg GetTimerParams) (Timer, error) {
+	row := q.db.QueryRow(ctx, getTimer, arg.HouseholdID, arg.ID)
+	var i Timer
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.ChoreID,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.ElapsedSeconds,
+	)
+	return i, err
+}
+
+const getTimerForUpdate = `-- name: GetTimerForUpdate :one
+SELECT id, household_id, user_id, chore_id, started_at, resumed_at, elapsed_seconds FROM timers
+WHERE household_id = $1 AND id = $2
+FOR UPDATE
+`
+
+type GetTimerForUpdateParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) GetTimerForUpdate(ctx context.Context, arg GetTimerForUpdateParams) (Timer, error) {
+	row := q.db.QueryRow(ctx, getTimerForUpdate, arg.HouseholdID, arg.ID)
+	var i Timer
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.ChoreID,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.ElapsedSeconds,
+	)
+	return i, err
+}
+
+const listTimers = `-- name: ListTimers :many
+SELECT timers.id, timers.household_id, timers.user_id, timers.chore_id, timers.started_at, timers.resumed_at, timers.elapsed_seconds, chores.id, chores.name, chores.description, chores.default_duration_mn, chores.household_id, chores.schedule_kind, chores.schedule_interval_days, chores.schedule_weekdays, chores.schedule_month_day, chores.difficulty, chores.archived, users.id, users.name, users.household_id, users.share, users.archived
+FROM timers
+JOIN chores ON timers.chore_id = chores.id
+JOIN users ON timers.user_id = users.id
+WHERE timers.household_id = $1
+ORDER BY timers.started_at
+`
+
+type ListTimersRow struct {
+	Timer Timer
+	Chore Chore
+	User  User
+}
+
+func (q *Queries) ListTimers(ctx context.Context, householdID int32) ([]ListTimersRow, error) {
+	rows, err := q.db.Query(ctx, listTimers, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTimersRow
+	for rows.Next() {
+		var i ListTimersRow
+		if err := rows.Scan(
+			&i.Timer.ID,
+			&i.Timer.HouseholdID,
+			&i.Timer.UserID,
+			&i.Timer.ChoreID,
+			&i.Timer.StartedAt,
+			&i.Timer.ResumedAt,
+			&i.Timer.ElapsedSeconds,
+			&i.Chore.ID,
+			&i.Chore.Name,
+			&i.Chore.Description,
+			&i.Chore.DefaultDurationMn,
+			&i.Chore.HouseholdID,
+			&i.Chore.ScheduleKind,
+			&i.Chore.ScheduleIntervalDays,
+			&i.Chore.ScheduleWeekdays,
+			&i.Chore.ScheduleMonthDay,
+			&i.Chore.Difficulty,
+			&i.Chore.Archived,
+			&i.User.ID,
+			&i.User.Name,
+			&i.User.HouseholdID,
+			&i.User.Share,
+			&i.User.Archived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTimer = `-- name: UpdateTimer :one
+UPDATE timers SET
+resumed_at = $3,
+elapsed_seconds = $4
+WHERE household_id = $1 AND id = $2
+RETURNING id, household_id, user_id, chore_id, started_at, resumed_at, elapsed_seconds
+`
+
+type UpdateTimerParams struct {
+	HouseholdID    int32
+	ID             int32
+	ResumedAt      pgtype.Timestamptz
+	ElapsedSeconds int64
+}
+
+func (q *Queries) UpdateTimer(ctx context.Context, arg UpdateTimerParams) (Timer, error) {
+	row := q.db.QueryRow(ctx, updateTimer,
+		arg.HouseholdID,
+		arg.ID,
+		arg.ResumedAt,
+		arg.ElapsedSeconds,
+	)
+	var i Timer
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.UserID,
+		&i.ChoreID,
+		&i.StartedAt,
+		&i.ResumedAt,
+		&i.ElapsedSeconds,
+	)
+	return i, err
+}
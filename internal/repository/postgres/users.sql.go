@@ -11,49 +11,81 @@ import (
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
-    name
+    household_id, name, share
 ) VALUES (
-    $1
+    $1, $2, $3
 )
-RETURNING id, name
+RETURNING id, name, household_id, share, archived
 `
 
-func (q *Queries) CreateUser(ctx context.Context, name string) (User, error) {
-	row := q.db.QueryRow(ctx, createUser, name)
+type CreateUserParams struct {
+	HouseholdID int32
+	Name        string
+	Share       int32
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser, arg.HouseholdID, arg.Name, arg.Share)
 	var i User
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HouseholdID,
+		&i.Share,
+		&i.Archived,
+	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
+const deleteUser = `-- name: DeleteUser :execrows
 DELETE FROM users
-WHERE id = $1
+WHERE household_id = $1 AND id = $2
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+type DeleteUserParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, arg.HouseholdID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name FROM users
-WHERE id = $1
+SELECT id, name, household_id, share, archived FROM users
+WHERE household_id = $1 AND id = $2
 `
 
-func (q *Queries) GetUser(ctx context.Context, id int32) (User, error) {
-	row := q.db.QueryRow(ctx, getUser, id)
+type GetUserParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) GetUser(ctx context.Context, arg GetUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, getUser, arg.HouseholdID, arg.ID)
 	var i User
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HouseholdID,
+		&i.Share,
+		&i.Archived,
+	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, name FROM users
+SELECT id, name, household_id, share, archived FROM users
+WHERE household_id = $1
 ORDER BY name
 `
 
-func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
-	rows, err := q.db.Query(ctx, listUsers)
+func (q *Queries) ListUsers(ctx context.Context, householdID int32) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers, householdID)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +93,13 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	var items []User
 	for rows.Next() {
 		var i User
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.HouseholdID,
+			&i.Share,
+			&i.Archived,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -72,21 +110,103 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const reassignUserTasks = `-- name: ReassignUserTasks :many
+UPDATE tasks SET
+user_id = $1
+WHERE household_id = $2 AND user_id = $3
+RETURNING id, user_id, chore_id, started_at, duration_mn, description, household_id, points
+`
+
+type ReassignUserTasksParams struct {
+	ToUserID    int32
+	HouseholdID int32
+	FromUserID  int32
+}
+
+func (q *Queries) ReassignUserTasks(ctx context.Context, arg ReassignUserTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, reassignUserTasks, arg.ToUserID, arg.HouseholdID, arg.FromUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ChoreID,
+			&i.StartedAt,
+			&i.DurationMn,
+			&i.Description,
+			&i.HouseholdID,
+			&i.Points,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserArchived = `-- name: SetUserArchived :one
+UPDATE users SET
+archived = $3
+WHERE household_id = $1 AND id = $2
+RETURNING id, name, household_id, share, archived
+`
+
+type SetUserArchivedParams struct {
+	HouseholdID int32
+	ID          int32
+	Archived    bool
+}
+
+func (q *Queries) SetUserArchived(ctx context.Context, arg SetUserArchivedParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserArchived, arg.HouseholdID, arg.ID, arg.Archived)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HouseholdID,
+		&i.Share,
+		&i.Archived,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users SET 
-name = $2
-WHERE id = $1
-RETURNING id, name
+name = $3,
+share = $4
+WHERE household_id = $1 AND id = $2
+RETURNING id, name, household_id, share, archived
 `
 
 type UpdateUserParams struct {
-	ID   int32
-	Name string
+	HouseholdID int32
+	ID          int32
+	Name        string
+	Share       int32
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUser, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateUser,
+		arg.HouseholdID,
+		arg.ID,
+		arg.Name,
+		arg.Share,
+	)
 	var i User
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HouseholdID,
+		&i.Share,
+		&i.Archived,
+	)
 	return i, err
 }
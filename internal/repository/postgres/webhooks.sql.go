@@ -0,0 +1,339 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: webhooks.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimWebhookDeliveries = `-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries SET
+next_attempt_at = $1
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id AND webhook_deliveries.id IN (
+    SELECT pending.id FROM webhook_deliveries AS pending
+    WHERE pending.status = 'pending' AND pending.next_attempt_at <= $2
+    ORDER BY pending.next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING webhook_deliveries.id, webhook_deliveries.event, webhook_deliveries.payload, webhook_deliveries.attempts, webhooks.url, webhooks.secret
+`
+
+type ClaimWebhookDeliveriesParams struct {
+	LeaseUntil    time.Time
+	Now           time.Time
+	MaxDeliveries int32
+}
+
+type ClaimWebhookDeliveriesRow struct {
+	ID       uuid.UUID
+	Event    string
+	Payload  []byte
+	Attempts int32
+	Url      string
+	Secret   string
+}
+
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimWebhookDeliveries, arg.LeaseUntil, arg.Now, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimWebhookDeliveriesRow
+	for rows.Next() {
+		var i ClaimWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Event,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (
+    household_id, url, secret, events
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, household_id, url, secret, events, created_at
+`
+
+type CreateWebhookParams struct {
+	HouseholdID int32
+	Url         string
+	Secret      string
+	Events      []string
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, createWebhook,
+		arg.HouseholdID,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE household_id = $1 AND id = $2
+`
+
+type DeleteWebhookParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) error {
+	_, err := q.db.Exec(ctx, deleteWebhook, arg.HouseholdID, arg.ID)
+	return err
+}
+
+const enqueueWebhookDeliveries = `-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+SELECT webhooks.id, $1::text, $2::jsonb
+FROM webhooks
+WHERE webhooks.household_id = $3 AND $4::text = ANY(webhooks.events)
+`
+
+type EnqueueWebhookDeliveriesParams struct {
+	Event       string
+	Payload     []byte
+	HouseholdID int32
+	Resource    string
+}
+
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) error {
+	_, err := q.db.Exec(ctx, enqueueWebhookDeliveries,
+		arg.Event,
+		arg.Payload,
+		arg.HouseholdID,
+		arg.Resource,
+	)
+	return err
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, household_id, url, secret, events, created_at FROM webhooks
+WHERE household_id = $1 AND id = $2
+`
+
+type GetWebhookParams struct {
+	HouseholdID int32
+	ID          int32
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, arg.HouseholdID, arg.ID)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListWebhookDeliveriesParams struct {
+	WebhookID int32
+	Limit     int32
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.WebhookID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.Event,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT id, household_id, url, secret, events, created_at FROM webhooks
+WHERE household_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListWebhooks(ctx context.Context, householdID int32) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, listWebhooks, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.HouseholdID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const retryWebhookDelivery = `-- name: RetryWebhookDelivery :execrows
+UPDATE webhook_deliveries SET
+status = 'pending',
+attempts = 0,
+last_error = '',
+next_attempt_at = now()
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id AND webhooks.household_id = $1
+    AND webhook_deliveries.webhook_id = $2 AND webhook_deliveries.id = $3
+`
+
+type RetryWebhookDeliveryParams struct {
+	HouseholdID int32
+	WebhookID   int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, retryWebhookDelivery, arg.HouseholdID, arg.WebhookID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks SET
+url = $3,
+secret = $4,
+events = $5
+WHERE household_id = $1 AND id = $2
+RETURNING id, household_id, url, secret, events, created_at
+`
+
+type UpdateWebhookParams struct {
+	HouseholdID int32
+	ID          int32
+	Url         string
+	Secret      string
+	Events      []string
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, updateWebhook,
+		arg.HouseholdID,
+		arg.ID,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.HouseholdID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries SET
+status = $2,
+attempts = attempts + 1,
+next_attempt_at = $3,
+last_status_code = $4,
+last_error = $5,
+delivered_at = $6
+WHERE id = $1
+`
+
+type UpdateWebhookDeliveryParams struct {
+	ID             uuid.UUID
+	Status         string
+	NextAttemptAt  time.Time
+	LastStatusCode int32
+	LastError      string
+	DeliveredAt    pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, updateWebhookDelivery,
+		arg.ID,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastStatusCode,
+		arg.LastError,
+		arg.DeliveredAt,
+	)
+	return err
+}
@@ -1,18 +1,60 @@
 package repository
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"slices"
 	"strings"
 	"time"
 
+	"github.com/mqufflc/whodidthechores/internal/database"
 	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
 )
 
+// ReportMetric is what a report adds up for each chore and user.
+type ReportMetric string
+
+const (
+	MetricMinutes ReportMetric = "minutes"
+	MetricTasks   ReportMetric = "tasks"
+	MetricPoints  ReportMetric = "points"
+)
+
+// ReportMetrics lists the metrics a report can use, the default first.
+var ReportMetrics = []ReportMetric{MetricMinutes, MetricTasks, MetricPoints}
+
+// ParseReportMetric returns the metric with the given name, the minutes when
+// it is empty.
+func ParseReportMetric(name string) (ReportMetric, error) {
+	if name == "" {
+		return MetricMinutes, nil
+	}
+	metric := ReportMetric(name)
+	if !slices.Contains(ReportMetrics, metric) {
+		return "", fmt.Errorf("%w: unknown report metric %q", ErrValidation, name)
+	}
+	return metric, nil
+}
+
 type TaskReport struct {
-	User  User  `json:"user"`
-	Chore Chore `json:"chore"`
-	Sum   int64 `json:"sum"`
+	User    User  `json:"user"`
+	Chore   Chore `json:"chore"`
+	Minutes int64 `json:"minutes"`
+	Tasks   int64 `json:"tasks"`
+	Points  int64 `json:"points"`
+}
+
+// Sum returns the value of the metric for the tasks of the chore and user.
+func (t TaskReport) Sum(metric ReportMetric) int64 {
+	switch metric {
+	case MetricTasks:
+		return t.Tasks
+	case MetricPoints:
+		return t.Points
+	default:
+		return t.Minutes
+	}
 }
 
 type SingleChoreReport struct {
@@ -26,22 +68,26 @@ type SingleUserReport struct {
 }
 
 type Report struct {
+	Metric ReportMetric                `json:"metric"`
 	Report map[string]map[string]int64 `json:"report"`
 	Users  []string                    `json:"users"`
 	Chores []string                    `json:"chores"`
 }
 
-func GenerateUserReport(tasks []TaskReport) map[string][]SingleChoreReport {
+// GenerateUserReport adds up the metric of the tasks by user, with the sum of
+// each of their chores.
+func GenerateUserReport(tasks []TaskReport, metric ReportMetric) map[string][]SingleChoreReport {
 	report := make(map[string][]SingleChoreReport)
 	for _, task := range tasks {
-		newReport := SingleChoreReport{Chore: task.Chore.Name, Sum: task.Sum}
+		newReport := SingleChoreReport{Chore: task.Chore.Name, Sum: task.Sum(metric)}
 		existingReport := report[task.User.Name]
 		report[task.User.Name] = append(existingReport, newReport)
 	}
 	return report
 }
 
-func GenerateReport(tasks []TaskReport) Report {
+// GenerateReport adds up the metric of the tasks by chore and user.
+func GenerateReport(tasks []TaskReport, metric ReportMetric) Report {
 	report := make(map[string]map[string]int64)
 	var users []string
 	var chores []string
@@ -56,7 +102,7 @@ func GenerateReport(tasks []TaskReport) Report {
 		if !ok {
 			existingReport = make(map[string]int64)
 		}
-		existingReport[task.User.Name] = task.Sum
+		existingReport[task.User.Name] = task.Sum(metric)
 		report[task.Chore.Name] = existingReport
 	}
 	slices.SortFunc(users, func(a, b string) int {
@@ -66,27 +112,107 @@ func GenerateReport(tasks []TaskReport) Report {
 		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
 	})
 	return Report{
+		Metric: metric,
 		Report: report,
 		Users:  users,
 		Chores: chores,
 	}
 }
 
-func (r *Repository) GetChoreReport(ctx context.Context, start time.Time, end time.Time) (Report, error) {
-	reports, err := r.q.TasksReport(ctx, postgres.TasksReportParams{NotBefore: start, NotAfter: end})
+func (r *Repository) GetChoreReport(ctx context.Context, start time.Time, end time.Time, metric ReportMetric) (Report, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	choreReports, err := r.taskReports(ctx, householdID, start, end)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
-			return Report{}, sqlErr
-		}
 		return Report{}, err
 	}
-	choreReports := make([]TaskReport, len(reports))
+	return GenerateReport(choreReports, metric), nil
+}
+
+// taskReports returns the tasks of the household started between start and
+// end, added up by chore and user.
+func (r *Repository) taskReports(ctx context.Context, householdID int32, start time.Time, end time.Time) ([]TaskReport, error) {
+	reports, err := r.q.TasksReport(ctx, postgres.TasksReportParams{HouseholdID: householdID, NotBefore: start, NotAfter: end})
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	taskReports := make([]TaskReport, len(reports))
 	for index, report := range reports {
-		choreReports[index] = TaskReport{
-			User:  User(report.User),
-			Chore: Chore(report.Chore),
-			Sum:   report.Sum,
+		taskReports[index] = TaskReport{
+			User:    User(report.User),
+			Chore:   Chore(report.Chore),
+			Minutes: report.Minutes,
+			Tasks:   report.TasksCount,
+			Points:  report.Points,
+		}
+	}
+	return taskReports, nil
+}
+
+// UserChoreReport adds up the minutes the user spent on each of their chores,
+// the chores they spent the most time on first.
+func UserChoreReport(user User, rows []postgres.GetUserTasksRow) []SingleChoreReport {
+	var tasks []TaskReport
+	for _, row := range rows {
+		index := slices.IndexFunc(tasks, func(task TaskReport) bool { return task.Chore.ID == row.Chore.ID })
+		if index < 0 {
+			index = len(tasks)
+			tasks = append(tasks, TaskReport{User: user, Chore: Chore(row.Chore)})
+		}
+		tasks[index].Minutes += int64(row.Task.DurationMn)
+		tasks[index].Tasks++
+		tasks[index].Points += row.Task.Points
+	}
+	report := GenerateUserReport(tasks, MetricMinutes)[user.Name]
+	slices.SortStableFunc(report, func(a, b SingleChoreReport) int {
+		return cmp.Compare(b.Sum, a.Sum)
+	})
+	return report
+}
+
+// DailyActivity adds up the minutes of the tasks by day in the timezone, the
+// days formatted as 2006-01-02.
+func DailyActivity(tasks []postgres.Task, timezone *time.Location) map[string]int64 {
+	activity := make(map[string]int64)
+	for _, task := range tasks {
+		activity[task.StartedAt.In(timezone).Format(time.DateOnly)] += int64(task.DurationMn)
+	}
+	return activity
+}
+
+// ChoreTimeline counts the tasks of a chore by user and by month, from the
+// month of its first task to now.
+func ChoreTimeline(rows []postgres.GetChoreTasksRow, now time.Time, timezone *time.Location) (Timeline, error) {
+	start := now
+	type key struct {
+		userID int32
+		period int64
+	}
+	indexes := map[key]int{}
+	var reports []PeriodReport
+	for _, row := range rows {
+		if row.Task.StartedAt.Before(start) {
+			start = row.Task.StartedAt
+		}
+		period, err := database.DateTrunc(string(BucketMonth), row.Task.StartedAt, timezone)
+		if err != nil {
+			return Timeline{}, err
+		}
+		k := key{row.User.ID, period.Unix()}
+		index, ok := indexes[k]
+		if !ok {
+			index = len(reports)
+			indexes[k] = index
+			reports = append(reports, PeriodReport{User: User(row.User), Period: period})
 		}
+		reports[index].Minutes += int64(row.Task.DurationMn)
+		reports[index].Tasks++
+		reports[index].Points += row.Task.Points
 	}
-	return GenerateReport(choreReports), nil
+	return GenerateTimeline(reports, start, now, BucketMonth, MetricTasks, timezone)
 }
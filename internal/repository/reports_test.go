@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetailReports(t *testing.T) {
+	alice := postgres.User{ID: 1, Name: "Alice", Share: 1}
+	bob := postgres.User{ID: 2, Name: "Bob", Share: 1}
+	dishes := postgres.Chore{ID: 1, Name: "Dishes"}
+	laundry := postgres.Chore{ID: 2, Name: "Laundry"}
+	tasks := []postgres.Task{
+		{UserID: 1, ChoreID: 1, StartedAt: time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC), DurationMn: 10},
+		{UserID: 1, ChoreID: 2, StartedAt: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC), DurationMn: 40},
+		{UserID: 1, ChoreID: 1, StartedAt: time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC), DurationMn: 15},
+	}
+
+	userRows := []postgres.GetUserTasksRow{{Task: tasks[0], Chore: dishes}, {Task: tasks[1], Chore: laundry}, {Task: tasks[2], Chore: dishes}}
+	assert.Equal(t, []SingleChoreReport{{Chore: "Laundry", Sum: 40}, {Chore: "Dishes", Sum: 25}}, UserChoreReport(User(alice), userRows))
+
+	// The first task is on the next day in Paris.
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"2024-05-02": 50, "2024-03-12": 15}, DailyActivity(tasks, paris))
+
+	choreRows := []postgres.GetChoreTasksRow{{Task: tasks[0], User: alice}, {Task: tasks[2], User: bob}, {Task: tasks[2], User: bob}}
+	timeline, err := ChoreTimeline(choreRows, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), time.UTC)
+	require.NoError(t, err)
+	assert.Len(t, timeline.Periods, 4)
+	assert.Equal(t, MetricTasks, timeline.Metric)
+	assert.Equal(t, map[string][]int64{"Alice": {0, 0, 1, 0}, "Bob": {2, 0, 0, 0}}, timeline.Series)
+}
@@ -1,22 +1,40 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
 )
 
 type Repository struct {
-	db *pgxpool.Pool
-	q  *postgres.Queries
+	db Store
+	q  postgres.Querier
+	// subscribers receive the changes listened to by ListenChanges.
+	subscribers *subscribers
 }
 
+// NewRepositoryParams gives the database of the repository: a Store, or a
+// PostgreSQL pool when Store is nil.
 type NewRepositoryParams struct {
-	DB *pgxpool.Pool
+	DB    *pgxpool.Pool
+	Store Store
 }
 
 func New(p NewRepositoryParams) *Repository {
+	store := p.Store
+	if store == nil {
+		store = NewPostgresStore(p.DB)
+	}
 	return &Repository{
-		db: p.DB,
-		q:  postgres.New(p.DB),
+		db:          store,
+		q:           store,
+		subscribers: &subscribers{},
 	}
 }
+
+// withTx runs fn with queries bound to a transaction, committed when fn
+// succeeds.
+func (r *Repository) withTx(ctx context.Context, fn func(q postgres.Querier) error) error {
+	return r.db.InTx(ctx, fn)
+}
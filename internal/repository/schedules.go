@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+const (
+	ScheduleNone     = "none"
+	ScheduleInterval = "interval"
+	ScheduleWeekly   = "weekly"
+	ScheduleMonthly  = "monthly"
+)
+
+type DueStatus int
+
+const (
+	NotScheduled DueStatus = iota
+	Upcoming
+	DueToday
+	Overdue
+)
+
+func (s DueStatus) String() string {
+	switch s {
+	case Upcoming:
+		return "upcoming"
+	case DueToday:
+		return "due"
+	case Overdue:
+		return "overdue"
+	}
+	return "not scheduled"
+}
+
+// Schedule describes when a chore should be done again.
+type Schedule struct {
+	Kind         string
+	IntervalDays int32
+	// Weekdays is a bitmask of the days of the week, bit 0 being Sunday.
+	Weekdays int32
+	MonthDay int32
+}
+
+func ChoreSchedule(chore postgres.Chore) Schedule {
+	return Schedule{
+		Kind:         chore.ScheduleKind,
+		IntervalDays: chore.ScheduleIntervalDays,
+		Weekdays:     chore.ScheduleWeekdays,
+		MonthDay:     chore.ScheduleMonthDay,
+	}
+}
+
+func (s Schedule) HasWeekday(day time.Weekday) bool {
+	return s.Weekdays&(1<<day) != 0
+}
+
+func startOfDay(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// NextDue returns the day, at midnight in the given location, at which a
+// chore last done at last is due again. It returns false when the schedule
+// never makes the chore due.
+func (s Schedule) NextDue(last time.Time, location *time.Location) (time.Time, bool) {
+	lastDay := startOfDay(last, location)
+	switch s.Kind {
+	case ScheduleInterval:
+		if s.IntervalDays <= 0 {
+			return time.Time{}, false
+		}
+		return lastDay.AddDate(0, 0, int(s.IntervalDays)), true
+	case ScheduleWeekly:
+		if s.Weekdays&0x7f == 0 {
+			return time.Time{}, false
+		}
+		for offset := 1; offset <= 7; offset++ {
+			day := lastDay.AddDate(0, 0, offset)
+			if s.HasWeekday(day.Weekday()) {
+				return day, true
+			}
+		}
+	case ScheduleMonthly:
+		if s.MonthDay < 1 || s.MonthDay > 31 {
+			return time.Time{}, false
+		}
+		for offset := 0; offset <= 1; offset++ {
+			firstOfMonth := time.Date(lastDay.Year(), lastDay.Month()+time.Month(offset), 1, 0, 0, 0, 0, location)
+			daysInMonth := firstOfMonth.AddDate(0, 1, -1).Day()
+			day := firstOfMonth.AddDate(0, 0, min(int(s.MonthDay), daysInMonth)-1)
+			if day.After(lastDay) {
+				return day, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ChoreDue is the due state of a scheduled chore.
+type ChoreDue struct {
+	Status DueStatus
+	// Due is the day the chore is due, at midnight in the configured location.
+	Due time.Time
+	// LastDone is the start of the latest task of the chore, if any.
+	LastDone *time.Time
+}
+
+// ComputeDue returns the due state of a chore at now. A scheduled chore that
+// was never done is due today.
+func ComputeDue(schedule Schedule, lastDone *time.Time, now time.Time, location *time.Location) ChoreDue {
+	today := startOfDay(now, location)
+	if schedule.Kind == "" || schedule.Kind == ScheduleNone {
+		return ChoreDue{Status: NotScheduled, LastDone: lastDone}
+	}
+	if lastDone == nil {
+		return ChoreDue{Status: DueToday, Due: today}
+	}
+	due, ok := schedule.NextDue(*lastDone, location)
+	if !ok {
+		return ChoreDue{Status: NotScheduled, LastDone: lastDone}
+	}
+	status := Upcoming
+	switch {
+	case due.Before(today):
+		status = Overdue
+	case due.Equal(today):
+		status = DueToday
+	}
+	return ChoreDue{Status: status, Due: due, LastDone: lastDone}
+}
+
+// ListChoresDue returns the due state of every chore of the household, keyed
+// by chore ID.
+func (r *Repository) ListChoresDue(ctx context.Context, chores []postgres.Chore, now time.Time, location *time.Location) (map[int32]ChoreDue, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := r.q.ChoresTasksStats(ctx, householdID)
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	lastDone := make(map[int32]time.Time, len(stats))
+	for _, stat := range stats {
+		lastDone[stat.ChoreID] = stat.LastStartedAt
+	}
+	dues := make(map[int32]ChoreDue, len(chores))
+	for _, chore := range chores {
+		var last *time.Time
+		if t, ok := lastDone[chore.ID]; ok {
+			last = &t
+		}
+		dues[chore.ID] = ComputeDue(ChoreSchedule(chore), last, now, location)
+	}
+	return dues, nil
+}
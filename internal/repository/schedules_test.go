@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleNextDue(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	// A Tuesday evening.
+	last := time.Date(2024, time.January, 30, 22, 30, 0, 0, location)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     time.Time
+		ok       bool
+	}{
+		{"none", Schedule{Kind: ScheduleNone}, time.Time{}, false},
+		{"every 7 days", Schedule{Kind: ScheduleInterval, IntervalDays: 7}, time.Date(2024, time.February, 6, 0, 0, 0, 0, location), true},
+		{"interval without days", Schedule{Kind: ScheduleInterval}, time.Time{}, false},
+		{"tuesdays", Schedule{Kind: ScheduleWeekly, Weekdays: 1 << time.Tuesday}, time.Date(2024, time.February, 6, 0, 0, 0, 0, location), true},
+		{"fridays and mondays", Schedule{Kind: ScheduleWeekly, Weekdays: 1<<time.Friday | 1<<time.Monday}, time.Date(2024, time.February, 2, 0, 0, 0, 0, location), true},
+		{"monthly later this month", Schedule{Kind: ScheduleMonthly, MonthDay: 31}, time.Date(2024, time.January, 31, 0, 0, 0, 0, location), true},
+		{"monthly next month", Schedule{Kind: ScheduleMonthly, MonthDay: 15}, time.Date(2024, time.February, 15, 0, 0, 0, 0, location), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := test.schedule.NextDue(last, location)
+			assert.Equal(t, test.ok, ok)
+			assert.True(t, test.want.Equal(got), "want %v, got %v", test.want, got)
+		})
+	}
+
+	// The 31st is clamped to the last day of shorter months.
+	got, ok := Schedule{Kind: ScheduleMonthly, MonthDay: 31}.NextDue(time.Date(2024, time.January, 31, 8, 0, 0, 0, location), location)
+	assert.True(t, ok)
+	assert.True(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, location).Equal(got), "got %v", got)
+}
+
+func TestComputeDue(t *testing.T) {
+	location := time.UTC
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, location)
+	weekly := Schedule{Kind: ScheduleInterval, IntervalDays: 7}
+
+	assert.Equal(t, NotScheduled, ComputeDue(Schedule{Kind: ScheduleNone}, nil, now, location).Status)
+	assert.Equal(t, DueToday, ComputeDue(weekly, nil, now, location).Status)
+
+	lastWeek := now.AddDate(0, 0, -7)
+	assert.Equal(t, DueToday, ComputeDue(weekly, &lastWeek, now, location).Status)
+	longAgo := now.AddDate(0, 0, -8)
+	assert.Equal(t, Overdue, ComputeDue(weekly, &longAgo, now, location).Status)
+	yesterday := now.AddDate(0, 0, -1)
+	assert.Equal(t, Upcoming, ComputeDue(weekly, &yesterday, now, location).Status)
+}
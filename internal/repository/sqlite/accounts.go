@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts`).Scan(&count)
+	return count, convertError(err)
+}
+
+func (q *Queries) ListAccounts(ctx context.Context, householdID int32) ([]postgres.Account, error) {
+	return many(ctx, q.db, accountFields, `SELECT * FROM accounts
+WHERE household_id = ?
+ORDER BY username`, householdID)
+}
+
+func (q *Queries) GetAccount(ctx context.Context, arg postgres.GetAccountParams) (postgres.Account, error) {
+	return one(ctx, q.db, accountFields, `SELECT * FROM accounts
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) GetAccountByUsername(ctx context.Context, username string) (postgres.Account, error) {
+	return one(ctx, q.db, accountFields, `SELECT * FROM accounts
+WHERE username = ?`, username)
+}
+
+func (q *Queries) CreateAccount(ctx context.Context, arg postgres.CreateAccountParams) (postgres.Account, error) {
+	return one(ctx, q.db, accountFields, `INSERT INTO accounts (
+    household_id, username, password_hash, user_id, created_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *`, arg.HouseholdID, arg.Username, arg.PasswordHash, arg.UserID, now())
+}
+
+func (q *Queries) UpdateAccount(ctx context.Context, arg postgres.UpdateAccountParams) (postgres.Account, error) {
+	return one(ctx, q.db, accountFields, `UPDATE accounts SET
+username = ?,
+user_id = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.Username, arg.UserID, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) UpdateAccountPassword(ctx context.Context, arg postgres.UpdateAccountPasswordParams) error {
+	_, err := exec(ctx, q.db, `UPDATE accounts SET
+password_hash = ?
+WHERE id = ?`, arg.PasswordHash, arg.ID)
+	return err
+}
+
+func (q *Queries) DeleteAccount(ctx context.Context, arg postgres.DeleteAccountParams) error {
+	_, err := exec(ctx, q.db, `DELETE FROM accounts
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+	return err
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg postgres.CreateSessionParams) (postgres.Session, error) {
+	return one(ctx, q.db, sessionFields, `INSERT INTO sessions (
+    token_hash, account_id, created_at, expires_at
+) VALUES (
+    ?, ?, ?, ?
+)
+RETURNING *`, arg.TokenHash, arg.AccountID, now(), formatTime(arg.ExpiresAt))
+}
+
+func (q *Queries) GetSessionAccount(ctx context.Context, tokenHash string) (postgres.Account, error) {
+	return one(ctx, q.db, accountFields, `SELECT accounts.*
+FROM sessions
+JOIN accounts ON sessions.account_id = accounts.id
+WHERE sessions.token_hash = ? AND sessions.expires_at > ?`, tokenHash, now())
+}
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := exec(ctx, q.db, `DELETE FROM sessions
+WHERE token_hash = ?`, tokenHash)
+	return err
+}
+
+func (q *Queries) DeleteAccountSessions(ctx context.Context, accountID int32) error {
+	_, err := exec(ctx, q.db, `DELETE FROM sessions
+WHERE account_id = ?`, accountID)
+	return err
+}
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context) error {
+	_, err := exec(ctx, q.db, `DELETE FROM sessions
+WHERE expires_at <= ?`, now())
+	return err
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) CountHouseholds(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM households`).Scan(&count)
+	return count, convertError(err)
+}
+
+func (q *Queries) RestoreHousehold(ctx context.Context, arg postgres.RestoreHouseholdParams) error {
+	_, err := exec(ctx, q.db, `INSERT INTO households (
+    id, name, created_at
+) VALUES (
+    ?, ?, ?
+)`, arg.ID, arg.Name, formatTime(arg.CreatedAt))
+	return err
+}
+
+func (q *Queries) RestoreChore(ctx context.Context, arg postgres.RestoreChoreParams) error {
+	_, err := exec(ctx, q.db, `INSERT INTO chores (
+    id, household_id, name, description, default_duration_mn, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty, archived
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)`, arg.ID, arg.HouseholdID, arg.Name, arg.Description, arg.DefaultDurationMn,
+		arg.ScheduleKind, arg.ScheduleIntervalDays, arg.ScheduleWeekdays, arg.ScheduleMonthDay, arg.Difficulty, arg.Archived)
+	return err
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg postgres.RestoreUserParams) error {
+	_, err := exec(ctx, q.db, `INSERT INTO users (
+    id, household_id, name, share, archived
+) VALUES (
+    ?, ?, ?, ?, ?
+)`, arg.ID, arg.HouseholdID, arg.Name, arg.Share, arg.Archived)
+	return err
+}
+
+func (q *Queries) RestoreTask(ctx context.Context, arg postgres.RestoreTaskParams) error {
+	_, err := exec(ctx, q.db, `INSERT INTO tasks (
+    id, household_id, user_id, chore_id, started_at, duration_mn, description, points
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)`, arg.ID, arg.HouseholdID, arg.UserID, arg.ChoreID, formatTime(arg.StartedAt), arg.DurationMn, arg.Description, arg.Points)
+	return err
+}
+
+// The IDs of SQLite follow the largest ID of their table, they don't need to
+// be reset after a restore.
+
+func (q *Queries) ResetHouseholdsSequence(ctx context.Context) error {
+	return nil
+}
+
+func (q *Queries) ResetChoresSequence(ctx context.Context) error {
+	return nil
+}
+
+func (q *Queries) ResetUsersSequence(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListCalendarFeeds(ctx context.Context, householdID int32) ([]postgres.CalendarFeed, error) {
+	return many(ctx, q.db, calendarFeedFields, `SELECT * FROM calendar_feeds
+WHERE household_id = ?
+ORDER BY id`, householdID)
+}
+
+func (q *Queries) GetCalendarFeed(ctx context.Context, tokenHash string) (postgres.CalendarFeed, error) {
+	return one(ctx, q.db, calendarFeedFields, `SELECT * FROM calendar_feeds
+WHERE token_hash = ?`, tokenHash)
+}
+
+func (q *Queries) CreateCalendarFeed(ctx context.Context, arg postgres.CreateCalendarFeedParams) (postgres.CalendarFeed, error) {
+	return one(ctx, q.db, calendarFeedFields, `INSERT INTO calendar_feeds (
+    household_id, user_id, token_hash, created_at
+) VALUES (
+    ?, ?, ?, ?
+)
+RETURNING *`, arg.HouseholdID, arg.UserID, arg.TokenHash, now())
+}
+
+func (q *Queries) DeleteCalendarFeed(ctx context.Context, arg postgres.DeleteCalendarFeedParams) (int64, error) {
+	return exec(ctx, q.db, `DELETE FROM calendar_feeds
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListChores(ctx context.Context, householdID int32) ([]postgres.Chore, error) {
+	return many(ctx, q.db, choreFields, `SELECT * FROM chores
+WHERE household_id = ?
+ORDER BY name`, householdID)
+}
+
+func (q *Queries) GetChore(ctx context.Context, arg postgres.GetChoreParams) (postgres.Chore, error) {
+	return one(ctx, q.db, choreFields, `SELECT * FROM chores
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) CreateChore(ctx context.Context, arg postgres.CreateChoreParams) (postgres.Chore, error) {
+	return one(ctx, q.db, choreFields, `INSERT INTO chores (
+    household_id, name, description, default_duration_mn, schedule_kind, schedule_interval_days, schedule_weekdays, schedule_month_day, difficulty
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *`, arg.HouseholdID, arg.Name, arg.Description, arg.DefaultDurationMn,
+		arg.ScheduleKind, arg.ScheduleIntervalDays, arg.ScheduleWeekdays, arg.ScheduleMonthDay, arg.Difficulty)
+}
+
+func (q *Queries) UpdateChore(ctx context.Context, arg postgres.UpdateChoreParams) (postgres.Chore, error) {
+	return one(ctx, q.db, choreFields, `UPDATE chores SET
+name = ?,
+description = ?,
+default_duration_mn = ?,
+schedule_kind = ?,
+schedule_interval_days = ?,
+schedule_weekdays = ?,
+schedule_month_day = ?,
+difficulty = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.Name, arg.Description, arg.DefaultDurationMn, arg.ScheduleKind,
+		arg.ScheduleIntervalDays, arg.ScheduleWeekdays, arg.ScheduleMonthDay, arg.Difficulty, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) SetChoreArchived(ctx context.Context, arg postgres.SetChoreArchivedParams) (postgres.Chore, error) {
+	return one(ctx, q.db, choreFields, `UPDATE chores SET
+archived = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.Archived, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) ReassignChoreTasks(ctx context.Context, arg postgres.ReassignChoreTasksParams) ([]postgres.Task, error) {
+	return many(ctx, q.db, taskFields, `UPDATE tasks SET
+chore_id = ?,
+points = duration_mn * ?
+WHERE household_id = ? AND chore_id = ?
+RETURNING *`, arg.ToChoreID, arg.Difficulty, arg.HouseholdID, arg.FromChoreID)
+}
+
+func (q *Queries) DeleteChore(ctx context.Context, arg postgres.DeleteChoreParams) (int64, error) {
+	return exec(ctx, q.db, `DELETE FROM chores
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) ChoresTasksStats(ctx context.Context, householdID int32) ([]postgres.ChoresTasksStatsRow, error) {
+	return many(ctx, q.db, func(row *postgres.ChoresTasksStatsRow) []any {
+		return []any{&row.ChoreID, &row.TasksCount, timestamp{&row.FirstStartedAt}, timestamp{&row.LastStartedAt}}
+	}, `SELECT chore_id, COUNT(*) AS tasks_count, MIN(started_at) AS first_started_at, MAX(started_at) AS last_started_at
+FROM tasks
+WHERE household_id = ?
+GROUP BY chore_id`, householdID)
+}
@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListHouseholds(ctx context.Context) ([]postgres.Household, error) {
+	return many(ctx, q.db, householdFields, `SELECT * FROM households
+ORDER BY id`)
+}
+
+func (q *Queries) GetHousehold(ctx context.Context, id int32) (postgres.Household, error) {
+	return one(ctx, q.db, householdFields, `SELECT * FROM households
+WHERE id = ?`, id)
+}
+
+func (q *Queries) CreateHousehold(ctx context.Context, name string) (postgres.Household, error) {
+	return one(ctx, q.db, householdFields, `INSERT INTO households (
+    name, created_at
+) VALUES (
+    ?, ?
+)
+RETURNING *`, name, now())
+}
+
+func (q *Queries) UpdateHousehold(ctx context.Context, arg postgres.UpdateHouseholdParams) (postgres.Household, error) {
+	return one(ctx, q.db, householdFields, `UPDATE households SET
+name = ?
+WHERE id = ?
+RETURNING *`, arg.Name, arg.ID)
+}
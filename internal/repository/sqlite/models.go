@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// The fields functions list where the columns of a table are scanned, in the
+// order of the columns of the table, the one of SELECT * and RETURNING *.
+
+func accountFields(a *postgres.Account) []any {
+	return []any{&a.ID, &a.Username, &a.PasswordHash, &a.UserID, timestamp{&a.CreatedAt}, &a.HouseholdID}
+}
+
+func calendarFeedFields(f *postgres.CalendarFeed) []any {
+	return []any{&f.ID, &f.HouseholdID, &f.UserID, &f.TokenHash, timestamp{&f.CreatedAt}}
+}
+
+func choreFields(c *postgres.Chore) []any {
+	return []any{&c.ID, &c.Name, &c.Description, &c.DefaultDurationMn, &c.HouseholdID,
+		&c.ScheduleKind, &c.ScheduleIntervalDays, &c.ScheduleWeekdays, &c.ScheduleMonthDay, &c.Difficulty, &c.Archived}
+}
+
+func householdFields(h *postgres.Household) []any {
+	return []any{&h.ID, &h.Name, timestamp{&h.CreatedAt}}
+}
+
+func sessionFields(s *postgres.Session) []any {
+	return []any{&s.TokenHash, &s.AccountID, timestamp{&s.CreatedAt}, timestamp{&s.ExpiresAt}}
+}
+
+func taskFields(t *postgres.Task) []any {
+	return []any{&t.ID, &t.UserID, &t.ChoreID, timestamp{&t.StartedAt}, &t.DurationMn, &t.Description, &t.HouseholdID, &t.Points}
+}
+
+func timerFields(t *postgres.Timer) []any {
+	return []any{&t.ID, &t.HouseholdID, &t.UserID, &t.ChoreID, timestamp{&t.StartedAt}, nullTimestamp{&t.ResumedAt}, &t.ElapsedSeconds}
+}
+
+func userFields(u *postgres.User) []any {
+	return []any{&u.ID, &u.Name, &u.HouseholdID, &u.Share, &u.Archived}
+}
+
+func webhookFields(w *postgres.Webhook) []any {
+	return []any{&w.ID, &w.HouseholdID, &w.Url, &w.Secret, stringArray{&w.Events}, timestamp{&w.CreatedAt}}
+}
+
+func webhookDeliveryFields(d *postgres.WebhookDelivery) []any {
+	return []any{&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts, timestamp{&d.NextAttemptAt},
+		&d.LastStatusCode, &d.LastError, timestamp{&d.CreatedAt}, nullTimestamp{&d.DeliveredAt}}
+}
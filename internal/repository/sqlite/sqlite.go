@@ -0,0 +1,278 @@
+// Package sqlite runs the queries of the repository on an embedded SQLite
+// database, for the deployments where running PostgreSQL is too much, such as
+// a single-board computer.
+//
+// Its queries are the ones sqlc generates for PostgreSQL in the postgres
+// package, written for SQLite, and return the same models. They fail like
+// them too: with pgx.ErrNoRows when no row is found and with a
+// *pgconn.PgError named after the PostgreSQL constraint that was violated.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"modernc.org/sqlite"
+)
+
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Queries runs the queries on a database or in a transaction.
+type Queries struct {
+	db dbtx
+	// notify sends a change to the listeners, once committed in a
+	// transaction.
+	notify func(payload string)
+}
+
+// Store runs the queries on a SQLite database opened with
+// database.OpenSQLite. SQLite has no LISTEN/NOTIFY, the changes are only
+// notified to the listeners of the process.
+type Store struct {
+	*Queries
+	db        *sql.DB
+	listeners *database.Listeners
+}
+
+func New(db *sql.DB) *Store {
+	listeners := &database.Listeners{}
+	return &Store{Queries: &Queries{db: db, notify: listeners.Notify}, db: db, listeners: listeners}
+}
+
+// InTx runs fn with queries bound to a transaction, committed when fn
+// succeeds. The changes notified are sent once committed.
+func (s *Store) InTx(ctx context.Context, fn func(q postgres.Querier) error) error {
+	return s.inTx(ctx, nil, fn)
+}
+
+// InSnapshot runs fn in a read-only transaction. Other connections can still
+// write, the transaction keeps seeing the database as it was when it started.
+func (s *Store) InSnapshot(ctx context.Context, fn func(q postgres.Querier) error) error {
+	return s.inTx(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+func (s *Store) inTx(ctx context.Context, options *sql.TxOptions, fn func(q postgres.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, options)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	var notified []string
+	notify := func(payload string) { notified = append(notified, payload) }
+	if err = fn(&Queries{db: tx, notify: notify}); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	for _, payload := range notified {
+		s.listeners.Notify(payload)
+	}
+	return nil
+}
+
+// Listen calls notify with the changes notified to the store until ctx is
+// canceled.
+func (s *Store) Listen(ctx context.Context, notify func(payload string)) error {
+	return s.listeners.Listen(ctx, notify)
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// SchemaVersion returns the version of the last migration applied to the
+// database, 0 when none was, and whether it failed.
+func (s *Store) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := s.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to get schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// constraintFailed matches the message of the constraint errors of SQLite,
+// e.g. "UNIQUE constraint failed: users.household_id, users.name". The
+// triggers of the migrations raise "FOREIGN KEY constraint failed: " followed
+// by the name of the foreign key.
+var constraintFailed = regexp.MustCompile(`(UNIQUE|CHECK|NOT NULL|FOREIGN KEY) constraint failed(?:: (.+?))?(?: \(\d+\))?$`)
+
+// convertError returns the error pgx would have returned in place of a
+// database/sql or SQLite one.
+func convertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	match := constraintFailed.FindStringSubmatch(sqliteErr.Error())
+	if match == nil {
+		return err
+	}
+	pgErr := &pgconn.PgError{Severity: "ERROR", Message: sqliteErr.Error()}
+	switch match[1] {
+	case "UNIQUE":
+		pgErr.Code = "23505"
+		pgErr.ConstraintName = uniqueConstraintName(match[2])
+	case "CHECK":
+		pgErr.Code = "23514"
+		pgErr.ConstraintName = match[2]
+	case "NOT NULL":
+		pgErr.Code = "23502"
+		pgErr.TableName, pgErr.ColumnName, _ = strings.Cut(match[2], ".")
+	case "FOREIGN KEY":
+		pgErr.Code = "23503"
+		pgErr.ConstraintName = match[2]
+	}
+	return pgErr
+}
+
+// uniqueConstraintName returns the name PostgreSQL gives to the unique
+// constraint on columns listed like "users.household_id, users.name":
+// users_household_id_name_key.
+func uniqueConstraintName(columns string) string {
+	var table string
+	names := []string{}
+	for _, column := range strings.Split(columns, ", ") {
+		table, column, _ = strings.Cut(column, ".")
+		names = append(names, column)
+	}
+	return table + "_" + strings.Join(names, "_") + "_key"
+}
+
+// timeLayout stores the times as UTC texts of a fixed width, so that they are
+// compared and sorted like the times, with the precision of PostgreSQL.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func now() string {
+	return formatTime(time.Now())
+}
+
+// timestamp scans a time stored with timeLayout.
+type timestamp struct {
+	t *time.Time
+}
+
+func (ts timestamp) Scan(src any) error {
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unable to scan %T as a timestamp", src)
+	}
+	t, err := time.Parse(timeLayout, text)
+	if err != nil {
+		return err
+	}
+	*ts.t = t.Local()
+	return nil
+}
+
+// nullTimestamp scans a time stored with timeLayout, or NULL.
+type nullTimestamp struct {
+	t *pgtype.Timestamptz
+}
+
+func (ts nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*ts.t = pgtype.Timestamptz{}
+		return nil
+	}
+	ts.t.Valid = true
+	return timestamp{&ts.t.Time}.Scan(src)
+}
+
+func formatNullTime(t pgtype.Timestamptz) any {
+	if !t.Valid {
+		return nil
+	}
+	return formatTime(t.Time)
+}
+
+// stringArray scans a JSON array of strings, the TEXT[] of PostgreSQL.
+type stringArray struct {
+	a *[]string
+}
+
+func (sa stringArray) Scan(src any) error {
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unable to scan %T as an array", src)
+	}
+	return json.Unmarshal([]byte(text), sa.a)
+}
+
+func formatStringArray(a []string) (string, error) {
+	if a == nil {
+		a = []string{}
+	}
+	data, err := json.Marshal(a)
+	return string(data), err
+}
+
+// one runs a query returning a single row, scanned into the fields returned
+// by fields.
+func one[T any](ctx context.Context, db dbtx, fields func(*T) []any, query string, args ...any) (T, error) {
+	var item T
+	err := db.QueryRowContext(ctx, query, args...).Scan(fields(&item)...)
+	return item, convertError(err)
+}
+
+// many runs a query returning several rows, each scanned into the fields
+// returned by fields.
+func many[T any](ctx context.Context, db dbtx, fields func(*T) []any, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	defer rows.Close()
+	var items []T
+	for rows.Next() {
+		var item T
+		if err := rows.Scan(fields(&item)...); err != nil {
+			return nil, convertError(err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertError(err)
+	}
+	return items, nil
+}
+
+// exec runs a query returning no row and returns the number of rows it
+// changed.
+func exec(ctx context.Context, db dbtx, query string, args ...any) (int64, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.Store {
+		path := filepath.Join(t.TempDir(), "whodidthechores.db")
+		migrator, err := database.NewSQLiteMigrator(path)
+		require.NoError(t, err)
+		require.NoError(t, migrator.Up())
+		require.NoError(t, migrator.Close())
+
+		db, err := database.OpenSQLite(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return New(db)
+	})
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListTasks(ctx context.Context, householdID int32) ([]postgres.Task, error) {
+	return many(ctx, q.db, taskFields, `SELECT * FROM tasks
+WHERE household_id = ?
+ORDER BY started_at`, householdID)
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg postgres.GetTaskParams) (postgres.Task, error) {
+	return one(ctx, q.db, taskFields, `SELECT * FROM tasks
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) CreateTask(ctx context.Context, arg postgres.CreateTaskParams) (postgres.Task, error) {
+	return one(ctx, q.db, taskFields, `INSERT INTO tasks (
+    id, household_id, user_id, chore_id, started_at, duration_mn, description, points
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *`, uuid.New(), arg.HouseholdID, arg.UserID, arg.ChoreID, formatTime(arg.StartedAt), arg.DurationMn, arg.Description, arg.Points)
+}
+
+func (q *Queries) DeleteTask(ctx context.Context, arg postgres.DeleteTaskParams) (int64, error) {
+	return exec(ctx, q.db, `DELETE FROM tasks
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) UpdateTask(ctx context.Context, arg postgres.UpdateTaskParams) (postgres.Task, error) {
+	return one(ctx, q.db, taskFields, `UPDATE tasks SET
+user_id = ?,
+chore_id = ?,
+started_at = ?,
+duration_mn = ?,
+description = ?,
+points = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.UserID, arg.ChoreID, formatTime(arg.StartedAt), arg.DurationMn, arg.Description, arg.Points, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) GetUserTasks(ctx context.Context, arg postgres.GetUserTasksParams) ([]postgres.GetUserTasksRow, error) {
+	return many(ctx, q.db, func(row *postgres.GetUserTasksRow) []any {
+		return append(taskFields(&row.Task), choreFields(&row.Chore)...)
+	}, `SELECT tasks.*, chores.*
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ? AND users.id = ?
+ORDER BY tasks.started_at DESC`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) GetChoreTasks(ctx context.Context, arg postgres.GetChoreTasksParams) ([]postgres.GetChoreTasksRow, error) {
+	return many(ctx, q.db, func(row *postgres.GetChoreTasksRow) []any {
+		return append(taskFields(&row.Task), userFields(&row.User)...)
+	}, `SELECT tasks.*, users.*
+FROM tasks
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ? AND tasks.chore_id = ?
+ORDER BY tasks.started_at DESC`, arg.HouseholdID, arg.ChoreID)
+}
+
+func (q *Queries) ListUsersTasks(ctx context.Context, householdID int32) ([]postgres.ListUsersTasksRow, error) {
+	return many(ctx, q.db, func(row *postgres.ListUsersTasksRow) []any {
+		fields := append(taskFields(&row.Task), choreFields(&row.Chore)...)
+		return append(fields, userFields(&row.User)...)
+	}, `SELECT tasks.*, chores.*, users.*
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ?
+ORDER BY tasks.started_at DESC`, householdID)
+}
+
+func (q *Queries) ListUsersTasksBetween(ctx context.Context, arg postgres.ListUsersTasksBetweenParams) ([]postgres.ListUsersTasksBetweenRow, error) {
+	return many(ctx, q.db, func(row *postgres.ListUsersTasksBetweenRow) []any {
+		fields := append(taskFields(&row.Task), choreFields(&row.Chore)...)
+		return append(fields, userFields(&row.User)...)
+	}, `SELECT tasks.*, chores.*, users.*
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ? AND (? = 0 OR tasks.user_id = ?) AND tasks.started_at >= ? AND tasks.started_at <= ?
+ORDER BY tasks.started_at DESC`, arg.HouseholdID, arg.UserID, arg.UserID, formatTime(arg.NotBefore), formatTime(arg.NotAfter))
+}
+
+// SearchTasks writes the sort of arg in the query rather than picking it with
+// CASE expressions: SQLite takes an integer constant in ORDER BY for the
+// number of a result column.
+func (q *Queries) SearchTasks(ctx context.Context, arg postgres.SearchTasksParams) ([]postgres.SearchTasksRow, error) {
+	columns := []string{"tasks.started_at", "tasks.id"}
+	after := []any{formatTime(arg.AfterStartedAt), arg.AfterID}
+	switch arg.Sort {
+	case "duration":
+		columns = append([]string{"tasks.duration_mn"}, columns...)
+		after = append([]any{arg.AfterKey}, after...)
+	case "points":
+		columns = append([]string{"tasks.points"}, columns...)
+		after = append([]any{arg.AfterKey}, after...)
+	}
+	comparison, direction := ">", "ASC"
+	if arg.Descending {
+		comparison, direction = "<", "DESC"
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	keyset := "TRUE"
+	if arg.After {
+		keyset = fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), comparison, placeholders)
+	} else {
+		after = nil
+	}
+	args := []any{arg.HouseholdID, arg.UserID, arg.UserID, arg.ChoreID, arg.ChoreID, formatTime(arg.NotBefore), formatTime(arg.NotAfter), arg.Search}
+	args = append(append(args, after...), arg.MaxTasks)
+	return many(ctx, q.db, func(row *postgres.SearchTasksRow) []any {
+		fields := append(taskFields(&row.Task), choreFields(&row.Chore)...)
+		return append(fields, userFields(&row.User)...)
+	}, fmt.Sprintf(`SELECT tasks.*, chores.*, users.*
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ?
+    AND (? = 0 OR tasks.user_id = ?)
+    AND (? = 0 OR tasks.chore_id = ?)
+    AND tasks.started_at >= ? AND tasks.started_at < ?
+    AND instr(lower(tasks.description), lower(?)) > 0
+    AND %s
+ORDER BY %s %s
+LIMIT ?`, keyset, strings.Join(columns, " "+direction+", "), direction), args...)
+}
+
+func (q *Queries) TasksReport(ctx context.Context, arg postgres.TasksReportParams) ([]postgres.TasksReportRow, error) {
+	return many(ctx, q.db, func(row *postgres.TasksReportRow) []any {
+		fields := append(userFields(&row.User), choreFields(&row.Chore)...)
+		return append(fields, &row.Minutes, &row.TasksCount, &row.Points)
+	}, `SELECT users.*, chores.*, SUM(tasks.duration_mn) AS minutes, COUNT(*) AS tasks_count, SUM(tasks.points) AS points
+FROM tasks
+JOIN chores ON tasks.chore_id = chores.id
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ? AND tasks.started_at > ? AND tasks.started_at < ?
+GROUP BY chores.id, users.id`, arg.HouseholdID, formatTime(arg.NotBefore), formatTime(arg.NotAfter))
+}
+
+// TasksTimeline adds up the tasks in Go: SQLite has no time zones to truncate
+// their start times with.
+func (q *Queries) TasksTimeline(ctx context.Context, arg postgres.TasksTimelineParams) ([]postgres.TasksTimelineRow, error) {
+	location, err := time.LoadLocation(arg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", arg.TimeZone, err)
+	}
+	tasks, err := many(ctx, q.db, func(row *postgres.TasksTimelineRow) []any {
+		return append(userFields(&row.User), timestamp{&row.Period}, &row.Minutes, &row.TasksCount, &row.Points)
+	}, `SELECT users.*, tasks.started_at, tasks.duration_mn, 1, tasks.points
+FROM tasks
+JOIN users ON tasks.user_id = users.id
+WHERE tasks.household_id = ? AND tasks.started_at > ? AND tasks.started_at < ?`, arg.HouseholdID, formatTime(arg.NotBefore), formatTime(arg.NotAfter))
+	if err != nil {
+		return nil, err
+	}
+	type key struct {
+		userID int32
+		period int64
+	}
+	indexes := map[key]int{}
+	var items []postgres.TasksTimelineRow
+	for _, task := range tasks {
+		task.Period, err = database.DateTrunc(arg.Unit, task.Period, location)
+		if err != nil {
+			return nil, err
+		}
+		k := key{task.User.ID, task.Period.Unix()}
+		index, ok := indexes[k]
+		if !ok {
+			indexes[k] = len(items)
+			items = append(items, task)
+			continue
+		}
+		items[index].Minutes += task.Minutes
+		items[index].TasksCount += task.TasksCount
+		items[index].Points += task.Points
+	}
+	slices.SortFunc(items, func(a, b postgres.TasksTimelineRow) int {
+		return cmp.Or(a.Period.Compare(b.Period), cmp.Compare(a.User.ID, b.User.ID))
+	})
+	return items, nil
+}
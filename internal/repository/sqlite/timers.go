@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListTimers(ctx context.Context, householdID int32) ([]postgres.ListTimersRow, error) {
+	return many(ctx, q.db, func(row *postgres.ListTimersRow) []any {
+		fields := append(timerFields(&row.Timer), choreFields(&row.Chore)...)
+		return append(fields, userFields(&row.User)...)
+	}, `SELECT timers.*, chores.*, users.*
+FROM timers
+JOIN chores ON timers.chore_id = chores.id
+JOIN users ON timers.user_id = users.id
+WHERE timers.household_id = ?
+ORDER BY timers.started_at`, householdID)
+}
+
+func (q *Queries) GetTimer(ctx context.Context, arg postgres.GetTimerParams) (postgres.Timer, error) {
+	return one(ctx, q.db, timerFields, `SELECT * FROM timers
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+// GetTimerForUpdate needs no row lock: the transactions start as writers, one
+// at a time.
+func (q *Queries) GetTimerForUpdate(ctx context.Context, arg postgres.GetTimerForUpdateParams) (postgres.Timer, error) {
+	return q.GetTimer(ctx, postgres.GetTimerParams(arg))
+}
+
+func (q *Queries) CreateTimer(ctx context.Context, arg postgres.CreateTimerParams) (postgres.Timer, error) {
+	return one(ctx, q.db, timerFields, `INSERT INTO timers (
+    household_id, user_id, chore_id, started_at, resumed_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *`, arg.HouseholdID, arg.UserID, arg.ChoreID, formatTime(arg.StartedAt), formatTime(arg.StartedAt))
+}
+
+func (q *Queries) UpdateTimer(ctx context.Context, arg postgres.UpdateTimerParams) (postgres.Timer, error) {
+	return one(ctx, q.db, timerFields, `UPDATE timers SET
+resumed_at = ?,
+elapsed_seconds = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, formatNullTime(arg.ResumedAt), arg.ElapsedSeconds, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) DeleteTimer(ctx context.Context, arg postgres.DeleteTimerParams) (int64, error) {
+	return exec(ctx, q.db, `DELETE FROM timers
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
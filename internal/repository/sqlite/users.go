@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListUsers(ctx context.Context, householdID int32) ([]postgres.User, error) {
+	return many(ctx, q.db, userFields, `SELECT * FROM users
+WHERE household_id = ?
+ORDER BY name`, householdID)
+}
+
+func (q *Queries) GetUser(ctx context.Context, arg postgres.GetUserParams) (postgres.User, error) {
+	return one(ctx, q.db, userFields, `SELECT * FROM users
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg postgres.CreateUserParams) (postgres.User, error) {
+	return one(ctx, q.db, userFields, `INSERT INTO users (
+    household_id, name, share
+) VALUES (
+    ?, ?, ?
+)
+RETURNING *`, arg.HouseholdID, arg.Name, arg.Share)
+}
+
+func (q *Queries) SetUserArchived(ctx context.Context, arg postgres.SetUserArchivedParams) (postgres.User, error) {
+	return one(ctx, q.db, userFields, `UPDATE users SET
+archived = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.Archived, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) ReassignUserTasks(ctx context.Context, arg postgres.ReassignUserTasksParams) ([]postgres.Task, error) {
+	return many(ctx, q.db, taskFields, `UPDATE tasks SET
+user_id = ?
+WHERE household_id = ? AND user_id = ?
+RETURNING *`, arg.ToUserID, arg.HouseholdID, arg.FromUserID)
+}
+
+func (q *Queries) DeleteUser(ctx context.Context, arg postgres.DeleteUserParams) (int64, error) {
+	return exec(ctx, q.db, `DELETE FROM users
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg postgres.UpdateUserParams) (postgres.User, error) {
+	return one(ctx, q.db, userFields, `UPDATE users SET
+name = ?,
+share = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.Name, arg.Share, arg.HouseholdID, arg.ID)
+}
@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func (q *Queries) ListWebhooks(ctx context.Context, householdID int32) ([]postgres.Webhook, error) {
+	return many(ctx, q.db, webhookFields, `SELECT * FROM webhooks
+WHERE household_id = ?
+ORDER BY id`, householdID)
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg postgres.GetWebhookParams) (postgres.Webhook, error) {
+	return one(ctx, q.db, webhookFields, `SELECT * FROM webhooks
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg postgres.CreateWebhookParams) (postgres.Webhook, error) {
+	events, err := formatStringArray(arg.Events)
+	if err != nil {
+		return postgres.Webhook{}, err
+	}
+	return one(ctx, q.db, webhookFields, `INSERT INTO webhooks (
+    household_id, url, secret, events, created_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *`, arg.HouseholdID, arg.Url, arg.Secret, events, now())
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg postgres.UpdateWebhookParams) (postgres.Webhook, error) {
+	events, err := formatStringArray(arg.Events)
+	if err != nil {
+		return postgres.Webhook{}, err
+	}
+	return one(ctx, q.db, webhookFields, `UPDATE webhooks SET
+url = ?,
+secret = ?,
+events = ?
+WHERE household_id = ? AND id = ?
+RETURNING *`, arg.Url, arg.Secret, events, arg.HouseholdID, arg.ID)
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg postgres.DeleteWebhookParams) error {
+	_, err := exec(ctx, q.db, `DELETE FROM webhooks
+WHERE household_id = ? AND id = ?`, arg.HouseholdID, arg.ID)
+	return err
+}
+
+// EnqueueWebhookDeliveries adds a delivery for each webhook of the household
+// subscribed to the resource. The IDs of the deliveries are generated here,
+// SQLite can't generate UUIDs.
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, arg postgres.EnqueueWebhookDeliveriesParams) error {
+	webhookIDs, err := many(ctx, q.db, func(id *int32) []any { return []any{id} }, `SELECT webhooks.id FROM webhooks
+WHERE webhooks.household_id = ? AND EXISTS (SELECT 1 FROM json_each(webhooks.events) WHERE json_each.value = ?)`,
+		arg.HouseholdID, arg.Resource)
+	if err != nil {
+		return err
+	}
+	createdAt := now()
+	for _, webhookID := range webhookIDs {
+		_, err = exec(ctx, q.db, `INSERT INTO webhook_deliveries (
+    id, webhook_id, event, payload, next_attempt_at, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)`, uuid.New(), webhookID, arg.Event, string(arg.Payload), createdAt, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg postgres.ListWebhookDeliveriesParams) ([]postgres.WebhookDelivery, error) {
+	return many(ctx, q.db, webhookDeliveryFields, `SELECT * FROM webhook_deliveries
+WHERE webhook_id = ?
+ORDER BY created_at DESC
+LIMIT ?`, arg.WebhookID, arg.Limit)
+}
+
+// ClaimWebhookDeliveries needs no row lock: SQLite runs a single write at a
+// time.
+func (q *Queries) ClaimWebhookDeliveries(ctx context.Context, arg postgres.ClaimWebhookDeliveriesParams) ([]postgres.ClaimWebhookDeliveriesRow, error) {
+	return many(ctx, q.db, func(row *postgres.ClaimWebhookDeliveriesRow) []any {
+		return []any{&row.ID, &row.Event, &row.Payload, &row.Attempts, &row.Url, &row.Secret}
+	}, `UPDATE webhook_deliveries SET
+next_attempt_at = ?
+WHERE id IN (
+    SELECT pending.id FROM webhook_deliveries AS pending
+    WHERE pending.status = 'pending' AND pending.next_attempt_at <= ?
+    ORDER BY pending.next_attempt_at
+    LIMIT ?
+)
+RETURNING id, event, payload, attempts,
+    (SELECT url FROM webhooks WHERE webhooks.id = webhook_deliveries.webhook_id),
+    (SELECT secret FROM webhooks WHERE webhooks.id = webhook_deliveries.webhook_id)`,
+		formatTime(arg.LeaseUntil), formatTime(arg.Now), arg.MaxDeliveries)
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg postgres.UpdateWebhookDeliveryParams) error {
+	_, err := exec(ctx, q.db, `UPDATE webhook_deliveries SET
+status = ?,
+attempts = attempts + 1,
+next_attempt_at = ?,
+last_status_code = ?,
+last_error = ?,
+delivered_at = ?
+WHERE id = ?`, arg.Status, formatTime(arg.NextAttemptAt), arg.LastStatusCode, arg.LastError, formatNullTime(arg.DeliveredAt), arg.ID)
+	return err
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg postgres.RetryWebhookDeliveryParams) (int64, error) {
+	return exec(ctx, q.db, `UPDATE webhook_deliveries SET
+status = 'pending',
+attempts = 0,
+last_error = '',
+next_attempt_at = ?
+WHERE id = ? AND webhook_id = ? AND webhook_id IN (SELECT id FROM webhooks WHERE household_id = ?)`, now(), arg.ID, arg.WebhookID, arg.HouseholdID)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// Store is the database behind the repository. Whatever the database, its
+// queries fail like the PostgreSQL ones: with pgx.ErrNoRows when no row is
+// found and with a *pgconn.PgError naming the violated constraint, so that
+// the errors are handled the same way.
+type Store interface {
+	postgres.Querier
+	// InTx runs fn with queries bound to a transaction, committed when fn
+	// succeeds.
+	InTx(ctx context.Context, fn func(q postgres.Querier) error) error
+	// InSnapshot runs fn with read-only queries seeing a consistent snapshot
+	// of the database.
+	InSnapshot(ctx context.Context, fn func(q postgres.Querier) error) error
+	// Listen calls notify with the payload of the changes notified with
+	// NotifyChange, by any instance of the application sharing the database,
+	// until ctx is canceled or the database connection is lost.
+	Listen(ctx context.Context, notify func(payload string)) error
+	Ping(ctx context.Context) error
+	// SchemaVersion returns the version of the last migration applied to the
+	// database, 0 when none was, and whether it failed.
+	SchemaVersion(ctx context.Context) (uint, bool, error)
+}
+
+type postgresStore struct {
+	*postgres.Queries
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore returns the store of a PostgreSQL database.
+func NewPostgresStore(db *pgxpool.Pool) Store {
+	return &postgresStore{Queries: postgres.New(db), db: db}
+}
+
+func (s *postgresStore) InTx(ctx context.Context, fn func(q postgres.Querier) error) error {
+	return s.inTx(ctx, pgx.TxOptions{}, fn)
+}
+
+func (s *postgresStore) InSnapshot(ctx context.Context, fn func(q postgres.Querier) error) error {
+	return s.inTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, fn)
+}
+
+func (s *postgresStore) inTx(ctx context.Context, options pgx.TxOptions, fn func(q postgres.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, options)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if err = fn(s.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// changesChannel is the channel the NotifyChange query notifies on.
+const changesChannel = "changes"
+
+// Listen listens on a connection of its own, taken out of the pool and
+// closed when done.
+func (s *postgresStore) Listen(ctx context.Context, notify func(payload string)) error {
+	pooled, err := s.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to acquire connection: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+	if _, err = conn.Exec(ctx, "LISTEN "+changesChannel); err != nil {
+		return fmt.Errorf("unable to listen: %w", err)
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("unable to wait for notification: %w", err)
+		}
+		notify(notification.Payload)
+	}
+}
+
+func (s *postgresStore) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+func (s *postgresStore) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := s.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to get schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
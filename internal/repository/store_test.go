@@ -0,0 +1,30 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStore(t *testing.T) {
+	ctx := context.Background()
+	pgContainer, err := repository.CreatePostgesContainer(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { pgContainer.Terminate(ctx) })
+	require.NoError(t, database.Migrate(pgContainer.ConnectionString))
+
+	pool, err := pgxpool.New(ctx, pgContainer.ConnectionString)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	storetest.Run(t, func(t *testing.T) repository.Store {
+		_, err := pool.Exec(ctx, "TRUNCATE households, chores, users, tasks, accounts, sessions, webhooks, webhook_deliveries RESTART IDENTITY CASCADE")
+		require.NoError(t, err)
+		return repository.NewPostgresStore(pool)
+	})
+}
@@ -0,0 +1,1097 @@
+// Package storetest checks that a repository.Store behaves like the
+// PostgreSQL database the repository was written for. The checks go through
+// the repository, so that they cover the errors it returns too, and are run
+// against every store.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs the contract tests, each on an empty store returned by newStore.
+// The context of the tests is bound to a household created beforehand, with
+// an account named "admin".
+func Run(t *testing.T, newStore func(t *testing.T) repository.Store) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store)
+	}{
+		{"Households", testHouseholds},
+		{"Chores", testChores},
+		{"Users", testUsers},
+		{"Archive", testArchive},
+		{"Tasks", testTasks},
+		{"SearchTasks", testSearchTasks},
+		{"Reports", testReports},
+		{"Timeline", testTimeline},
+		{"Timers", testTimers},
+		{"ConcurrentTimerStops", testConcurrentTimerStops},
+		{"Accounts", testAccounts},
+		{"Sessions", testSessions},
+		{"Calendars", testCalendars},
+		{"Webhooks", testWebhooks},
+		{"Transactions", testTransactions},
+		{"Changes", testChanges},
+		{"Backups", testBackups},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newStore(t)
+			repo := repository.New(repository.NewRepositoryParams{Store: store})
+			household, _, err := repo.CreateHousehold(context.Background(), "Home", repository.ValidatedAccount{Username: "admin", Password: "password"})
+			require.NoError(t, err)
+			tc.test(t, repository.WithHousehold(context.Background(), household.ID), repo, store)
+		})
+	}
+}
+
+func createChore(t *testing.T, ctx context.Context, repo *repository.Repository, name string) postgres.Chore {
+	chore, err := repo.CreateChore(ctx, postgres.CreateChoreParams{Name: name, DefaultDurationMn: 15, ScheduleKind: "none"})
+	require.NoError(t, err)
+	return chore
+}
+
+func createUser(t *testing.T, ctx context.Context, repo *repository.Repository, name string) postgres.User {
+	user, err := repo.CreateUser(ctx, postgres.CreateUserParams{Name: name})
+	require.NoError(t, err)
+	return user
+}
+
+func createTask(t *testing.T, ctx context.Context, repo *repository.Repository, user postgres.User, chore postgres.Chore, startedAt time.Time, durationMn int32) postgres.Task {
+	task, err := repo.CreateTask(ctx, postgres.CreateTaskParams{UserID: user.ID, ChoreID: chore.ID, StartedAt: startedAt, DurationMn: durationMn})
+	require.NoError(t, err)
+	return task
+}
+
+// otherHousehold returns the context of a second household.
+func otherHousehold(t *testing.T, repo *repository.Repository) context.Context {
+	household, _, err := repo.CreateHousehold(context.Background(), "Other", repository.ValidatedAccount{Username: "other", Password: "password"})
+	require.NoError(t, err)
+	return repository.WithHousehold(context.Background(), household.ID)
+}
+
+func testHouseholds(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	_, _, err := repo.CreateHousehold(ctx, "", repository.ValidatedAccount{Username: "empty", Password: "password"})
+	assert.ErrorIs(t, err, repository.ErrInvalidName)
+	_, _, err = repo.CreateHousehold(ctx, "Other", repository.ValidatedAccount{Username: "admin", Password: "password"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	otherHousehold(t, repo)
+
+	households, err := repo.ListHouseholds(ctx)
+	require.NoError(t, err)
+	require.Len(t, households, 2)
+	assert.Equal(t, "Home", households[0].Name)
+	assert.Equal(t, "Other", households[1].Name)
+	assert.WithinDuration(t, time.Now(), households[0].CreatedAt, time.Minute)
+
+	household, err := repo.GetHousehold(ctx, households[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, households[1], household)
+	_, err = repo.GetHousehold(ctx, households[1].ID+1)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	count, err := store.CountHouseholds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func testChores(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	dishes := createChore(t, ctx, repo, "Dishes")
+	_, err := repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Dishes", ScheduleKind: "none"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	_, err = repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "", ScheduleKind: "none"})
+	assert.ErrorIs(t, err, repository.ErrInvalidName)
+	for _, params := range []postgres.CreateChoreParams{
+		{Name: "Laundry", ScheduleKind: "yearly"},
+		{Name: "Laundry", ScheduleKind: "interval", ScheduleIntervalDays: -1},
+		{Name: "Laundry", ScheduleKind: "weekly", ScheduleWeekdays: 128},
+		{Name: "Laundry", ScheduleKind: "monthly", ScheduleMonthDay: 32},
+	} {
+		_, err = repo.CreateChore(ctx, params)
+		assert.ErrorIs(t, err, repository.ErrInvalidSchedule, "%+v", params)
+	}
+	_, err = repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Laundry", ScheduleKind: "none", Difficulty: 11})
+	assert.ErrorIs(t, err, repository.ErrInvalidDifficulty)
+	assert.Equal(t, int32(1), dishes.Difficulty, "chores are of the lowest difficulty by default")
+
+	laundry, err := repo.CreateChore(ctx, postgres.CreateChoreParams{
+		Name:                 "Laundry",
+		Description:          "Wash and dry",
+		DefaultDurationMn:    30,
+		ScheduleKind:         "interval",
+		ScheduleIntervalDays: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Wash and dry", laundry.Description)
+	assert.Equal(t, int32(3), laundry.ScheduleIntervalDays)
+	vacuum := createChore(t, ctx, repo, "Vacuum")
+
+	// Each household has its own chores and chore names.
+	otherCtx := otherHousehold(t, repo)
+	createChore(t, otherCtx, repo, "Dishes")
+	_, err = repo.GetChore(otherCtx, dishes.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	chores, err := repo.ListChores(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.Chore{dishes, laundry, vacuum}, chores)
+
+	updated, err := repo.UpdateChore(ctx, vacuum.ID, postgres.CreateChoreParams{Name: "Vacuum", ScheduleKind: "weekly", ScheduleWeekdays: 0b10})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0b10), updated.ScheduleWeekdays)
+	_, err = repo.UpdateChore(ctx, vacuum.ID, postgres.CreateChoreParams{Name: "Dishes", ScheduleKind: "none"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	_, err = repo.UpdateChore(otherCtx, vacuum.ID, postgres.CreateChoreParams{Name: "Vacuum", ScheduleKind: "none"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	chore, err := repo.GetChore(ctx, vacuum.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, chore)
+
+	user := createUser(t, ctx, repo, "Alice")
+	createTask(t, ctx, repo, user, dishes, time.Now(), 10)
+	assert.ErrorIs(t, repo.DeleteChore(ctx, dishes.ID), repository.ErrStillInUse)
+	require.NoError(t, repo.DeleteChore(otherCtx, vacuum.ID))
+	_, err = repo.GetChore(ctx, vacuum.ID)
+	require.NoError(t, err, "only the chores of the household are deleted")
+	require.NoError(t, repo.DeleteChore(ctx, vacuum.ID))
+	_, err = repo.GetChore(ctx, vacuum.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testUsers(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	bob := createUser(t, ctx, repo, "Bob")
+	alice := createUser(t, ctx, repo, "Alice")
+	_, err := repo.CreateUser(ctx, postgres.CreateUserParams{Name: "Alice"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	_, err = repo.CreateUser(ctx, postgres.CreateUserParams{Name: ""})
+	assert.ErrorIs(t, err, repository.ErrInvalidName)
+
+	otherCtx := otherHousehold(t, repo)
+	createUser(t, otherCtx, repo, "Alice")
+	_, err = repo.GetUser(otherCtx, alice.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	users, err := repo.ListUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.User{alice, bob}, users)
+
+	assert.Equal(t, int32(1), bob.Share, "users have the lowest share by default")
+	updated, err := repo.UpdateUser(ctx, bob.ID, postgres.CreateUserParams{Name: "Robert", Share: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "Robert", updated.Name)
+	assert.Equal(t, int32(3), updated.Share)
+	_, err = repo.UpdateUser(ctx, bob.ID, postgres.CreateUserParams{Name: "Robert", Share: 101})
+	assert.ErrorIs(t, err, repository.ErrInvalidShare)
+	_, err = repo.UpdateUser(ctx, bob.ID, postgres.CreateUserParams{Name: "Alice"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	_, err = repo.UpdateUser(otherCtx, bob.ID, postgres.CreateUserParams{Name: "Bobby"})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	chore := createChore(t, ctx, repo, "Dishes")
+	createTask(t, ctx, repo, alice, chore, time.Now(), 10)
+	assert.ErrorIs(t, repo.DeleteUser(ctx, alice.ID), repository.ErrStillInUse)
+	require.NoError(t, repo.DeleteUser(otherCtx, bob.ID))
+	_, err = repo.GetUser(ctx, bob.ID)
+	require.NoError(t, err, "only the users of the household are deleted")
+	require.NoError(t, repo.DeleteUser(ctx, bob.ID))
+	_, err = repo.GetUser(ctx, bob.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testArchive(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	laundry, err := repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Laundry", ScheduleKind: "none", Difficulty: 3})
+	require.NoError(t, err)
+	task := createTask(t, ctx, repo, alice, dishes, time.Now().Add(-time.Hour), 10)
+
+	// Archived chores and users keep their tasks but leave the lists.
+	archivedDishes, err := repo.SetChoreArchived(ctx, dishes.ID, true)
+	require.NoError(t, err)
+	assert.True(t, archivedDishes.Archived)
+	archivedAlice, err := repo.SetUserArchived(ctx, alice.ID, true)
+	require.NoError(t, err)
+	assert.True(t, archivedAlice.Archived)
+	chores, err := repo.ListChores(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.Chore{laundry}, chores)
+	chores, err = repo.ListArchivedChores(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.Chore{archivedDishes}, chores)
+	users, err := repo.ListUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.User{bob}, users)
+	users, err = repo.ListArchivedUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.User{archivedAlice}, users)
+	_, err = repo.StartTimer(ctx, bob.ID, dishes.ID, time.Now())
+	assert.ErrorIs(t, err, repository.ErrArchived)
+	_, err = repo.StartTimer(ctx, alice.ID, laundry.ID, time.Now())
+	assert.ErrorIs(t, err, repository.ErrArchived)
+	_, err = repo.StartTimer(ctx, bob.ID, 999, time.Now())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	tasks, err := repo.GetChoreTasks(ctx, dishes.ID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	_, err = repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Dishes", ScheduleKind: "none"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName, "archived chores keep their name")
+	_, err = repo.SetChoreArchived(otherHousehold(t, repo), dishes.ID, false)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	restored, err := repo.SetChoreArchived(ctx, dishes.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, dishes, restored)
+
+	// Reassigning moves the tasks before deleting, the points following the
+	// difficulty of the new chore.
+	err = repo.ReassignAndDeleteChore(ctx, dishes.ID, dishes.ID)
+	assert.ErrorIs(t, err, repository.ErrValidation)
+	err = repo.ReassignAndDeleteChore(ctx, dishes.ID, laundry.ID+100)
+	assert.ErrorIs(t, err, repository.ErrValidation)
+	require.NoError(t, repo.ReassignAndDeleteChore(ctx, dishes.ID, laundry.ID))
+	_, err = repo.GetChore(ctx, dishes.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	moved, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, laundry.ID, moved.ChoreID)
+	assert.Equal(t, int64(30), moved.Points)
+
+	err = repo.ReassignAndDeleteUser(ctx, alice.ID, alice.ID)
+	assert.ErrorIs(t, err, repository.ErrValidation)
+	require.NoError(t, repo.ReassignAndDeleteUser(ctx, alice.ID, bob.ID))
+	_, err = repo.GetUser(ctx, alice.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	moved, err = repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, moved.UserID)
+
+	// The archived state is part of the backups.
+	_, err = repo.SetUserArchived(ctx, bob.ID, true)
+	require.NoError(t, err)
+	backup, err := repo.CreateBackup(ctx)
+	require.NoError(t, err)
+	_, err = repo.RestoreBackup(ctx, backup, true)
+	require.NoError(t, err)
+	householdID, err := repository.HouseholdFromContext(ctx)
+	require.NoError(t, err)
+	households, err := repo.ListHouseholds(ctx)
+	require.NoError(t, err)
+	require.Len(t, households, 4)
+	for _, household := range households {
+		if household.Name != "Home" || household.ID == householdID {
+			continue
+		}
+		users, err = repo.ListArchivedUsers(repository.WithHousehold(context.Background(), household.ID))
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "Bob", users[0].Name)
+	}
+}
+
+func testTasks(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	laundry := createChore(t, ctx, repo, "Laundry")
+	otherCtx := otherHousehold(t, repo)
+	otherUser := createUser(t, otherCtx, repo, "Carol")
+	otherChore := createChore(t, otherCtx, repo, "Cooking")
+
+	now := time.Now()
+	_, err := repo.CreateTask(ctx, postgres.CreateTaskParams{UserID: otherUser.ID, ChoreID: dishes.ID, StartedAt: now, DurationMn: 10})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorContains(t, err, "task user doesn't exist")
+	_, err = repo.CreateTask(ctx, postgres.CreateTaskParams{UserID: alice.ID, ChoreID: otherChore.ID, StartedAt: now, DurationMn: 10})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorContains(t, err, "task chore doesn't exist")
+
+	first := createTask(t, ctx, repo, alice, dishes, now.Add(-2*time.Hour), 10)
+	second := createTask(t, ctx, repo, bob, dishes, now.Add(-time.Hour), 20)
+	third := createTask(t, ctx, repo, alice, laundry, now, 30)
+	assert.Equal(t, alice.ID, first.UserID)
+	assert.Equal(t, dishes.ID, first.ChoreID)
+	assert.WithinDuration(t, now.Add(-2*time.Hour), first.StartedAt, time.Microsecond)
+
+	task, err := repo.GetTask(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, second, task)
+	_, err = repo.GetTask(otherCtx, second.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.GetTask(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.Task{first, second, third}, tasks)
+
+	userTasks, err := repo.GetUserTasks(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.GetUserTasksRow{{Task: third, Chore: laundry}, {Task: first, Chore: dishes}}, userTasks)
+	choreTasks, err := repo.GetChoreTasks(ctx, dishes.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.GetChoreTasksRow{{Task: second, User: bob}, {Task: first, User: alice}}, choreTasks)
+	usersTasks, err := repo.ListUsersTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.ListUsersTasksRow{
+		{Task: third, Chore: laundry, User: alice},
+		{Task: second, Chore: dishes, User: bob},
+		{Task: first, Chore: dishes, User: alice},
+	}, usersTasks)
+	rangeTasks, err := repo.ListUsersTasksBetween(ctx, 0, second.StartedAt, third.StartedAt)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.ListUsersTasksBetweenRow{
+		{Task: third, Chore: laundry, User: alice},
+		{Task: second, Chore: dishes, User: bob},
+	}, rangeTasks, "both ends are included")
+	rangeTasks, err = repo.ListUsersTasksBetween(ctx, bob.ID, first.StartedAt, third.StartedAt)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.ListUsersTasksBetweenRow{{Task: second, Chore: dishes, User: bob}}, rangeTasks)
+	rangeTasks, err = repo.ListUsersTasksBetween(otherCtx, 0, first.StartedAt, third.StartedAt)
+	require.NoError(t, err)
+	assert.Empty(t, rangeTasks)
+
+	updated, err := repo.UpdateTask(ctx, second.ID, postgres.CreateTaskParams{UserID: alice.ID, ChoreID: laundry.ID, StartedAt: now.Add(-3 * time.Hour), DurationMn: 25, Description: "towels"})
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, updated.UserID)
+	assert.Equal(t, laundry.ID, updated.ChoreID)
+	assert.Equal(t, "towels", updated.Description)
+	_, err = repo.UpdateTask(ctx, second.ID, postgres.CreateTaskParams{UserID: otherUser.ID, ChoreID: laundry.ID, StartedAt: now, DurationMn: 25})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.UpdateTask(otherCtx, second.ID, postgres.CreateTaskParams{UserID: otherUser.ID, ChoreID: otherChore.ID, StartedAt: now, DurationMn: 25})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	stats, err := store.ChoresTasksStats(ctx, dishes.HouseholdID)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	for _, stat := range stats {
+		switch stat.ChoreID {
+		case dishes.ID:
+			assert.Equal(t, int64(1), stat.TasksCount)
+		case laundry.ID:
+			assert.Equal(t, int64(2), stat.TasksCount)
+			assert.Equal(t, updated.StartedAt, stat.FirstStartedAt)
+			assert.Equal(t, third.StartedAt, stat.LastStartedAt)
+		}
+	}
+
+	require.NoError(t, repo.DeleteTask(otherCtx, first.ID))
+	_, err = repo.GetTask(ctx, first.ID)
+	require.NoError(t, err, "only the tasks of the household are deleted")
+	require.NoError(t, repo.DeleteTask(ctx, first.ID))
+	_, err = repo.GetTask(ctx, first.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	require.NoError(t, repo.DeleteChore(ctx, dishes.ID))
+}
+
+func testSearchTasks(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	laundry := createChore(t, ctx, repo, "Laundry")
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var tasks []postgres.Task
+	for i := range 7 {
+		user, chore := alice, dishes
+		if i%2 == 1 {
+			user, chore = bob, laundry
+		}
+		task, err := repo.CreateTask(ctx, postgres.CreateTaskParams{
+			UserID:      user.ID,
+			ChoreID:     chore.ID,
+			StartedAt:   start.Add(time.Duration(i) * 24 * time.Hour),
+			DurationMn:  int32(10 + i%3*10),
+			Description: fmt.Sprintf("Task %d", i),
+		})
+		require.NoError(t, err)
+		tasks = append(tasks, task)
+	}
+	otherCtx := otherHousehold(t, repo)
+	createTask(t, otherCtx, repo, createUser(t, otherCtx, repo, "Carol"), createChore(t, otherCtx, repo, "Dishes"), start, 10)
+
+	// search pages through the tasks matching the filter and returns their
+	// IDs in order.
+	search := func(filter repository.TaskFilter, size int32) []uuid.UUID {
+		var ids []uuid.UUID
+		cursor := ""
+		for range 10 {
+			page, err := repo.SearchTasks(ctx, filter, cursor, size)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Tasks), int(size))
+			for _, row := range page.Tasks {
+				ids = append(ids, row.Task.ID)
+			}
+			if page.Next == "" {
+				return ids
+			}
+			cursor = page.Next
+		}
+		t.Fatal("too many pages")
+		return nil
+	}
+	ids := func(indexes ...int) []uuid.UUID {
+		var ids []uuid.UUID
+		for _, i := range indexes {
+			ids = append(ids, tasks[i].ID)
+		}
+		return ids
+	}
+
+	page, err := repo.SearchTasks(ctx, repository.TaskFilter{}, "", 3)
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 3)
+	assert.Equal(t, tasks[6], page.Tasks[0].Task)
+	assert.Equal(t, dishes, page.Tasks[0].Chore)
+	assert.Equal(t, alice, page.Tasks[0].User)
+	assert.NotEmpty(t, page.Next)
+	assert.Equal(t, ids(6, 5, 4, 3, 2, 1, 0), search(repository.TaskFilter{}, 3))
+	assert.Equal(t, ids(0, 1, 2, 3, 4, 5, 6), search(repository.TaskFilter{Ascending: true}, 2))
+	assert.Equal(t, ids(5, 3, 1), search(repository.TaskFilter{UserID: bob.ID}, 2))
+	assert.Equal(t, ids(6, 4, 2, 0), search(repository.TaskFilter{ChoreID: dishes.ID}, 7))
+	assert.Empty(t, search(repository.TaskFilter{UserID: bob.ID, ChoreID: dishes.ID}, 2))
+	assert.Equal(t, ids(4, 3, 2), search(repository.TaskFilter{From: start.Add(2 * 24 * time.Hour), To: start.Add(5 * 24 * time.Hour)}, 2))
+	assert.Equal(t, ids(3), search(repository.TaskFilter{Search: "task 3"}, 2))
+	// Durations are 10, 20, 30, 10, 20, 30, 10: ties are sorted on the start.
+	assert.Equal(t, ids(5, 2, 4, 1, 6, 3, 0), search(repository.TaskFilter{Sort: repository.TaskSortDuration}, 2))
+	assert.Equal(t, ids(0, 3, 6, 1, 4, 2, 5), search(repository.TaskFilter{Sort: repository.TaskSortPoints, Ascending: true}, 3))
+
+	_, err = repo.SearchTasks(ctx, repository.TaskFilter{}, "invalid", 2)
+	assert.ErrorIs(t, err, repository.ErrValidation)
+	page, err = repo.SearchTasks(otherCtx, repository.TaskFilter{}, "", 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Tasks, 1)
+	assert.Empty(t, page.Next)
+}
+
+func testReports(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	laundry, err := repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Laundry", ScheduleKind: "none", Difficulty: 3})
+	require.NoError(t, err)
+	now := time.Now()
+	createTask(t, ctx, repo, alice, dishes, now.Add(-48*time.Hour), 100)
+	createTask(t, ctx, repo, alice, dishes, now.Add(-2*time.Hour), 10)
+	createTask(t, ctx, repo, alice, dishes, now.Add(-time.Hour), 15)
+	ironing := createTask(t, ctx, repo, bob, laundry, now.Add(-time.Hour), 30)
+	assert.Equal(t, int64(90), ironing.Points)
+
+	report, err := repo.GetChoreReport(ctx, now.Add(-24*time.Hour), now, repository.MetricMinutes)
+	require.NoError(t, err)
+	assert.Equal(t, repository.MetricMinutes, report.Metric)
+	assert.Equal(t, map[string]map[string]int64{
+		"Dishes":  {"Alice": 25},
+		"Laundry": {"Bob": 30},
+	}, report.Report)
+	assert.ElementsMatch(t, []string{"Alice", "Bob"}, report.Users)
+	assert.ElementsMatch(t, []string{"Dishes", "Laundry"}, report.Chores)
+
+	report, err = repo.GetChoreReport(ctx, now.Add(-24*time.Hour), now, repository.MetricTasks)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int64{
+		"Dishes":  {"Alice": 2},
+		"Laundry": {"Bob": 1},
+	}, report.Report)
+
+	// The points of a task follow the difficulty of its chore when it is saved.
+	_, err = repo.UpdateChore(ctx, dishes.ID, postgres.CreateChoreParams{Name: "Dishes", ScheduleKind: "none", Difficulty: 2})
+	require.NoError(t, err)
+	ironing, err = repo.UpdateTask(ctx, ironing.ID, postgres.CreateTaskParams{UserID: bob.ID, ChoreID: laundry.ID, StartedAt: ironing.StartedAt, DurationMn: 20})
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), ironing.Points)
+	report, err = repo.GetChoreReport(ctx, now.Add(-24*time.Hour), now, repository.MetricPoints)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int64{
+		"Dishes":  {"Alice": 25},
+		"Laundry": {"Bob": 60},
+	}, report.Report)
+
+	fairness, err := repo.GetFairnessReport(ctx, now.Add(-24*time.Hour), now, repository.MetricPoints)
+	require.NoError(t, err)
+	assert.Equal(t, int64(85), fairness.Total)
+	// Alice's target of 43 points gets the odd one of the even split.
+	assert.Equal(t, []repository.FairnessDebt{{From: "Alice", To: "Bob", Amount: 18}}, fairness.Debts)
+}
+
+func testTimeline(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	// The first two tasks are on the same day in UTC but not in Paris.
+	createTask(t, ctx, repo, alice, dishes, time.Date(2024, 3, 31, 23, 30, 0, 0, paris), 10)
+	createTask(t, ctx, repo, alice, dishes, time.Date(2024, 4, 1, 0, 30, 0, 0, paris), 20)
+	createTask(t, ctx, repo, bob, dishes, time.Date(2024, 4, 10, 10, 0, 0, 0, paris), 30)
+	start := time.Date(2024, 3, 25, 0, 0, 0, 0, paris)
+	end := time.Date(2024, 4, 15, 0, 0, 0, 0, paris)
+
+	timeline, err := repo.GetTimeline(ctx, start, end, repository.BucketWeek, repository.MetricMinutes, paris)
+	require.NoError(t, err)
+	require.Len(t, timeline.Periods, 3)
+	assert.True(t, timeline.Periods[1].Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, paris)), "weeks start on Monday")
+	assert.Equal(t, []string{"Alice", "Bob"}, timeline.Users)
+	assert.Equal(t, map[string][]int64{"Alice": {10, 20, 0}, "Bob": {0, 0, 30}}, timeline.Series)
+
+	timeline, err = repo.GetTimeline(ctx, start, end, repository.BucketMonth, repository.MetricTasks, paris)
+	require.NoError(t, err)
+	require.Len(t, timeline.Periods, 2)
+	assert.True(t, timeline.Periods[0].Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, paris)))
+	assert.Equal(t, map[string][]int64{"Alice": {1, 1}, "Bob": {0, 1}}, timeline.Series)
+
+	timeline, err = repo.GetTimeline(ctx, start, end, repository.BucketDay, repository.MetricPoints, paris)
+	require.NoError(t, err)
+	require.Len(t, timeline.Periods, 21)
+	assert.Equal(t, []int64{10, 20}, timeline.Series["Alice"][6:8])
+}
+
+func testTimers(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	dishes, err := repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Dishes", DefaultDurationMn: 15, ScheduleKind: "none", Difficulty: 2})
+	require.NoError(t, err)
+	laundry := createChore(t, ctx, repo, "Laundry")
+	otherCtx := otherHousehold(t, repo)
+	otherUser := createUser(t, otherCtx, repo, "Carol")
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	_, err = repo.StartTimer(ctx, otherUser.ID, dishes.ID, start)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	timer, err := repo.StartTimer(ctx, alice.ID, dishes.ID, start)
+	require.NoError(t, err)
+	assert.True(t, timer.StartedAt.Equal(start))
+	assert.True(t, timer.ResumedAt.Valid)
+	_, err = repo.StartTimer(ctx, alice.ID, laundry.ID, start)
+	assert.ErrorIs(t, err, repository.ErrTimerRunning)
+	other, err := repo.StartTimer(ctx, bob.ID, laundry.ID, start.Add(time.Minute))
+	require.NoError(t, err)
+
+	timers, err := repo.ListTimers(ctx)
+	require.NoError(t, err)
+	require.Len(t, timers, 2)
+	assert.Equal(t, timer, timers[0].Timer)
+	assert.Equal(t, alice, timers[0].User)
+	assert.Equal(t, dishes, timers[0].Chore)
+	timers, err = repo.ListTimers(otherCtx)
+	require.NoError(t, err)
+	assert.Empty(t, timers)
+
+	// 10 minutes, a 5 minutes break, then 20 more minutes and 20 seconds.
+	timer, err = repo.PauseTimer(ctx, timer.ID, start.Add(10*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, timer.ResumedAt.Valid)
+	assert.Equal(t, int64(600), timer.ElapsedSeconds)
+	timer, err = repo.PauseTimer(ctx, timer.ID, start.Add(12*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), timer.ElapsedSeconds, "pausing a paused timer changes nothing")
+	assert.Equal(t, 10*time.Minute, repository.TimerElapsed(timer, start.Add(time.Hour)))
+	timer, err = repo.ResumeTimer(ctx, timer.ID, start.Add(15*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, timer.ResumedAt.Time.Equal(start.Add(15*time.Minute)))
+	_, err = repo.PauseTimer(otherCtx, timer.ID, start)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	task, err := repo.StopTimer(ctx, timer.ID, start.Add(35*time.Minute+20*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, task.UserID)
+	assert.Equal(t, dishes.ID, task.ChoreID)
+	assert.True(t, task.StartedAt.Equal(start))
+	assert.Equal(t, int32(30), task.DurationMn)
+	assert.Equal(t, int64(60), task.Points)
+	_, err = repo.StopTimer(ctx, timer.ID, start)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.StartTimer(ctx, alice.ID, laundry.ID, start)
+	require.NoError(t, err, "the user can start a new timer once stopped")
+
+	assert.ErrorIs(t, repo.DiscardTimer(otherCtx, other.ID), repository.ErrNotFound)
+	require.NoError(t, repo.DiscardTimer(ctx, other.ID))
+	assert.ErrorIs(t, repo.DiscardTimer(ctx, other.ID), repository.ErrNotFound)
+
+	// The timers go with their user or chore.
+	_, err = repo.StartTimer(ctx, bob.ID, dishes.ID, start)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteChore(ctx, laundry.ID))
+	require.NoError(t, repo.DeleteUser(ctx, bob.ID))
+	timers, err = repo.ListTimers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, timers)
+}
+
+func testAccounts(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	linked := pgtype.Int4{Int32: alice.ID, Valid: true}
+	_, err := repo.CreateAccount(ctx, repository.ValidatedAccount{Username: "admin", Password: "password"})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	_, err = repo.CreateAccount(ctx, repository.ValidatedAccount{Username: "", Password: "password"})
+	assert.ErrorIs(t, err, repository.ErrInvalidName)
+	_, err = repo.CreateAccount(ctx, repository.ValidatedAccount{Username: "alice", Password: "password", UserID: pgtype.Int4{Int32: alice.ID + 1, Valid: true}})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	account, err := repo.CreateAccount(ctx, repository.ValidatedAccount{Username: "alice", Password: "password", UserID: linked})
+	require.NoError(t, err)
+	assert.Equal(t, linked, account.UserID)
+	_, err = repo.CreateAccount(ctx, repository.ValidatedAccount{Username: "alice2", Password: "password", UserID: linked})
+	assert.ErrorIs(t, err, repository.ErrAlreadyLinked)
+
+	// The users of another household can't be linked.
+	otherCtx := otherHousehold(t, repo)
+	bob := createUser(t, ctx, repo, "Bob")
+	_, err = repo.CreateAccount(otherCtx, repository.ValidatedAccount{Username: "carol", Password: "password", UserID: pgtype.Int4{Int32: bob.ID, Valid: true}})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.GetAccount(otherCtx, account.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "admin", accounts[0].Username)
+	assert.Equal(t, account, accounts[1])
+	count, err := repo.CountAccounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	_, err = repo.Authenticate(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, repository.ErrInvalidCredentials)
+	_, err = repo.UpdateAccount(ctx, account.ID, repository.ValidatedAccount{Username: "admin", UserID: linked})
+	assert.ErrorIs(t, err, repository.ErrDuplicateName)
+	updated, err := repo.UpdateAccount(ctx, account.ID, repository.ValidatedAccount{Username: "ali", Password: "new password", UserID: linked})
+	require.NoError(t, err)
+	assert.Equal(t, "ali", updated.Username)
+	authenticated, err := repo.Authenticate(ctx, "ali", "new password")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, authenticated.ID)
+
+	// Deleting the user unlinks the account.
+	require.NoError(t, repo.DeleteUser(ctx, alice.ID))
+	unlinked, err := repo.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	assert.False(t, unlinked.UserID.Valid)
+
+	require.NoError(t, repo.DeleteAccount(ctx, account.ID))
+	_, err = repo.GetAccount(ctx, account.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testSessions(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	admin, err := repo.Authenticate(ctx, "admin", "password")
+	require.NoError(t, err)
+
+	token, expiresAt, err := repo.CreateSession(ctx, admin.ID, time.Hour)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	account, err := repo.GetSessionAccount(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, admin, account)
+	_, err = repo.GetSessionAccount(ctx, "unknown")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	expired, _, err := repo.CreateSession(ctx, admin.ID, -time.Minute)
+	require.NoError(t, err)
+	_, err = repo.GetSessionAccount(ctx, expired)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	require.NoError(t, repo.DeleteExpiredSessions(ctx))
+
+	require.NoError(t, repo.DeleteSession(ctx, token))
+	_, err = repo.GetSessionAccount(ctx, token)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	// Changing the password or deleting the account ends its sessions.
+	token, _, err = repo.CreateSession(ctx, admin.ID, time.Hour)
+	require.NoError(t, err)
+	_, err = repo.UpdateAccount(ctx, admin.ID, repository.ValidatedAccount{Username: "admin", Password: "new password"})
+	require.NoError(t, err)
+	_, err = repo.GetSessionAccount(ctx, token)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	token, _, err = repo.CreateSession(ctx, admin.ID, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteAccount(ctx, admin.ID))
+	_, err = repo.GetSessionAccount(ctx, token)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testCalendars(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	bob := createUser(t, ctx, repo, "Bob")
+	carol := createUser(t, ctx, repo, "Carol")
+	laundry := createChore(t, ctx, repo, "Laundry")
+	dishes, err := repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Dishes", DefaultDurationMn: 15, ScheduleKind: repository.ScheduleInterval, ScheduleIntervalDays: 2})
+	require.NoError(t, err)
+	otherCtx := otherHousehold(t, repo)
+	otherUser := createUser(t, otherCtx, repo, "Dave")
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	task := createTask(t, ctx, repo, alice, laundry, now.Add(-2*time.Hour), 30)
+	createTask(t, ctx, repo, bob, dishes, now.Add(-5*24*time.Hour), 10)
+	createTask(t, ctx, repo, alice, laundry, now.Add(-2*repository.CalendarHistory), 10)
+
+	_, _, err = repo.CreateCalendarFeed(ctx, pgtype.Int4{Int32: otherUser.ID, Valid: true})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	household, householdToken, err := repo.CreateCalendarFeed(ctx, pgtype.Int4{})
+	require.NoError(t, err)
+	aliceFeed, aliceToken, err := repo.CreateCalendarFeed(ctx, pgtype.Int4{Int32: alice.ID, Valid: true})
+	require.NoError(t, err)
+	_, carolToken, err := repo.CreateCalendarFeed(ctx, pgtype.Int4{Int32: carol.ID, Valid: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, householdToken, aliceToken)
+	feeds, err := repo.ListCalendarFeeds(ctx)
+	require.NoError(t, err)
+	require.Len(t, feeds, 3)
+	assert.Equal(t, household, feeds[0])
+	assert.Equal(t, aliceFeed, feeds[1])
+	feeds, err = repo.ListCalendarFeeds(otherCtx)
+	require.NoError(t, err)
+	assert.Empty(t, feeds)
+
+	// The calendar is found by its token alone, without household.
+	calendar, err := repo.GetCalendar(context.Background(), householdToken, now, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "Home chores", calendar.Name)
+	assert.Equal(t, "UTC", calendar.TimeZone)
+	require.Len(t, calendar.Events, 3)
+	assert.Equal(t, fmt.Sprintf("task-%s@whodidthechores", task.ID), calendar.Events[0].UID)
+	assert.Equal(t, "Laundry (Alice)", calendar.Events[0].Summary)
+	assert.True(t, calendar.Events[0].End.Equal(now.Add(-90*time.Minute)))
+	assert.Equal(t, "Dishes (Bob)", calendar.Events[1].Summary)
+	today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Due: Dishes (overdue)", calendar.Events[2].Summary)
+	assert.True(t, calendar.Events[2].AllDay)
+	assert.True(t, calendar.Events[2].Start.Equal(today))
+
+	calendar, err = repo.GetCalendar(context.Background(), aliceToken, now, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice chores (Home)", calendar.Name)
+	require.Len(t, calendar.Events, 2)
+	assert.Equal(t, "Laundry", calendar.Events[0].Summary)
+	assert.Equal(t, "Due: Dishes (overdue)", calendar.Events[1].Summary)
+	_, err = repo.GetCalendar(context.Background(), "unknown", now, time.UTC)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.ErrorIs(t, repo.DeleteCalendarFeed(otherCtx, aliceFeed.ID), repository.ErrNotFound)
+	require.NoError(t, repo.DeleteCalendarFeed(ctx, aliceFeed.ID))
+	assert.ErrorIs(t, repo.DeleteCalendarFeed(ctx, aliceFeed.ID), repository.ErrNotFound)
+	_, err = repo.GetCalendar(context.Background(), aliceToken, now, time.UTC)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	// Deleting a user revokes their calendars.
+	require.NoError(t, repo.DeleteUser(ctx, carol.ID))
+	_, err = repo.GetCalendar(context.Background(), carolToken, now, time.UTC)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testWebhooks(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	_, err := repo.CreateWebhook(ctx, repository.ValidatedWebhook{URL: "", Secret: "secret", Events: []string{"task"}})
+	assert.ErrorIs(t, err, repository.ErrInvalidURL)
+	_, err = repo.CreateWebhook(ctx, repository.ValidatedWebhook{URL: "http://example.com/chores", Secret: "", Events: []string{"task"}})
+	assert.ErrorIs(t, err, repository.ErrValidation)
+
+	tasksHook, err := repo.CreateWebhook(ctx, repository.ValidatedWebhook{URL: "http://example.com/tasks", Secret: "secret", Events: []string{"task"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task"}, tasksHook.Events)
+	choresHook, err := repo.CreateWebhook(ctx, repository.ValidatedWebhook{URL: "http://example.com/chores", Secret: "secret", Events: []string{"user"}})
+	require.NoError(t, err)
+	choresHook, err = repo.UpdateWebhook(ctx, choresHook.ID, repository.ValidatedWebhook{URL: "http://example.com/chores", Secret: "other secret", Events: []string{"chore", "user"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chore", "user"}, choresHook.Events)
+	otherCtx := otherHousehold(t, repo)
+	_, err = repo.GetWebhook(otherCtx, tasksHook.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	webhooks, err := repo.ListWebhooks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []postgres.Webhook{tasksHook, choresHook}, webhooks)
+
+	chore := createChore(t, ctx, repo, "Dishes")
+	user := createUser(t, ctx, repo, "Alice")
+	createTask(t, ctx, repo, user, chore, time.Now(), 10)
+
+	deliveries, err := repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	events := map[string]string{}
+	for _, delivery := range deliveries {
+		events[delivery.Event] = delivery.Url
+		assert.Equal(t, int32(0), delivery.Attempts)
+	}
+	assert.Equal(t, map[string]string{
+		repository.EventChoreCreated: "http://example.com/chores",
+		repository.EventUserCreated:  "http://example.com/chores",
+		repository.EventTaskCreated:  "http://example.com/tasks",
+	}, events)
+	claimed, err := repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "claimed deliveries are leased")
+
+	delivery := deliveries[0]
+	require.NoError(t, repo.RecordWebhookDelivery(ctx, delivery.ID, repository.DeliveryAttempt{
+		Status:        repository.DeliveryPending,
+		StatusCode:    500,
+		Error:         "server error",
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}))
+	claimed, err = repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, delivery.ID, claimed[0].ID)
+	assert.Equal(t, int32(1), claimed[0].Attempts)
+	require.NoError(t, repo.RecordWebhookDelivery(ctx, delivery.ID, repository.DeliveryAttempt{
+		Status:        repository.DeliverySucceeded,
+		StatusCode:    200,
+		NextAttemptAt: time.Now(),
+	}))
+
+	var webhookID int32
+	if events[delivery.Event] == tasksHook.Url {
+		webhookID = tasksHook.ID
+	} else {
+		webhookID = choresHook.ID
+	}
+	history, err := repo.ListWebhookDeliveries(ctx, webhookID, 10)
+	require.NoError(t, err)
+	var recorded postgres.WebhookDelivery
+	for _, d := range history {
+		if d.ID == delivery.ID {
+			recorded = d
+		}
+	}
+	assert.Equal(t, repository.DeliverySucceeded, recorded.Status)
+	assert.Equal(t, int32(2), recorded.Attempts)
+	assert.Equal(t, int32(200), recorded.LastStatusCode)
+	assert.True(t, recorded.DeliveredAt.Valid)
+	history, err = repo.ListWebhookDeliveries(ctx, choresHook.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// A retried delivery is due again with its attempts reset, but not for
+	// another household.
+	require.NoError(t, repo.RecordWebhookDelivery(ctx, delivery.ID, repository.DeliveryAttempt{
+		Status:        repository.DeliveryFailed,
+		StatusCode:    500,
+		Error:         "server error",
+		NextAttemptAt: time.Now(),
+	}))
+	assert.ErrorIs(t, repo.RetryWebhookDelivery(otherCtx, webhookID, delivery.ID), repository.ErrNotFound)
+	otherHook := tasksHook.ID
+	if webhookID == otherHook {
+		otherHook = choresHook.ID
+	}
+	assert.ErrorIs(t, repo.RetryWebhookDelivery(ctx, otherHook, delivery.ID), repository.ErrNotFound)
+	assert.ErrorIs(t, repo.RetryWebhookDelivery(ctx, webhookID, uuid.New()), repository.ErrNotFound)
+	claimed, err = repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	require.NoError(t, repo.RetryWebhookDelivery(ctx, webhookID, delivery.ID))
+	history, err = repo.ListWebhookDeliveries(ctx, webhookID, 10)
+	require.NoError(t, err)
+	for _, d := range history {
+		if d.ID == delivery.ID {
+			assert.Equal(t, int32(0), d.Attempts)
+			assert.Empty(t, d.LastError)
+		}
+	}
+	claimed, err = repo.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, int32(0), claimed[0].Attempts)
+
+	require.NoError(t, repo.DeleteWebhook(ctx, choresHook.ID))
+	_, err = repo.GetWebhook(ctx, choresHook.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	history, err = store.ListWebhookDeliveries(ctx, postgres.ListWebhookDeliveriesParams{WebhookID: choresHook.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, history, "the deliveries of a deleted webhook are deleted")
+}
+
+func testTransactions(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	householdID, err := repository.HouseholdFromContext(ctx)
+	require.NoError(t, err)
+	errRollback := errors.New("rollback")
+	err = store.InTx(ctx, func(q postgres.Querier) error {
+		if _, err := q.CreateUser(ctx, postgres.CreateUserParams{HouseholdID: householdID, Name: "Alice", Share: 1}); err != nil {
+			return err
+		}
+		users, err := q.ListUsers(ctx, householdID)
+		if err != nil {
+			return err
+		}
+		assert.Len(t, users, 1, "a transaction sees its own changes")
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	users, err := repo.ListUsers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, users, "a failed transaction is rolled back")
+
+	// A failed statement fails the whole creation, like creating a chore
+	// with a duplicate name fails its webhook event.
+	_, err = repo.CreateChore(ctx, postgres.CreateChoreParams{Name: "Dishes", ScheduleKind: "none"})
+	require.NoError(t, err)
+	err = store.InTx(ctx, func(q postgres.Querier) error {
+		_, err := q.CreateUser(ctx, postgres.CreateUserParams{HouseholdID: householdID, Name: "Bob", Share: 1})
+		return err
+	})
+	require.NoError(t, err)
+	users, err = repo.ListUsers(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 1, "a successful transaction is committed")
+
+	err = store.InSnapshot(ctx, func(q postgres.Querier) error {
+		chores, err := q.ListChores(ctx, householdID)
+		assert.Len(t, chores, 1)
+		return err
+	})
+	assert.NoError(t, err)
+}
+
+// receiveChange returns the next change, failing the test when none arrives.
+func receiveChange(t *testing.T, changes <-chan repository.Change) repository.Change {
+	t.Helper()
+	select {
+	case change := <-changes:
+		return change
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no change received")
+		return repository.Change{}
+	}
+}
+
+func testChanges(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	householdID, err := repository.HouseholdFromContext(ctx)
+	require.NoError(t, err)
+	otherCtx := otherHousehold(t, repo)
+	listenCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listening := make(chan struct{})
+	go func() {
+		repo.ListenChanges(listenCtx)
+		close(listening)
+	}()
+	changes, err := repo.SubscribeChanges(ctx)
+	require.NoError(t, err)
+	otherChanges, err := repo.SubscribeChanges(otherCtx)
+	require.NoError(t, err)
+
+	// The listener starts in the background: users are created until it
+	// receives their changes, then a chore marks the end of them.
+	received := false
+	for attempt := 0; attempt < 100 && !received; attempt++ {
+		createUser(t, ctx, repo, uuid.NewString())
+		select {
+		case change := <-changes:
+			assert.Equal(t, repository.Change{HouseholdID: householdID, Event: repository.EventUserCreated}, change)
+			received = true
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	require.True(t, received, "no change received")
+	chore := createChore(t, ctx, repo, "Dishes")
+	for change := receiveChange(t, changes); change.Event != repository.EventChoreCreated; change = receiveChange(t, changes) {
+		assert.Equal(t, repository.EventUserCreated, change.Event)
+	}
+
+	errRollback := errors.New("rollback")
+	err = store.InTx(ctx, func(q postgres.Querier) error {
+		if err := q.NotifyChange(ctx, fmt.Sprintf(`{"household_id":%d,"event":"task.deleted"}`, householdID)); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	user := createUser(t, ctx, repo, "Alice")
+	assert.Equal(t, repository.EventUserCreated, receiveChange(t, changes).Event, "the changes of a failed transaction aren't notified")
+	createTask(t, ctx, repo, user, chore, time.Now(), 10)
+	change := receiveChange(t, changes)
+	assert.Equal(t, repository.EventTaskCreated, change.Event)
+	assert.Equal(t, "task", change.Resource())
+	_, err = repo.StartTimer(ctx, user.ID, chore.ID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, repository.EventTimerUpdated, receiveChange(t, changes).Event)
+
+	createUser(t, otherCtx, repo, "Carol")
+	otherHouseholdID, err := repository.HouseholdFromContext(otherCtx)
+	require.NoError(t, err)
+	assert.Equal(t, repository.Change{HouseholdID: otherHouseholdID, Event: repository.EventUserCreated}, receiveChange(t, otherChanges), "only the changes of the household are received")
+
+	cancel()
+	<-listening
+	for range changes {
+	}
+	closed, err := repo.SubscribeChanges(ctx)
+	require.NoError(t, err)
+	_, ok := <-closed
+	assert.False(t, ok, "the subscriptions end with the listener")
+}
+
+func testBackups(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	createTask(t, ctx, repo, alice, dishes, time.Now().Add(-time.Hour), 10)
+	otherCtx := otherHousehold(t, repo)
+	createUser(t, otherCtx, repo, "Carol")
+
+	backup, err := repo.CreateBackup(ctx)
+	require.NoError(t, err)
+	require.Len(t, backup.Households, 2)
+	assert.Len(t, backup.Households[0].Tasks, 1)
+	assert.Len(t, backup.Households[1].Users, 1)
+
+	_, err = repo.RestoreBackup(ctx, backup, false)
+	assert.ErrorIs(t, err, repository.ErrNotEmpty)
+
+	result, err := repo.RestoreBackup(ctx, backup, true)
+	require.NoError(t, err)
+	assert.Equal(t, repository.RestoreResult{Households: 2, Chores: 1, Users: 2, Tasks: 1}, result)
+	households, err := repo.ListHouseholds(ctx)
+	require.NoError(t, err)
+	require.Len(t, households, 4)
+	remappedCtx := repository.WithHousehold(context.Background(), households[2].ID)
+	tasks, err := repo.ListUsersTasks(remappedCtx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Alice", tasks[0].User.Name)
+	assert.Equal(t, "Dishes", tasks[0].Chore.Name)
+	assert.Equal(t, int64(10), tasks[0].Task.Points)
+	assert.NotEqual(t, alice.ID, tasks[0].User.ID)
+}
+
+func testConcurrentTimerStops(t *testing.T, ctx context.Context, repo *repository.Repository, store repository.Store) {
+	alice := createUser(t, ctx, repo, "Alice")
+	dishes := createChore(t, ctx, repo, "Dishes")
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	timer, err := repo.StartTimer(ctx, alice.ID, dishes.ID, start)
+	require.NoError(t, err)
+
+	const devices = 5
+	errs := make([]error, devices)
+	var wg sync.WaitGroup
+	for i := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.StopTimer(ctx, timer.ID, start.Add(20*time.Minute))
+		}()
+	}
+	wg.Wait()
+
+	stopped := 0
+	for _, err := range errs {
+		if err == nil {
+			stopped++
+			continue
+		}
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	}
+	assert.Equal(t, 1, stopped, "only one device stops the timer")
+	tasks, err := repo.GetUserTasks(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1, "the chore is logged once")
+}
@@ -2,18 +2,22 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
 )
 
-func taskPgError(err error) error {
+func taskPgError(ctx context.Context, err error) error {
 	var pgErr *pgconn.PgError
 	if !errors.As(err, &pgErr) {
 		return nil
@@ -23,8 +27,12 @@ func taskPgError(err error) error {
 		return errors.New("task already exists")
 	case "tasks_name_check":
 		return errors.New("invalid task name")
+	case "tasks_user_id_fkey":
+		return fmt.Errorf("%w: task user doesn't exist", ErrNotFound)
+	case "tasks_chore_id_fkey":
+		return fmt.Errorf("%w: task chore doesn't exist", ErrNotFound)
 	}
-	slog.Error(fmt.Sprintf("uncaught task pg error: %v", pgErr.Code))
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught task pg error: %v", pgErr.Code))
 	return err
 }
 
@@ -46,18 +54,43 @@ type TaskParamsError struct {
 	Description string
 }
 
+// First returns the first error of the fields, in the order of the form, or
+// "" when there is none.
+func (e TaskParamsError) First() string {
+	for _, fieldError := range []string{e.UserID, e.ChoreID, e.StartedAt, e.DurationMn, e.Description} {
+		if fieldError != "" {
+			return fieldError
+		}
+	}
+	return ""
+}
+
+// ValidateTask checks the fields of a task. New tasks can't be done on an
+// archived chore or by an archived user, but an edited task can keep the ones
+// it has.
 func (r *Repository) ValidateTask(ctx context.Context, taskParams *TaskParams, timezone time.Location) (postgres.CreateTaskParams, error) {
+	var current postgres.Task
+	if taskParams.ID != (uuid.UUID{}) {
+		task, err := r.GetTask(ctx, taskParams.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return postgres.CreateTaskParams{}, err
+		}
+		current = task
+	}
 	isErr := false
 	choreId, err := strconv.Atoi(taskParams.ChoreID)
 	if err != nil {
 		isErr = true
 		taskParams.Errors.ChoreID = "Please select an existing chore"
-	} else if err = r.ValidateTaskChoreId(ctx, choreId); err != nil {
+	} else if err = r.ValidateTaskChoreId(ctx, choreId); err != nil && !(errors.Is(err, ErrArchived) && current.ChoreID == int32(choreId)) {
+		isErr = true
 		switch {
 		case errors.Is(err, ErrNotFound):
 			taskParams.Errors.ChoreID = "Chore not found"
+		case errors.Is(err, ErrArchived):
+			taskParams.Errors.ChoreID = "This chore is archived, please restore it first"
 		default:
-			slog.Error(fmt.Sprintf("unable to validate a chore id: %v", err))
+			slog.ErrorContext(ctx, fmt.Sprintf("unable to validate a chore id: %v", err))
 			taskParams.Errors.ChoreID = "Unable to validate this chore, please try again"
 		}
 	}
@@ -65,44 +98,58 @@ func (r *Repository) ValidateTask(ctx context.Context, taskParams *TaskParams, t
 	if err != nil {
 		isErr = true
 		taskParams.Errors.UserID = "Please select an existing user"
-	} else if err = r.ValidateTaskUserId(ctx, userId); err != nil {
+	} else if err = r.ValidateTaskUserId(ctx, userId); err != nil && !(errors.Is(err, ErrArchived) && current.UserID == int32(userId)) {
+		isErr = true
 		switch {
 		case errors.Is(err, ErrNotFound):
 			taskParams.Errors.UserID = "User not found"
+		case errors.Is(err, ErrArchived):
+			taskParams.Errors.UserID = "This user is archived, please restore them first"
 		default:
-			slog.Error(fmt.Sprintf("unable to validate a task id: %v", err))
+			slog.ErrorContext(ctx, fmt.Sprintf("unable to validate a task id: %v", err))
 			taskParams.Errors.UserID = "Unable to validate this task, please try again"
 		}
 	}
-	duration, err := strconv.Atoi(taskParams.DurationMn)
-	if err != nil {
+	duration, durationErr := r.parseTaskDuration(ctx, taskParams.DurationMn)
+	if durationErr != "" {
 		isErr = true
-		taskParams.Errors.DurationMn = "Please enter a number"
-	} else if err = r.ValidateTaskDuration(duration); err != nil {
-		switch {
-		case errors.Is(err, ErrTooSmall):
-			taskParams.Errors.DurationMn = "Duration can't be negative"
-		case errors.Is(err, ErrTooBig):
-			taskParams.Errors.DurationMn = "Duration too big, please select a smaller number"
-		default:
-			slog.Error(fmt.Sprintf("Unable to validate a task duration: %v", err))
-			taskParams.Errors.DurationMn = "Unable to validate this duration, please try again"
-		}
+		taskParams.Errors.DurationMn = durationErr
 	}
 
 	startedAt, err := time.ParseInLocation("2006-01-02T15:04", taskParams.StartedAt, &timezone)
 	if err != nil {
 		isErr = true
-		slog.Warn(fmt.Sprintf("Unable to parse started time: %v", err))
+		slog.WarnContext(ctx, fmt.Sprintf("Unable to parse started time: %v", err))
 		taskParams.Errors.StartedAt = "Please enter a valid date"
 	}
 	if isErr {
 		return postgres.CreateTaskParams{}, ErrValidation
 	}
-	return postgres.CreateTaskParams{ChoreID: int32(choreId), UserID: int32(userId), Description: taskParams.Description, DurationMn: int32(duration), StartedAt: startedAt}, nil
+	return postgres.CreateTaskParams{ChoreID: int32(choreId), UserID: int32(userId), Description: taskParams.Description, DurationMn: duration, StartedAt: startedAt}, nil
 
 }
 
+// parseTaskDuration parses a task duration in minutes and returns the error to
+// show when it is invalid.
+func (r *Repository) parseTaskDuration(ctx context.Context, durationMn string) (int32, string) {
+	duration, err := strconv.Atoi(durationMn)
+	if err != nil {
+		return 0, "Please enter a number"
+	}
+	if err = r.ValidateTaskDuration(duration); err != nil {
+		switch {
+		case errors.Is(err, ErrTooSmall):
+			return 0, "Duration can't be negative"
+		case errors.Is(err, ErrTooBig):
+			return 0, "Duration too big, please select a smaller number"
+		default:
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a task duration: %v", err))
+			return 0, "Unable to validate this duration, please try again"
+		}
+	}
+	return int32(duration), ""
+}
+
 func (r *Repository) ValidateTaskChoreId(ctx context.Context, choreId int) error {
 	if choreId < 0 {
 		return ErrNotFound
@@ -117,6 +164,9 @@ func (r *Repository) ValidateTaskChoreId(ctx context.Context, choreId int) error
 	if chore == (postgres.Chore{}) {
 		return ErrNotFound
 	}
+	if chore.Archived {
+		return ErrArchived
+	}
 	return nil
 }
 
@@ -134,6 +184,9 @@ func (r *Repository) ValidateTaskUserId(ctx context.Context, userId int) error {
 	if user == (postgres.User{}) {
 		return ErrNotFound
 	}
+	if user.Archived {
+		return ErrArchived
+	}
 	return nil
 }
 
@@ -147,10 +200,41 @@ func (r *Repository) ValidateTaskDuration(default_duration int) error {
 	return nil
 }
 
+// taskPoints returns the points of a task: its duration weighted by the
+// difficulty of its chore. A missing chore is left to the foreign key of the
+// task to report.
+func taskPoints(ctx context.Context, q postgres.Querier, householdID int32, choreID int32, durationMn int32) (int64, error) {
+	chore, err := q.GetChore(ctx, postgres.GetChoreParams{HouseholdID: householdID, ID: choreID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(durationMn) * int64(chore.Difficulty), nil
+}
+
 func (r *Repository) CreateTask(ctx context.Context, params postgres.CreateTaskParams) (postgres.Task, error) {
-	newtask, err := r.q.CreateTask(ctx, params)
+	householdID, err := HouseholdFromContext(ctx)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		return postgres.Task{}, err
+	}
+	params.HouseholdID = householdID
+	var newtask postgres.Task
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		params.Points, err = taskPoints(ctx, q, householdID, params.ChoreID, params.DurationMn)
+		if err != nil {
+			return err
+		}
+		newtask, err = q.CreateTask(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventTaskCreated, Task(newtask))
+	})
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return postgres.Task{}, sqlErr
 		}
 		return postgres.Task{}, err
@@ -160,9 +244,13 @@ func (r *Repository) CreateTask(ctx context.Context, params postgres.CreateTaskP
 }
 
 func (r *Repository) ListTasks(ctx context.Context) ([]postgres.Task, error) {
-	tasks, err := r.q.ListTasks(ctx)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.q.ListTasks(ctx, householdID)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return nil, sqlErr
 		}
 		return nil, err
@@ -171,9 +259,16 @@ func (r *Repository) ListTasks(ctx context.Context) ([]postgres.Task, error) {
 }
 
 func (r *Repository) GetTask(ctx context.Context, id uuid.UUID) (postgres.Task, error) {
-	task, err := r.q.GetTask(ctx, id)
+	householdID, err := HouseholdFromContext(ctx)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		return postgres.Task{}, err
+	}
+	task, err := r.q.GetTask(ctx, postgres.GetTaskParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Task{}, ErrNotFound
+		}
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return postgres.Task{}, sqlErr
 		}
 		return postgres.Task{}, err
@@ -182,7 +277,12 @@ func (r *Repository) GetTask(ctx context.Context, id uuid.UUID) (postgres.Task,
 }
 
 func (r *Repository) UpdateTask(ctx context.Context, id uuid.UUID, taskParams postgres.CreateTaskParams) (postgres.Task, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Task{}, err
+	}
 	params := postgres.UpdateTaskParams{
+		HouseholdID: householdID,
 		ID:          id,
 		UserID:      taskParams.UserID,
 		ChoreID:     taskParams.ChoreID,
@@ -190,9 +290,24 @@ func (r *Repository) UpdateTask(ctx context.Context, id uuid.UUID, taskParams po
 		DurationMn:  taskParams.DurationMn,
 		Description: taskParams.Description,
 	}
-	task, err := r.q.UpdateTask(ctx, params)
+	var task postgres.Task
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		params.Points, err = taskPoints(ctx, q, householdID, params.ChoreID, params.DurationMn)
+		if err != nil {
+			return err
+		}
+		task, err = q.UpdateTask(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventTaskUpdated, Task(task))
+	})
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Task{}, ErrNotFound
+		}
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return postgres.Task{}, sqlErr
 		}
 		return postgres.Task{}, err
@@ -201,9 +316,13 @@ func (r *Repository) UpdateTask(ctx context.Context, id uuid.UUID, taskParams po
 }
 
 func (r *Repository) GetChoreTasks(ctx context.Context, choreID int32) ([]postgres.GetChoreTasksRow, error) {
-	tasks, err := r.q.GetChoreTasks(ctx, choreID)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.q.GetChoreTasks(ctx, postgres.GetChoreTasksParams{HouseholdID: householdID, ChoreID: choreID})
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return nil, sqlErr
 		}
 		return nil, err
@@ -212,9 +331,30 @@ func (r *Repository) GetChoreTasks(ctx context.Context, choreID int32) ([]postgr
 }
 
 func (r *Repository) GetUserTasks(ctx context.Context, userID int32) ([]postgres.GetUserTasksRow, error) {
-	tasks, err := r.q.GetUserTasks(ctx, userID)
+	householdID, err := HouseholdFromContext(ctx)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		return nil, err
+	}
+	tasks, err := r.q.GetUserTasks(ctx, postgres.GetUserTasksParams{HouseholdID: householdID, ID: userID})
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListUsersTasksBetween returns the tasks of the user, or of every user when
+// userID is 0, started from 'from' to 'to' included, the latest first.
+func (r *Repository) ListUsersTasksBetween(ctx context.Context, userID int32, from time.Time, to time.Time) ([]postgres.ListUsersTasksBetweenRow, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.q.ListUsersTasksBetween(ctx, postgres.ListUsersTasksBetweenParams{HouseholdID: householdID, UserID: userID, NotBefore: from, NotAfter: to})
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return nil, sqlErr
 		}
 		return nil, err
@@ -223,9 +363,13 @@ func (r *Repository) GetUserTasks(ctx context.Context, userID int32) ([]postgres
 }
 
 func (r *Repository) ListUsersTasks(ctx context.Context) ([]postgres.ListUsersTasksRow, error) {
-	tasks, err := r.q.ListUsersTasks(ctx)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.q.ListUsersTasks(ctx, householdID)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return nil, sqlErr
 		}
 		return nil, err
@@ -234,12 +378,143 @@ func (r *Repository) ListUsersTasks(ctx context.Context) ([]postgres.ListUsersTa
 }
 
 func (r *Repository) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	err := r.q.DeleteTask(ctx, id)
+	householdID, err := HouseholdFromContext(ctx)
 	if err != nil {
-		if sqlErr := taskPgError(err); sqlErr != nil {
+		return err
+	}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		deleted, err := q.DeleteTask(ctx, postgres.DeleteTaskParams{HouseholdID: householdID, ID: id})
+		if err != nil || deleted == 0 {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventTaskDeleted, deletedResource{ID: id})
+	})
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
 			return sqlErr
 		}
 		return err
 	}
 	return nil
 }
+
+// Columns the tasks list can be sorted on, the start time being the default.
+const (
+	TaskSortStartedAt = "started_at"
+	TaskSortDuration  = "duration"
+	TaskSortPoints    = "points"
+)
+
+var TaskSorts = []string{TaskSortStartedAt, TaskSortDuration, TaskSortPoints}
+
+// TasksPageSize is the number of tasks of a page of the tasks list.
+const TasksPageSize = 50
+
+// TaskFilter selects and sorts the tasks of the tasks list.
+type TaskFilter struct {
+	// UserID and ChoreID restrict the tasks to a user and a chore when not 0.
+	UserID  int32
+	ChoreID int32
+	// From and To restrict the tasks to those started in between when not
+	// zero, To excluded.
+	From time.Time
+	To   time.Time
+	// Search restricts the tasks to those whose description contains it,
+	// ignoring case.
+	Search string
+	// Sort is one of TaskSorts. Tasks are sorted from the greatest value to
+	// the smallest unless Ascending is set.
+	Sort      string
+	Ascending bool
+}
+
+// TasksPage is a page of the tasks list.
+type TasksPage struct {
+	Tasks []postgres.SearchTasksRow
+	// Next is the cursor of the next page, empty on the last page.
+	Next string
+}
+
+// tasksCursor holds the values of the sort columns of the last task of a page.
+type tasksCursor struct {
+	Key       int64     `json:"k"`
+	StartedAt time.Time `json:"s"`
+	ID        uuid.UUID `json:"i"`
+}
+
+func taskSortKey(task postgres.Task, sort string) int64 {
+	switch sort {
+	case TaskSortDuration:
+		return int64(task.DurationMn)
+	case TaskSortPoints:
+		return task.Points
+	}
+	return 0
+}
+
+// SearchTasks returns the page of at most size tasks matching the filter that
+// follows the cursor, or the first page when cursor is empty. Pages are
+// selected by the values of the sort columns rather than skipping the tasks
+// of the previous pages, so they stay cheap however far the list goes.
+func (r *Repository) SearchTasks(ctx context.Context, filter TaskFilter, cursor string, size int32) (TasksPage, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return TasksPage{}, err
+	}
+	if !slices.Contains(TaskSorts, filter.Sort) {
+		filter.Sort = TaskSortStartedAt
+	}
+	if size < 1 {
+		size = TasksPageSize
+	}
+	params := postgres.SearchTasksParams{
+		HouseholdID: householdID,
+		UserID:      filter.UserID,
+		ChoreID:     filter.ChoreID,
+		NotBefore:   filter.From,
+		NotAfter:    filter.To,
+		Search:      filter.Search,
+		Descending:  !filter.Ascending,
+		Sort:        filter.Sort,
+		// One more task tells whether there is a next page.
+		MaxTasks: size + 1,
+	}
+	if params.NotBefore.IsZero() {
+		params.NotBefore = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if params.NotAfter.IsZero() {
+		params.NotAfter = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if cursor != "" {
+		var after tasksCursor
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			err = json.Unmarshal(raw, &after)
+		}
+		if err != nil {
+			return TasksPage{}, fmt.Errorf("%w: invalid tasks cursor", ErrValidation)
+		}
+		params.After = true
+		params.AfterKey = after.Key
+		params.AfterStartedAt = after.StartedAt
+		params.AfterID = after.ID
+	}
+	tasks, err := r.q.SearchTasks(ctx, params)
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return TasksPage{}, sqlErr
+		}
+		return TasksPage{}, err
+	}
+	page := TasksPage{Tasks: tasks}
+	if len(tasks) > int(size) {
+		page.Tasks = tasks[:size]
+		last := page.Tasks[size-1].Task
+		raw, err := json.Marshal(tasksCursor{Key: taskSortKey(last, filter.Sort), StartedAt: last.StartedAt, ID: last.ID})
+		if err != nil {
+			return TasksPage{}, fmt.Errorf("unable to encode tasks cursor: %w", err)
+		}
+		page.Next = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	return page, nil
+}
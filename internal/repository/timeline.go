@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mqufflc/whodidthechores/internal/database"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// TimelineBucket is the period a timeline adds up the tasks by.
+type TimelineBucket string
+
+const (
+	BucketDay   TimelineBucket = "day"
+	BucketWeek  TimelineBucket = "week"
+	BucketMonth TimelineBucket = "month"
+)
+
+// TimelineBuckets lists the periods a timeline can use, the shortest first.
+var TimelineBuckets = []TimelineBucket{BucketDay, BucketWeek, BucketMonth}
+
+// MaxTimelinePeriods bounds the periods of a timeline, so that a long range
+// doesn't lay out and render a point per day since year 1.
+const MaxTimelinePeriods = 1000
+
+// ParseTimelineBucket returns the bucket with the given name, the week when it
+// is empty.
+func ParseTimelineBucket(name string) (TimelineBucket, error) {
+	if name == "" {
+		return BucketWeek, nil
+	}
+	bucket := TimelineBucket(name)
+	if !slices.Contains(TimelineBuckets, bucket) {
+		return "", fmt.Errorf("%w: unknown timeline bucket %q", ErrValidation, name)
+	}
+	return bucket, nil
+}
+
+// next returns the start of the period following the one starting at start.
+func (b TimelineBucket) next(start time.Time) time.Time {
+	switch b {
+	case BucketDay:
+		return start.AddDate(0, 0, 1)
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// maxPeriods returns an upper bound of the number of periods between start
+// and end.
+func (b TimelineBucket) maxPeriods(start time.Time, end time.Time) int {
+	days := int(end.Sub(start).Hours()/24) + 1
+	switch b {
+	case BucketDay:
+		return days + 1
+	case BucketWeek:
+		return days/7 + 2
+	default:
+		return days/28 + 2
+	}
+}
+
+// fitTimeline returns the start and bucket of a timeline between start and end
+// with at most MaxTimelinePeriods periods: the bucket is made longer first,
+// then the start is moved closer to the end.
+func fitTimeline(start time.Time, end time.Time, bucket TimelineBucket) (time.Time, TimelineBucket) {
+	for _, longer := range TimelineBuckets[slices.Index(TimelineBuckets, bucket):] {
+		bucket = longer
+		if bucket.maxPeriods(start, end) <= MaxTimelinePeriods {
+			return start, bucket
+		}
+	}
+	return end.AddDate(0, -(MaxTimelinePeriods - 2), 0), bucket
+}
+
+// PeriodReport is the sum of the tasks of a user started during a period.
+type PeriodReport struct {
+	User    User      `json:"user"`
+	Period  time.Time `json:"period"`
+	Minutes int64     `json:"minutes"`
+	Tasks   int64     `json:"tasks"`
+	Points  int64     `json:"points"`
+}
+
+// Sum returns the value of the metric for the tasks of the user and period.
+func (p PeriodReport) Sum(metric ReportMetric) int64 {
+	return TaskReport{Minutes: p.Minutes, Tasks: p.Tasks, Points: p.Points}.Sum(metric)
+}
+
+// Timeline is the metric of each user over consecutive periods. Series holds
+// one value per period for each user.
+type Timeline struct {
+	Metric  ReportMetric       `json:"metric"`
+	Bucket  TimelineBucket     `json:"bucket"`
+	Periods []time.Time        `json:"periods"`
+	Users   []string           `json:"users"`
+	Series  map[string][]int64 `json:"series"`
+}
+
+// GenerateTimeline lays out the metric of the reports over every period
+// between start and end, the periods without tasks included. The bucket or
+// the start are changed when there would be more than MaxTimelinePeriods.
+func GenerateTimeline(reports []PeriodReport, start time.Time, end time.Time, bucket TimelineBucket, metric ReportMetric, timezone *time.Location) (Timeline, error) {
+	start, bucket = fitTimeline(start, end, bucket)
+	timeline := Timeline{Metric: metric, Bucket: bucket, Periods: []time.Time{}, Users: []string{}, Series: map[string][]int64{}}
+	period, err := database.DateTrunc(string(bucket), start, timezone)
+	if err != nil {
+		return Timeline{}, err
+	}
+	indexes := map[int64]int{}
+	for ; period.Before(end); period = bucket.next(period) {
+		indexes[period.Unix()] = len(timeline.Periods)
+		timeline.Periods = append(timeline.Periods, period)
+	}
+	for _, report := range reports {
+		index, ok := indexes[report.Period.Unix()]
+		if !ok {
+			continue
+		}
+		series, ok := timeline.Series[report.User.Name]
+		if !ok {
+			series = make([]int64, len(timeline.Periods))
+			timeline.Users = append(timeline.Users, report.User.Name)
+		}
+		series[index] += report.Sum(metric)
+		timeline.Series[report.User.Name] = series
+	}
+	slices.SortFunc(timeline.Users, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return timeline, nil
+}
+
+// GetTimeline adds up the tasks started between start and end by user and by
+// period, the periods starting at midnight in the timezone. The bucket of the
+// returned timeline is longer than the one asked for when the range has too
+// many periods.
+func (r *Repository) GetTimeline(ctx context.Context, start time.Time, end time.Time, bucket TimelineBucket, metric ReportMetric, timezone *time.Location) (Timeline, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return Timeline{}, err
+	}
+	start, bucket = fitTimeline(start, end, bucket)
+	rows, err := r.q.TasksTimeline(ctx, postgres.TasksTimelineParams{
+		Unit:        string(bucket),
+		TimeZone:    timezone.String(),
+		HouseholdID: householdID,
+		NotBefore:   start,
+		NotAfter:    end,
+	})
+	if err != nil {
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return Timeline{}, sqlErr
+		}
+		return Timeline{}, err
+	}
+	reports := make([]PeriodReport, len(rows))
+	for index, row := range rows {
+		reports[index] = PeriodReport{
+			User:    User(row.User),
+			Period:  row.Period,
+			Minutes: row.Minutes,
+			Tasks:   row.TasksCount,
+			Points:  row.Points,
+		}
+	}
+	return GenerateTimeline(reports, start, end, bucket, metric, timezone)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTimelineLimit(t *testing.T) {
+	end := time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)
+
+	timeline, err := GenerateTimeline(nil, end.AddDate(0, 0, -90), end, BucketDay, MetricMinutes, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, BucketDay, timeline.Bucket)
+	assert.Len(t, timeline.Periods, 91)
+
+	// Days over ten years are too many, weeks are not.
+	timeline, err = GenerateTimeline(nil, end.AddDate(-10, 0, 0), end, BucketDay, MetricMinutes, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, BucketWeek, timeline.Bucket)
+	assert.LessOrEqual(t, len(timeline.Periods), MaxTimelinePeriods)
+
+	// Since year 1, even months are too many: the timeline starts later.
+	timeline, err = GenerateTimeline(nil, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), end, BucketDay, MetricMinutes, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, BucketMonth, timeline.Bucket)
+	assert.LessOrEqual(t, len(timeline.Periods), MaxTimelinePeriods)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), timeline.Periods[len(timeline.Periods)-1])
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+func timerPgError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case "timers_user_id_key":
+		return fmt.Errorf("%w: the user already has a timer", ErrTimerRunning)
+	case "timers_user_id_fkey":
+		return fmt.Errorf("%w: timer user doesn't exist", ErrNotFound)
+	case "timers_chore_id_fkey":
+		return fmt.Errorf("%w: timer chore doesn't exist", ErrNotFound)
+	}
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught timer pg error: %v", pgErr.Code))
+	return err
+}
+
+// TimerElapsed returns the time the timer measured up to now, paused periods
+// excluded.
+func TimerElapsed(timer postgres.Timer, now time.Time) time.Duration {
+	elapsed := time.Duration(timer.ElapsedSeconds) * time.Second
+	if timer.ResumedAt.Valid && now.After(timer.ResumedAt.Time) {
+		elapsed += now.Sub(timer.ResumedAt.Time)
+	}
+	return elapsed
+}
+
+func (r *Repository) ListTimers(ctx context.Context) ([]postgres.ListTimersRow, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timers, err := r.q.ListTimers(ctx, householdID)
+	if err != nil {
+		if sqlErr := timerPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return timers, nil
+}
+
+// StartTimer starts measuring the user doing the chore from now. A user has a
+// single timer at a time, and archived users and chores can't have one.
+func (r *Repository) StartTimer(ctx context.Context, userID int32, choreID int32, now time.Time) (postgres.Timer, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Timer{}, err
+	}
+	var timer postgres.Timer
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		chore, err := q.GetChore(ctx, postgres.GetChoreParams{HouseholdID: householdID, ID: choreID})
+		if err != nil {
+			return err
+		}
+		user, err := q.GetUser(ctx, postgres.GetUserParams{HouseholdID: householdID, ID: userID})
+		if err != nil {
+			return err
+		}
+		if chore.Archived || user.Archived {
+			return ErrArchived
+		}
+		timer, err = q.CreateTimer(ctx, postgres.CreateTimerParams{HouseholdID: householdID, UserID: userID, ChoreID: choreID, StartedAt: now})
+		if err != nil {
+			return err
+		}
+		return notifyChange(ctx, q, householdID, EventTimerUpdated)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Timer{}, ErrNotFound
+		}
+		if sqlErr := timerPgError(ctx, err); sqlErr != nil {
+			return postgres.Timer{}, sqlErr
+		}
+		return postgres.Timer{}, err
+	}
+	return timer, nil
+}
+
+// PauseTimer stops measuring time until the timer is resumed. Pausing a paused
+// timer changes nothing.
+func (r *Repository) PauseTimer(ctx context.Context, id int32, now time.Time) (postgres.Timer, error) {
+	return r.updateTimer(ctx, id, func(timer postgres.Timer) postgres.UpdateTimerParams {
+		return postgres.UpdateTimerParams{ElapsedSeconds: int64(TimerElapsed(timer, now) / time.Second)}
+	})
+}
+
+// ResumeTimer measures time again from now. Resuming a running timer changes
+// nothing.
+func (r *Repository) ResumeTimer(ctx context.Context, id int32, now time.Time) (postgres.Timer, error) {
+	return r.updateTimer(ctx, id, func(timer postgres.Timer) postgres.UpdateTimerParams {
+		resumedAt := timer.ResumedAt
+		if !resumedAt.Valid {
+			resumedAt = pgtype.Timestamptz{Time: now, Valid: true}
+		}
+		return postgres.UpdateTimerParams{ResumedAt: resumedAt, ElapsedSeconds: timer.ElapsedSeconds}
+	})
+}
+
+// updateTimer saves the state returned by update for the current one. The
+// timer is locked until the transaction ends, so that two devices can't change
+// it at once.
+func (r *Repository) updateTimer(ctx context.Context, id int32, update func(timer postgres.Timer) postgres.UpdateTimerParams) (postgres.Timer, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Timer{}, err
+	}
+	var timer postgres.Timer
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		current, err := q.GetTimerForUpdate(ctx, postgres.GetTimerForUpdateParams{HouseholdID: householdID, ID: id})
+		if err != nil {
+			return err
+		}
+		params := update(current)
+		params.HouseholdID = householdID
+		params.ID = id
+		timer, err = q.UpdateTimer(ctx, params)
+		if err != nil {
+			return err
+		}
+		return notifyChange(ctx, q, householdID, EventTimerUpdated)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Timer{}, ErrNotFound
+		}
+		if sqlErr := timerPgError(ctx, err); sqlErr != nil {
+			return postgres.Timer{}, sqlErr
+		}
+		return postgres.Timer{}, err
+	}
+	return timer, nil
+}
+
+// StopTimer replaces the timer with a task of its user and chore, started when
+// the timer was and lasting the time it measured, rounded to the minute. When
+// two devices stop the timer at once, only one logs the task and the other gets
+// ErrNotFound.
+func (r *Repository) StopTimer(ctx context.Context, id int32, now time.Time) (postgres.Task, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Task{}, err
+	}
+	var task postgres.Task
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		timer, err := q.GetTimerForUpdate(ctx, postgres.GetTimerForUpdateParams{HouseholdID: householdID, ID: id})
+		if err != nil {
+			return err
+		}
+		deleted, err := q.DeleteTimer(ctx, postgres.DeleteTimerParams{HouseholdID: householdID, ID: id})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrNotFound
+		}
+		if err = notifyChange(ctx, q, householdID, EventTimerUpdated); err != nil {
+			return err
+		}
+		params := postgres.CreateTaskParams{
+			HouseholdID: householdID,
+			UserID:      timer.UserID,
+			ChoreID:     timer.ChoreID,
+			StartedAt:   timer.StartedAt,
+			DurationMn:  int32(TimerElapsed(timer, now).Round(time.Minute) / time.Minute),
+		}
+		params.Points, err = taskPoints(ctx, q, householdID, params.ChoreID, params.DurationMn)
+		if err != nil {
+			return err
+		}
+		task, err = q.CreateTask(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventTaskCreated, Task(task))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Task{}, ErrNotFound
+		}
+		if sqlErr := taskPgError(ctx, err); sqlErr != nil {
+			return postgres.Task{}, sqlErr
+		}
+		return postgres.Task{}, err
+	}
+	return task, nil
+}
+
+// DiscardTimer deletes the timer without logging a task.
+func (r *Repository) DiscardTimer(ctx context.Context, id int32) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		deleted, err := q.DeleteTimer(ctx, postgres.DeleteTimerParams{HouseholdID: householdID, ID: id})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrNotFound
+		}
+		return notifyChange(ctx, q, householdID, EventTimerUpdated)
+	})
+	if err != nil {
+		if sqlErr := timerPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	return nil
+}
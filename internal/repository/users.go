@@ -5,39 +5,64 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
 )
 
-func userPgError(err error) error {
+// The share of a user is the part of the chores expected from them, relative to
+// the shares of the other users. A user without share has the lowest one.
+const (
+	MinShare = 1
+	MaxShare = 100
+)
+
+func userPgError(ctx context.Context, err error) error {
 	var pgErr *pgconn.PgError
 	if !errors.As(err, &pgErr) {
 		return nil
 	}
 	switch pgErr.ConstraintName {
-	case "users_name_key":
+	case "users_household_id_name_key":
 		return ErrDuplicateName
 	case "users_name_check":
 		return ErrInvalidName
+	case "users_share_check":
+		return fmt.Errorf("%w: must be between %d and %d", ErrInvalidShare, MinShare, MaxShare)
 	case "tasks_user_id_fkey":
 		return ErrStillInUse
 	}
-	slog.Error(fmt.Sprintf("uncaught user pg error: %v", pgErr.Code))
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught user pg error: %v", pgErr.Code))
 	return err
 }
 
 type UserParams struct {
-	ID     int32
-	Name   string
-	Errors UserParamsError
+	ID       int32
+	Name     string
+	Share    string
+	Archived bool
+	Errors   UserParamsError
 }
 
 type UserParamsError struct {
-	Name string
+	Name  string
+	Share string
+}
+
+// NewUserParams returns the form parameters of an existing user.
+func NewUserParams(user postgres.User) UserParams {
+	return UserParams{
+		ID:       user.ID,
+		Name:     user.Name,
+		Share:    strconv.FormatInt(int64(user.Share), 10),
+		Archived: user.Archived,
+	}
 }
 
-func (r *Repository) ValidateUser(ctx context.Context, userParams *UserParams) (string, error) {
+func (r *Repository) ValidateUser(ctx context.Context, userParams *UserParams) (postgres.CreateUserParams, error) {
 	isErr := false
 	if err := r.ValidateUserName(ctx, userParams.Name, userParams.ID); err != nil {
 		isErr = true
@@ -47,18 +72,29 @@ func (r *Repository) ValidateUser(ctx context.Context, userParams *UserParams) (
 		case errors.Is(err, ErrDuplicateName):
 			userParams.Errors.Name = "Name already taken, please chose another one"
 		default:
-			slog.Error(fmt.Sprintf("Unable to validate a name: %v", err))
+			slog.ErrorContext(ctx, fmt.Sprintf("Unable to validate a name: %v", err))
 			userParams.Errors.Name = "Unable to validate this name, please try again"
 		}
 	}
+	if userParams.Share == "" {
+		userParams.Share = strconv.Itoa(MinShare)
+	}
+	share, err := strconv.Atoi(userParams.Share)
+	if err != nil {
+		isErr = true
+		userParams.Errors.Share = "Please enter a number"
+	} else if err = r.ValidateUserShare(share); err != nil {
+		isErr = true
+		userParams.Errors.Share = fmt.Sprintf("Please enter a share between %d and %d", MinShare, MaxShare)
+	}
 	if isErr {
-		return "", ErrValidation
+		return postgres.CreateUserParams{}, ErrValidation
 	}
-	return userParams.Name, nil
+	return postgres.CreateUserParams{Name: userParams.Name, Share: int32(share)}, nil
 }
 
 func (r *Repository) ValidateUserName(ctx context.Context, name string, id int32) error {
-	existingUsers, err := r.q.ListUsers(ctx)
+	existingUsers, err := r.ListAllUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get existing users: %w", err)
 	}
@@ -76,10 +112,33 @@ func (r *Repository) ValidateUserName(ctx context.Context, name string, id int32
 	return nil
 }
 
-func (r *Repository) CreateUser(ctx context.Context, name string) (postgres.User, error) {
-	newuser, err := r.q.CreateUser(ctx, name)
+func (r *Repository) ValidateUserShare(share int) error {
+	if share < MinShare || share > MaxShare {
+		return ErrInvalidShare
+	}
+	return nil
+}
+
+func (r *Repository) CreateUser(ctx context.Context, params postgres.CreateUserParams) (postgres.User, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.User{}, err
+	}
+	params.HouseholdID = householdID
+	if params.Share == 0 {
+		params.Share = MinShare
+	}
+	var newuser postgres.User
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		newuser, err = q.CreateUser(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventUserCreated, User(newuser))
+	})
 	if err != nil {
-		if sqlErr := userPgError(err); sqlErr != nil {
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
 			return postgres.User{}, sqlErr
 		}
 		return postgres.User{}, err
@@ -88,10 +147,32 @@ func (r *Repository) CreateUser(ctx context.Context, name string) (postgres.User
 	return newuser, nil
 }
 
+// ListUsers returns the users of the household that aren't archived.
 func (r *Repository) ListUsers(ctx context.Context) ([]postgres.User, error) {
-	users, err := r.q.ListUsers(ctx)
+	users, err := r.ListAllUsers(ctx)
 	if err != nil {
-		if sqlErr := userPgError(err); sqlErr != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(users, func(user postgres.User) bool { return user.Archived }), nil
+}
+
+func (r *Repository) ListArchivedUsers(ctx context.Context) ([]postgres.User, error) {
+	users, err := r.ListAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(users, func(user postgres.User) bool { return !user.Archived }), nil
+}
+
+// ListAllUsers returns the users of the household, archived ones included.
+func (r *Repository) ListAllUsers(ctx context.Context) ([]postgres.User, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := r.q.ListUsers(ctx, householdID)
+	if err != nil {
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
 			return nil, sqlErr
 		}
 		return nil, err
@@ -100,9 +181,16 @@ func (r *Repository) ListUsers(ctx context.Context) ([]postgres.User, error) {
 }
 
 func (r *Repository) GetUser(ctx context.Context, id int32) (postgres.User, error) {
-	user, err := r.q.GetUser(ctx, id)
+	householdID, err := HouseholdFromContext(ctx)
 	if err != nil {
-		if sqlErr := userPgError(err); sqlErr != nil {
+		return postgres.User{}, err
+	}
+	user, err := r.q.GetUser(ctx, postgres.GetUserParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.User{}, ErrNotFound
+		}
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
 			return postgres.User{}, sqlErr
 		}
 		return postgres.User{}, err
@@ -110,14 +198,34 @@ func (r *Repository) GetUser(ctx context.Context, id int32) (postgres.User, erro
 	return user, nil
 }
 
-func (r *Repository) UpdateUser(ctx context.Context, id int32, name string) (postgres.User, error) {
+func (r *Repository) UpdateUser(ctx context.Context, id int32, userParams postgres.CreateUserParams) (postgres.User, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.User{}, err
+	}
+	if userParams.Share == 0 {
+		userParams.Share = MinShare
+	}
 	params := postgres.UpdateUserParams{
-		ID:   id,
-		Name: name,
+		HouseholdID: householdID,
+		ID:          id,
+		Name:        userParams.Name,
+		Share:       userParams.Share,
 	}
-	user, err := r.q.UpdateUser(ctx, params)
+	var user postgres.User
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		user, err = q.UpdateUser(ctx, params)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventUserUpdated, User(user))
+	})
 	if err != nil {
-		if sqlErr := userPgError(err); sqlErr != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.User{}, ErrNotFound
+		}
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
 			return postgres.User{}, sqlErr
 		}
 		return postgres.User{}, err
@@ -126,9 +234,89 @@ func (r *Repository) UpdateUser(ctx context.Context, id int32, name string) (pos
 }
 
 func (r *Repository) DeleteUser(ctx context.Context, id int32) error {
-	err := r.q.DeleteUser(ctx, id)
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		deleted, err := q.DeleteUser(ctx, postgres.DeleteUserParams{HouseholdID: householdID, ID: id})
+		if err != nil || deleted == 0 {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventUserDeleted, deletedResource{ID: id})
+	})
+	if err != nil {
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	return nil
+}
+
+// SetUserArchived archives the user, or restores them. An archived user is
+// hidden from the lists and the forms but keeps their tasks.
+func (r *Repository) SetUserArchived(ctx context.Context, id int32, archived bool) (postgres.User, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.User{}, err
+	}
+	var user postgres.User
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		var err error
+		user, err = q.SetUserArchived(ctx, postgres.SetUserArchivedParams{HouseholdID: householdID, ID: id, Archived: archived})
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventUserUpdated, User(user))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.User{}, ErrNotFound
+		}
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
+			return postgres.User{}, sqlErr
+		}
+		return postgres.User{}, err
+	}
+	return user, nil
+}
+
+// ReassignAndDeleteUser moves the tasks of the user to the user toID, then
+// deletes the user.
+func (r *Repository) ReassignAndDeleteUser(ctx context.Context, id int32, toID int32) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if id == toID {
+		return fmt.Errorf("%w: can't reassign the tasks of a user to themselves", ErrValidation)
+	}
+	err = r.withTx(ctx, func(q postgres.Querier) error {
+		_, err := q.GetUser(ctx, postgres.GetUserParams{HouseholdID: householdID, ID: toID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: user to reassign the tasks to doesn't exist", ErrValidation)
+			}
+			return err
+		}
+		tasks, err := q.ReassignUserTasks(ctx, postgres.ReassignUserTasksParams{ToUserID: toID, HouseholdID: householdID, FromUserID: id})
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err = publishEvent(ctx, q, householdID, EventTaskUpdated, Task(task)); err != nil {
+				return err
+			}
+		}
+		deleted, err := q.DeleteUser(ctx, postgres.DeleteUserParams{HouseholdID: householdID, ID: id})
+		if err != nil || deleted == 0 {
+			return err
+		}
+		return publishEvent(ctx, q, householdID, EventUserDeleted, deletedResource{ID: id})
+	})
 	if err != nil {
-		if sqlErr := userPgError(err); sqlErr != nil {
+		if sqlErr := userPgError(ctx, err); sqlErr != nil {
 			return sqlErr
 		}
 		return err
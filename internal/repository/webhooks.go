@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+// Resources a webhook can subscribe to.
+const (
+	ResourceTask  = "task"
+	ResourceChore = "chore"
+	ResourceUser  = "user"
+)
+
+var WebhookResources = []string{ResourceTask, ResourceChore, ResourceUser}
+
+// Events sent to webhooks, named after the resource they concern.
+const (
+	EventTaskCreated  = "task.created"
+	EventTaskUpdated  = "task.updated"
+	EventTaskDeleted  = "task.deleted"
+	EventChoreCreated = "chore.created"
+	EventChoreUpdated = "chore.updated"
+	EventChoreDeleted = "chore.deleted"
+	EventUserCreated  = "user.created"
+	EventUserUpdated  = "user.updated"
+	EventUserDeleted  = "user.deleted"
+)
+
+// Statuses of a webhook delivery.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+func webhookPgError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case "webhooks_url_check":
+		return fmt.Errorf("%w: invalid webhook url", ErrInvalidURL)
+	case "webhooks_secret_check":
+		return fmt.Errorf("%w: invalid webhook secret", ErrValidation)
+	}
+	slog.ErrorContext(ctx, fmt.Sprintf("uncaught webhook pg error: %v", pgErr))
+	return fmt.Errorf("%w: %w", ErrSQL, err)
+}
+
+// WebhookPayload is the JSON body posted to webhooks.
+type WebhookPayload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+type deletedResource struct {
+	ID any `json:"id"`
+}
+
+// publishEvent queues a delivery of the event to every webhook of the
+// household subscribed to its resource, and notifies the change to the
+// instances of the application. It is given the queries of the transaction of
+// the mutation so that events are only published when the mutation is
+// committed.
+func publishEvent(ctx context.Context, q postgres.Querier, householdID int32, event string, data any) error {
+	payload, err := json.Marshal(WebhookPayload{Event: event, OccurredAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("unable to encode webhook payload: %w", err)
+	}
+	resource, _, _ := strings.Cut(event, ".")
+	err = q.EnqueueWebhookDeliveries(ctx, postgres.EnqueueWebhookDeliveriesParams{
+		Event:       event,
+		Payload:     payload,
+		HouseholdID: householdID,
+		Resource:    resource,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to enqueue webhook deliveries: %w", err)
+	}
+	return notifyChange(ctx, q, householdID, event)
+}
+
+type WebhookParams struct {
+	ID     int32
+	URL    string
+	Secret string
+	Events []string
+	Errors WebhookParamsError
+}
+
+type WebhookParamsError struct {
+	URL    string
+	Events string
+}
+
+type ValidatedWebhook struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+func NewWebhookParams(webhook postgres.Webhook) WebhookParams {
+	return WebhookParams{
+		ID:     webhook.ID,
+		URL:    webhook.Url,
+		Secret: webhook.Secret,
+		Events: webhook.Events,
+	}
+}
+
+// ValidateWebhook checks the webhook form. A secret is generated when none is
+// given.
+func (r *Repository) ValidateWebhook(webhookParams *WebhookParams) (ValidatedWebhook, error) {
+	isErr := false
+	if err := r.ValidateWebhookURL(webhookParams.URL); err != nil {
+		isErr = true
+		webhookParams.Errors.URL = "Please enter an http or https URL"
+	}
+	if err := r.ValidateWebhookEvents(webhookParams.Events); err != nil {
+		isErr = true
+		webhookParams.Errors.Events = "Please select at least one kind of change"
+	}
+	if isErr {
+		return ValidatedWebhook{}, ErrValidation
+	}
+	secret := webhookParams.Secret
+	if secret == "" {
+		buf := make([]byte, 24)
+		if _, err := rand.Read(buf); err != nil {
+			return ValidatedWebhook{}, fmt.Errorf("unable to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+	return ValidatedWebhook{URL: webhookParams.URL, Secret: secret, Events: webhookParams.Events}, nil
+}
+
+func (r *Repository) ValidateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidURL
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+func (r *Repository) ValidateWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return ErrValidation
+	}
+	for _, event := range events {
+		if !slices.Contains(WebhookResources, event) {
+			return ErrValidation
+		}
+	}
+	return nil
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context) ([]postgres.Webhook, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := r.q.ListWebhooks(ctx, householdID)
+	if err != nil {
+		if sqlErr := webhookPgError(ctx, err); sqlErr != nil {
+			return nil, sqlErr
+		}
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *Repository) GetWebhook(ctx context.Context, id int32) (postgres.Webhook, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Webhook{}, err
+	}
+	webhook, err := r.q.GetWebhook(ctx, postgres.GetWebhookParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Webhook{}, ErrNotFound
+		}
+		if sqlErr := webhookPgError(ctx, err); sqlErr != nil {
+			return postgres.Webhook{}, sqlErr
+		}
+		return postgres.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (r *Repository) CreateWebhook(ctx context.Context, webhook ValidatedWebhook) (postgres.Webhook, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Webhook{}, err
+	}
+	newWebhook, err := r.q.CreateWebhook(ctx, postgres.CreateWebhookParams{
+		HouseholdID: householdID,
+		Url:         webhook.URL,
+		Secret:      webhook.Secret,
+		Events:      webhook.Events,
+	})
+	if err != nil {
+		if sqlErr := webhookPgError(ctx, err); sqlErr != nil {
+			return postgres.Webhook{}, sqlErr
+		}
+		return postgres.Webhook{}, err
+	}
+	return newWebhook, nil
+}
+
+func (r *Repository) UpdateWebhook(ctx context.Context, id int32, webhook ValidatedWebhook) (postgres.Webhook, error) {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return postgres.Webhook{}, err
+	}
+	updatedWebhook, err := r.q.UpdateWebhook(ctx, postgres.UpdateWebhookParams{
+		HouseholdID: householdID,
+		ID:          id,
+		Url:         webhook.URL,
+		Secret:      webhook.Secret,
+		Events:      webhook.Events,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.Webhook{}, ErrNotFound
+		}
+		if sqlErr := webhookPgError(ctx, err); sqlErr != nil {
+			return postgres.Webhook{}, sqlErr
+		}
+		return postgres.Webhook{}, err
+	}
+	return updatedWebhook, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, id int32) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	err = r.q.DeleteWebhook(ctx, postgres.DeleteWebhookParams{HouseholdID: householdID, ID: id})
+	if err != nil {
+		if sqlErr := webhookPgError(ctx, err); sqlErr != nil {
+			return sqlErr
+		}
+		return err
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the latest deliveries of a webhook of the
+// household, most recent first.
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, webhookID int32, limit int32) ([]postgres.WebhookDelivery, error) {
+	if _, err := r.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	deliveries, err := r.q.ListWebhookDeliveries(ctx, postgres.ListWebhookDeliveriesParams{WebhookID: webhookID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery queues a delivery of the household again, whatever its
+// current status.
+func (r *Repository) RetryWebhookDelivery(ctx context.Context, webhookID int32, id uuid.UUID) error {
+	householdID, err := HouseholdFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	retried, err := r.q.RetryWebhookDelivery(ctx, postgres.RetryWebhookDeliveryParams{HouseholdID: householdID, WebhookID: webhookID, ID: id})
+	if err != nil {
+		return err
+	}
+	if retried == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimWebhookDeliveries returns up to max pending deliveries that are due,
+// across every household. The claimed deliveries won't be returned again
+// before lease has passed, leaving time to attempt them.
+func (r *Repository) ClaimWebhookDeliveries(ctx context.Context, max int32, lease time.Duration) ([]postgres.ClaimWebhookDeliveriesRow, error) {
+	now := time.Now()
+	return r.q.ClaimWebhookDeliveries(ctx, postgres.ClaimWebhookDeliveriesParams{
+		LeaseUntil:    now.Add(lease),
+		Now:           now,
+		MaxDeliveries: max,
+	})
+}
+
+// DeliveryAttempt is the outcome of an attempt to deliver a webhook.
+type DeliveryAttempt struct {
+	Status        string
+	StatusCode    int
+	Error         string
+	NextAttemptAt time.Time
+}
+
+func (r *Repository) RecordWebhookDelivery(ctx context.Context, id uuid.UUID, attempt DeliveryAttempt) error {
+	deliveredAt := pgtype.Timestamptz{}
+	if attempt.Status == DeliverySucceeded {
+		deliveredAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+	return r.q.UpdateWebhookDelivery(ctx, postgres.UpdateWebhookDeliveryParams{
+		ID:             id,
+		Status:         attempt.Status,
+		NextAttemptAt:  attempt.NextAttemptAt,
+		LastStatusCode: int32(attempt.StatusCode),
+		LastError:      attempt.Error,
+		DeliveredAt:    deliveredAt,
+	})
+}
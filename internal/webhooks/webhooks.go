@@ -0,0 +1,196 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/config"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+)
+
+const (
+	SignatureHeader = "X-WDTC-Signature-256"
+	EventHeader     = "X-WDTC-Event"
+	DeliveryHeader  = "X-WDTC-Delivery"
+
+	batchSize  = 20
+	maxBackoff = 6 * time.Hour
+)
+
+// ErrPrivateAddress is returned when a webhook would connect to an address
+// that isn't public.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// Queue stores the deliveries waiting to be sent.
+type Queue interface {
+	ClaimWebhookDeliveries(ctx context.Context, max int32, lease time.Duration) ([]postgres.ClaimWebhookDeliveriesRow, error)
+	RecordWebhookDelivery(ctx context.Context, id uuid.UUID, attempt repository.DeliveryAttempt) error
+}
+
+// Dispatcher sends the queued deliveries to their webhook, retrying failed
+// ones with an exponential backoff.
+type Dispatcher struct {
+	queue        Queue
+	client       *http.Client
+	pollInterval time.Duration
+	maxAttempts  int
+	backoff      time.Duration
+}
+
+func New(queue Queue, conf config.WebhooksConfig) *Dispatcher {
+	return &Dispatcher{
+		queue:        queue,
+		client:       newClient(conf),
+		pollInterval: conf.PollInterval,
+		maxAttempts:  conf.MaxAttempts,
+		backoff:      conf.Backoff,
+	}
+}
+
+// newClient returns the client sending the deliveries. Unless private
+// addresses are allowed, its connections are checked by publicOnly. It doesn't
+// go through the proxy of the environment, whose address would be checked
+// instead of the one of the webhook.
+func newClient(conf config.WebhooksConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: conf.Timeout}
+	if !conf.AllowPrivate {
+		dialer.Control = publicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: conf.Timeout, Transport: transport}
+}
+
+// publicOnly refuses to connect to loopback, private, link-local, multicast
+// and unspecified addresses, so that webhooks can't reach the services next to
+// the server. It runs once the host name is resolved, which also covers names
+// resolving to such addresses and redirects.
+func publicOnly(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, ip)
+	}
+	return nil
+}
+
+// Sign returns the signature of a payload sent in the SignatureHeader: the
+// hex encoded HMAC-SHA256 of the body keyed with the webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before retrying a delivery that failed attempts
+// times, doubling at each attempt.
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// Run dispatches deliveries until the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			count, err := d.Dispatch(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, fmt.Sprintf("unable to dispatch webhook deliveries: %v", err))
+			}
+			if err != nil || count < batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch attempts a batch of due deliveries and returns how many were
+// attempted.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	lease := d.client.Timeout + time.Minute
+	deliveries, err := d.queue.ClaimWebhookDeliveries(ctx, batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, delivery := range deliveries {
+		attempt := d.deliver(ctx, delivery)
+		if err = d.queue.RecordWebhookDelivery(ctx, delivery.ID, attempt); err != nil {
+			return 0, fmt.Errorf("unable to record webhook delivery %v: %w", delivery.ID, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery postgres.ClaimWebhookDeliveriesRow) repository.DeliveryAttempt {
+	attempts := int(delivery.Attempts) + 1
+	statusCode, err := d.post(ctx, delivery)
+	if err == nil {
+		return repository.DeliveryAttempt{Status: repository.DeliverySucceeded, StatusCode: statusCode, NextAttemptAt: time.Now()}
+	}
+	slog.WarnContext(ctx, fmt.Sprintf("webhook delivery %v to %s failed (attempt %d): %v", delivery.ID, delivery.Url, attempts, err))
+	attempt := repository.DeliveryAttempt{
+		Status:        repository.DeliveryPending,
+		StatusCode:    statusCode,
+		Error:         err.Error(),
+		NextAttemptAt: time.Now().Add(d.Backoff(attempts)),
+	}
+	if attempts >= d.maxAttempts {
+		attempt.Status = repository.DeliveryFailed
+	}
+	return attempt
+}
+
+func (d *Dispatcher) post(ctx context.Context, delivery postgres.ClaimWebhookDeliveriesRow) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "whodidthechores-webhooks")
+	request.Header.Set(EventHeader, delivery.Event)
+	request.Header.Set(DeliveryHeader, delivery.ID.String())
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
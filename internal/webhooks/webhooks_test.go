@@ -0,0 +1,131 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mqufflc/whodidthechores/internal/config"
+	"github.com/mqufflc/whodidthechores/internal/repository"
+	"github.com/mqufflc/whodidthechores/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQueue hands out its deliveries once and keeps the recorded attempts.
+type memoryQueue struct {
+	mu         sync.Mutex
+	deliveries []postgres.ClaimWebhookDeliveriesRow
+	attempts   map[uuid.UUID]repository.DeliveryAttempt
+}
+
+func (q *memoryQueue) ClaimWebhookDeliveries(ctx context.Context, max int32, lease time.Duration) ([]postgres.ClaimWebhookDeliveriesRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	deliveries := q.deliveries
+	q.deliveries = nil
+	return deliveries, nil
+}
+
+func (q *memoryQueue) RecordWebhookDelivery(ctx context.Context, id uuid.UUID, attempt repository.DeliveryAttempt) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.attempts[id] = attempt
+	return nil
+}
+
+var testConfig = config.WebhooksConfig{PollInterval: time.Second, Timeout: time.Second, Backoff: 30 * time.Second, MaxAttempts: 3, AllowPrivate: true}
+
+func TestDispatch(t *testing.T) {
+	secret := "s3cr3t"
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ok := postgres.ClaimWebhookDeliveriesRow{ID: uuid.New(), Event: repository.EventTaskCreated, Payload: []byte(`{"event":"task.created"}`), Url: server.URL + "/hook", Secret: secret}
+	retried := postgres.ClaimWebhookDeliveriesRow{ID: uuid.New(), Event: repository.EventChoreDeleted, Payload: []byte(`{}`), Attempts: 0, Url: server.URL + "/broken", Secret: secret}
+	exhausted := postgres.ClaimWebhookDeliveriesRow{ID: uuid.New(), Event: repository.EventUserUpdated, Payload: []byte(`{}`), Attempts: 2, Url: server.URL + "/broken", Secret: secret}
+	queue := &memoryQueue{
+		deliveries: []postgres.ClaimWebhookDeliveriesRow{ok, retried, exhausted},
+		attempts:   map[uuid.UUID]repository.DeliveryAttempt{},
+	}
+
+	count, err := New(queue, testConfig).Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	assert.Len(t, received, 3)
+	assert.Equal(t, repository.EventTaskCreated, received[0].Header.Get(EventHeader))
+	assert.Equal(t, ok.ID.String(), received[0].Header.Get(DeliveryHeader))
+	assert.Equal(t, Sign(secret, bodies[0]), received[0].Header.Get(SignatureHeader))
+	assert.Equal(t, string(ok.Payload), string(bodies[0]))
+
+	assert.Equal(t, repository.DeliverySucceeded, queue.attempts[ok.ID].Status)
+	assert.Equal(t, http.StatusNoContent, queue.attempts[ok.ID].StatusCode)
+
+	assert.Equal(t, repository.DeliveryPending, queue.attempts[retried.ID].Status)
+	assert.Equal(t, http.StatusBadGateway, queue.attempts[retried.ID].StatusCode)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), queue.attempts[retried.ID].NextAttemptAt, 5*time.Second)
+
+	assert.Equal(t, repository.DeliveryFailed, queue.attempts[exhausted.ID].Status)
+	assert.NotEmpty(t, queue.attempts[exhausted.ID].Error)
+}
+
+func TestDispatchPrivateAddress(t *testing.T) {
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer server.Close()
+
+	delivery := postgres.ClaimWebhookDeliveriesRow{ID: uuid.New(), Event: repository.EventTaskCreated, Payload: []byte(`{}`), Url: server.URL, Secret: "s3cr3t"}
+	queue := &memoryQueue{
+		deliveries: []postgres.ClaimWebhookDeliveriesRow{delivery},
+		attempts:   map[uuid.UUID]repository.DeliveryAttempt{},
+	}
+	conf := testConfig
+	conf.AllowPrivate = false
+	_, err := New(queue, conf).Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, received)
+	assert.Equal(t, repository.DeliveryPending, queue.attempts[delivery.ID].Status)
+	assert.Contains(t, queue.attempts[delivery.ID].Error, ErrPrivateAddress.Error())
+}
+
+func TestPublicOnly(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:80", "[::1]:443", "10.1.2.3:80", "172.16.0.1:80", "192.168.1.10:8123",
+		"169.254.169.254:80", "[fe80::1]:80", "[fd00::1]:80", "0.0.0.0:80", "[::ffff:127.0.0.1]:80"} {
+		assert.True(t, errors.Is(publicOnly("tcp", address, nil), ErrPrivateAddress), address)
+	}
+	for _, address := range []string{"93.184.215.14:443", "[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443"} {
+		assert.NoError(t, publicOnly("tcp", address, nil), address)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"event":"task.created"}' | openssl dgst -sha256 -hmac s3cr3t
+	assert.Equal(t, "sha256=26e41d8dcef7a07df3161c26de5307f8cc4aa79df6cb8e7f8d46a3f329404f44", Sign("s3cr3t", []byte(`{"event":"task.created"}`)))
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher := New(&memoryQueue{}, testConfig)
+	assert.Equal(t, 30*time.Second, dispatcher.Backoff(1))
+	assert.Equal(t, time.Minute, dispatcher.Backoff(2))
+	assert.Equal(t, 4*time.Minute, dispatcher.Backoff(4))
+	assert.Equal(t, maxBackoff, dispatcher.Backoff(50))
+}